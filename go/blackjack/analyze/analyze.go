@@ -0,0 +1,379 @@
+package analyze
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+	"blackjack/sidebets"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Line is how often one paytable line wins and what it returns, per unit
+// wagered, over every deal.
+type Line struct {
+	Hand      string
+	Frequency float64
+	Return    float64
+}
+
+// Analysis is the exact odds of a sidebet for one shoe.
+type Analysis struct {
+	Sidebet      string
+	Lines        []Line
+	HitFrequency float64 // how often the sidebet wins anything
+	HouseEdge    float64 // the player's expected loss per unit wagered
+}
+
+// shoe is how many of each card a fresh shoe holds.
+type shoe struct {
+	cards  []cards.Card
+	counts []int
+	total  int
+}
+
+// newShoe counts the cards in decks decks of mode's deck, so a Spanish 21
+// shoe has no tens and a Super Fun 21 shoe is one deck.
+func newShoe(mode game.Game, decks int) *shoe {
+	decks = game.DecksFor(mode, decks)
+
+	s := &shoe{}
+	for _, card := range game.CreateDeck(game.NewTable(mode, 1)).Cards {
+		s.cards = append(s.cards, card)
+		s.counts = append(s.counts, decks)
+		s.total += decks
+	}
+	return s
+}
+
+// byValue folds s into one card of each value, for deals where suits do not
+// matter.
+func (s *shoe) byValue() *shoe {
+	values := &shoe{total: s.total}
+	seen := make(map[cards.CardValue]int)
+	for i, card := range s.cards {
+		if j, ok := seen[card.Value]; ok {
+			values.counts[j] += s.counts[i]
+			continue
+		}
+		seen[card.Value] = len(values.cards)
+		values.cards = append(values.cards, card)
+		values.counts = append(values.counts, s.counts[i])
+	}
+	return values
+}
+
+// without is a copy of s with one card of s.cards[i] dealt.
+func (s *shoe) without(i int) *shoe {
+	left := &shoe{cards: s.cards, counts: append([]int(nil), s.counts...), total: s.total - 1}
+	left.counts[i]--
+	return left
+}
+
+// newAnalysisTable is a table with an empty dealer hand to deal into.
+func newAnalysisTable(mode game.Game) *game.Table {
+	t := game.NewTable(mode, 1)
+	t.State.Dealer = player.Player{Dealer: true}
+	t.State.Dealer.Hands = []player.Hand{{Active: true, Player: &t.State.Dealer}}
+	return t
+}
+
+// tally adds up what one sidebet wins over the deals it is shown.
+type tally struct {
+	bet      sidebets.Sidebet
+	values   []float64
+	wins     []float64
+	hits     float64
+	returned float64
+}
+
+func newTally(bet sidebets.Sidebet, wager int) *tally {
+	paytable := bet.Paytable()
+	tl := &tally{bet: bet, values: make([]float64, len(paytable)), wins: make([]float64, len(paytable))}
+
+	resets := make(map[string]int)
+	if progressiveBet, ok := bet.(sidebets.Progressive); ok {
+		for _, meter := range progressiveBet.Meters() {
+			resets[meter.Name] = meter.Reset
+		}
+	}
+	for i, payout := range paytable {
+		tl.values[i] = lineValue(payout, resets, wager)
+	}
+	return tl
+}
+
+// lineValue is what payout wins per unit of wager, counting a progressive at
+// the amount in resets its meter starts again from.
+func lineValue(payout sidebets.Payout, resets map[string]int, wager int) float64 {
+	if payout.Progressive {
+		return float64(resets[payout.Meter]) / 100 / float64(wager)
+	}
+	return float64(payout.Odds*wager+payout.Amount) / float64(wager)
+}
+
+// empty is a tally of the same bet and wager with nothing added yet.
+func (tl *tally) empty() *tally {
+	return &tally{bet: tl.bet, values: tl.values, wins: make([]float64, len(tl.wins))}
+}
+
+func (tl *tally) merge(other *tally) {
+	tl.hits += other.hits
+	tl.returned += other.returned
+	for i := range tl.wins {
+		tl.wins[i] += other.wins[i]
+	}
+}
+
+func (tl *tally) add(t *game.Table, hand player.Hand, probability float64) {
+	lines := tl.bet.Evaluate(t, hand)
+	if len(lines) == 0 {
+		return
+	}
+
+	tl.hits += probability
+	for _, line := range lines {
+		tl.wins[line] += probability
+		tl.returned += probability * tl.values[line]
+	}
+}
+
+func (tl *tally) analysis() Analysis {
+	analysis := Analysis{
+		Sidebet:      tl.bet.Name(),
+		HitFrequency: tl.hits,
+		HouseEdge:    1 - tl.hits - tl.returned,
+	}
+	for i, payout := range tl.bet.Paytable() {
+		analysis.Lines = append(analysis.Lines, Line{Hand: payout.Hand, Frequency: tl.wins[i], Return: tl.wins[i] * tl.values[i]})
+	}
+	return analysis
+}
+
+// Sidebets works out every bet's exact house edge on a wager of wager, from
+// a fresh shoe of decks of mode's deck, in the order given.
+//
+// Bets that settle after the deal are evaluated on every deal of the player's
+// first two cards and the dealer's up and hole cards, or the up card alone
+// when tableRules deal no hole card, each weighted by its chance. Bets that
+// settle once the dealer has drawn are evaluated on every way the dealer can
+// draw to a standing hand under tableRules, by card value alone, so their
+// paytables should not depend on suits. Bets on both of a
+// Blackjack Switch player's hands are evaluated on every deal of their four
+// cards. None count the cards other players draw.
+func Sidebets(bets []sidebets.Sidebet, mode game.Game, decks int, tableRules rules.TableRules, wager int) []Analysis {
+	if wager <= 0 {
+		wager = 1
+	}
+
+	s := newShoe(mode, decks)
+	dealt := make([]*tally, 0)
+	drawn := make([]*tally, 0)
+	switched := make([]*tally, 0)
+	tallies := make([]*tally, 0, len(bets))
+	for _, bet := range bets {
+		tl := newTally(bet, wager)
+		tallies = append(tallies, tl)
+		if _, ok := bet.(sidebets.SwitchHands); ok {
+			switched = append(switched, tl)
+		} else if bet.Resolves() == sidebets.AfterDealer {
+			drawn = append(drawn, tl)
+		} else {
+			dealt = append(dealt, tl)
+		}
+	}
+
+	if len(dealt) > 0 {
+		dealHands(mode, s, tableRules.NoHoleCard, dealt)
+	}
+	if len(drawn) > 0 {
+		drawHands(mode, s.byValue(), tableRules, drawn)
+	}
+	if len(switched) > 0 {
+		dealSwitchHands(mode, s, switched)
+	}
+
+	analyses := make([]Analysis, 0, len(tallies))
+	for _, tl := range tallies {
+		analyses = append(analyses, tl.analysis())
+	}
+	return analyses
+}
+
+// dealHands shows tallies every deal of two cards to the player and an up
+// and a hole card to the dealer, or only the up card when there is no hole
+// card.
+func dealHands(mode game.Game, s *shoe, noHoleCard bool, tallies []*tally) {
+	byFirstCard(mode, s, tallies, func(t *game.Table, s *shoe, first cards.Card, probability float64, tallies []*tally) {
+		p := player.Player{}
+		hand := player.Hand{Active: true, Player: &p, Cards: make([]cards.Card, 2)}
+		dealerHand := &t.State.Dealer.Hands[0]
+		dealerHand.Cards = make([]cards.Card, 2)
+		if noHoleCard {
+			dealerHand.Cards = dealerHand.Cards[:1]
+		}
+
+		deal(s, 1+len(dealerHand.Cards), probability, func(dealt []int, probability float64) {
+			hand.Cards[0], hand.Cards[1] = first, s.cards[dealt[0]]
+			for i := range dealerHand.Cards {
+				dealerHand.Cards[i] = s.cards[dealt[1+i]]
+			}
+			// the hole card is still face down when these bets settle
+			if len(dealerHand.Cards) > 1 {
+				dealerHand.Cards[1].Masked = true
+			}
+
+			for _, tl := range tallies {
+				tl.add(t, hand, probability)
+			}
+		})
+	})
+}
+
+// dealSwitchHands shows tallies every deal of two cards to each of a
+// Blackjack Switch player's two hands.
+func dealSwitchHands(mode game.Game, s *shoe, tallies []*tally) {
+	byFirstCard(mode, s, tallies, func(t *game.Table, s *shoe, first cards.Card, probability float64, tallies []*tally) {
+		p := player.Player{}
+		p.Hands = []player.Hand{{Active: true, Player: &p, Cards: make([]cards.Card, 2)}, {Active: true, Player: &p, Cards: make([]cards.Card, 2)}}
+
+		deal(s, 3, probability, func(dealt []int, probability float64) {
+			p.Hands[0].Cards[0], p.Hands[1].Cards[0] = first, s.cards[dealt[0]]
+			p.Hands[0].Cards[1], p.Hands[1].Cards[1] = s.cards[dealt[1]], s.cards[dealt[2]]
+
+			for _, tl := range tallies {
+				tl.add(t, p.Hands[0], probability)
+			}
+		})
+	})
+}
+
+// drawHands shows tallies every standing hand the dealer can draw to, after
+// two cards to the player.
+func drawHands(mode game.Game, s *shoe, tableRules rules.TableRules, tallies []*tally) {
+	byFirstCard(mode, s, tallies, func(t *game.Table, s *shoe, first cards.Card, probability float64, tallies []*tally) {
+		p := player.Player{}
+		hand := player.Hand{Active: true, Player: &p, Cards: make([]cards.Card, 2)}
+
+		deal(s, 1, probability, func(dealt []int, probability float64) {
+			hand.Cards[0], hand.Cards[1] = first, s.cards[dealt[0]]
+			drawDealer(t, s, tableRules, make([]cards.Card, 0), probability, func(probability float64) {
+				for _, tl := range tallies {
+					tl.add(t, hand, probability)
+				}
+			})
+		})
+	})
+}
+
+// byFirstCard splits the deals from s by the player's first card and plays
+// them in parallel, each with its own table, copy of the shoe and tallies,
+// then adds the tallies up in the order of the shoe so every run comes out
+// the same.
+func byFirstCard(mode game.Game, s *shoe, tallies []*tally, deals func(t *game.Table, s *shoe, first cards.Card, probability float64, tallies []*tally)) {
+	results := make([][]*tally, len(s.cards))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < runtime.NumCPU(); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := newAnalysisTable(mode)
+			for i := range jobs {
+				left := s.without(i)
+				results[i] = make([]*tally, 0, len(tallies))
+				for _, tl := range tallies {
+					results[i] = append(results[i], tl.empty())
+				}
+				deals(t, left, s.cards[i], float64(s.counts[i])/float64(s.total), results[i])
+			}
+		}()
+	}
+
+	for i := range s.cards {
+		if s.counts[i] > 0 {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range results {
+		for j, tl := range result {
+			tallies[j].merge(tl)
+		}
+	}
+}
+
+// drawDealer deals the dealer cards from what is left of s, with the chance
+// of having drawn dealerCards so far, until the dealer stands and then calls
+// stands with the chance of that hand.
+func drawDealer(t *game.Table, s *shoe, tableRules rules.TableRules, dealerCards []cards.Card, probability float64, stands func(probability float64)) {
+	dealerHand := &t.State.Dealer.Hands[0]
+	// fresh copies, as working out the hand's value demotes its aces
+	dealerHand.Cards = make([]cards.Card, 0, len(dealerCards))
+	for _, card := range dealerCards {
+		dealerHand.Cards = append(dealerHand.Cards, cards.CreateCard(card.Suite, card.Value))
+	}
+
+	if len(dealerCards) >= 2 && !rules.CanHit(t, dealerHand, tableRules) {
+		dealerHand.Cards = dealerCards
+		stands(probability)
+		return
+	}
+
+	for i, card := range s.cards {
+		if s.counts[i] == 0 {
+			continue
+		}
+		chance := float64(s.counts[i]) / float64(s.total)
+		s.counts[i]--
+		s.total--
+		drawDealer(t, s, tableRules, append(dealerCards[:len(dealerCards):len(dealerCards)], card), probability*chance, stands)
+		s.counts[i]++
+		s.total++
+	}
+}
+
+// deal calls dealt with every way of dealing n cards from s, in order, and
+// the chance of each.
+func deal(s *shoe, n int, probability float64, dealt func(dealt []int, probability float64)) {
+	indexes := make([]int, 0, n)
+	var next func(probability float64)
+	next = func(probability float64) {
+		if len(indexes) == n {
+			dealt(indexes, probability)
+			return
+		}
+		for i := range s.cards {
+			if s.counts[i] == 0 {
+				continue
+			}
+			chance := float64(s.counts[i]) / float64(s.total)
+			s.counts[i]--
+			s.total--
+			indexes = append(indexes, i)
+			next(probability * chance)
+			indexes = indexes[:len(indexes)-1]
+			s.counts[i]++
+			s.total++
+		}
+	}
+	next(probability)
+}
+
+// PrintAnalyses prints each sidebet's paytable with how often each line hits
+// and what it returns, then its house edge.
+func PrintAnalyses(analyses []Analysis, mode game.Game, decks int) {
+	fmt.Printf("Sidebets on %d decks of %s\n", decks, mode)
+	for _, analysis := range analyses {
+		fmt.Printf("\n%s\n", analysis.Sidebet)
+		for _, line := range analysis.Lines {
+			fmt.Printf("  %-44s %9.5f%%  returns %+8.4f%%\n", line.Hand, 100*line.Frequency, 100*line.Return)
+		}
+		fmt.Printf("  %-44s %9.5f%%\n", "Hit frequency", 100*analysis.HitFrequency)
+		fmt.Printf("  %-44s %+9.4f%%\n", "House edge", 100*analysis.HouseEdge)
+	}
+}
@@ -0,0 +1,92 @@
+package analyze
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/rules"
+	"blackjack/sidebets"
+	"math"
+	"testing"
+)
+
+func TestNewShoe(t *testing.T) {
+	if s := newShoe(game.Blackjack, 6); s.total != 6*52 || len(s.cards) != 52 {
+		t.Fatalf("6 decks: total=%d cards=%d want 312 and 52", s.total, len(s.cards))
+	}
+
+	s := newShoe(game.Spanish21, 8)
+	if s.total != 8*48 {
+		t.Fatalf("8 Spanish decks: total=%d want 384", s.total)
+	}
+	for _, card := range s.cards {
+		if card.Value == cards.Ten {
+			t.Fatalf("a Spanish 21 shoe should have no tens")
+		}
+	}
+
+	if values := s.byValue(); len(values.cards) != 12 || values.total != s.total || values.counts[0] != 32 {
+		t.Fatalf("by value: cards=%d total=%d count=%d want 12, 384 and 32", len(values.cards), values.total, values.counts[0])
+	}
+}
+
+func TestPerfectPairs(t *testing.T) {
+	analyses := Sidebets([]sidebets.Sidebet{sidebets.PerfectPairsStandard}, game.Blackjack, 6, rules.DefaultTableRules(), 5)
+	if len(analyses) != 1 {
+		t.Fatalf("got %d analyses want 1", len(analyses))
+	}
+
+	// the second card has 311 left to come from
+	want := []float64{5.0 / 311, 6.0 / 311, 12.0 / 311}
+	for i, line := range analyses[0].Lines {
+		if math.Abs(line.Frequency-want[i]) > 1e-9 {
+			t.Errorf("%s: frequency %f want %f", line.Hand, line.Frequency, want[i])
+		}
+	}
+
+	edge := 1 - (5.0*26+6.0*13+12.0*7)/311
+	if math.Abs(analyses[0].HouseEdge-edge) > 1e-9 {
+		t.Errorf("house edge %f want %f", analyses[0].HouseEdge, edge)
+	}
+}
+
+func TestSuperMatch(t *testing.T) {
+	analyses := Sidebets([]sidebets.Sidebet{sidebets.SuperMatch{}}, game.Switch, 1, rules.DefaultTableRules(), 5)
+
+	// four cards from one deck: 13 values of 4, C(52,4) = 270725 hands
+	hands := 270725.0
+	want := []float64{
+		13 / hands,               // four of a kind
+		78 * 6 * 6 / hands,       // two pair
+		13 * 4 * 48 / hands,      // three of a kind
+		13 * 6 * 66 * 16 / hands, // one pair
+	}
+	for i, line := range analyses[0].Lines {
+		if math.Abs(line.Frequency-want[i]) > 1e-9 {
+			t.Errorf("%s: frequency %f want %f", line.Hand, line.Frequency, want[i])
+		}
+	}
+	edge := 1 - (41*want[0] + 9*want[1] + 6*want[2] + 2*want[3])
+	if math.Abs(analyses[0].HouseEdge-edge) > 1e-9 {
+		t.Errorf("house edge %f want %f", analyses[0].HouseEdge, edge)
+	}
+}
+
+func TestLuckyLadiesWithoutHoleCard(t *testing.T) {
+	tableRules := rules.DefaultTableRules()
+	withHole := Sidebets([]sidebets.Sidebet{sidebets.LuckyLadies{}}, game.Blackjack, 6, tableRules, 5)[0]
+	tableRules.NoHoleCard = true
+	withoutHole := Sidebets([]sidebets.Sidebet{sidebets.LuckyLadies{}}, game.Blackjack, 6, tableRules, 5)[0]
+
+	if withHole.Lines[0].Frequency == 0 {
+		t.Fatalf("a queen of hearts pair against a dealer blackjack should be dealt with a hole card")
+	}
+	if withoutHole.Lines[0].Frequency != 0 {
+		t.Fatalf("the top line paid %f of the time without a hole card", withoutHole.Lines[0].Frequency)
+	}
+
+	// those hands move down to the queen of hearts pair line
+	pairs := withHole.Lines[0].Frequency + withHole.Lines[1].Frequency
+	if math.Abs(withoutHole.Lines[1].Frequency-pairs) > 1e-12 {
+		t.Errorf("queen of hearts pairs %f want %f", withoutHole.Lines[1].Frequency, pairs)
+	}
+}
@@ -0,0 +1,328 @@
+package betting
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/utils"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Strategy picks a player's next main wager before the cards are dealt, in
+// any amount. Bet keeps it to the table's limits and the player's stack.
+type Strategy interface {
+	Name() string
+	Wager(t *game.Table, p *player.Player, limits Limits) int
+}
+
+// Limits are the table's smallest and largest bets, no largest when Maximum
+// is 0. Every strategy counts its units in table minimums.
+type Limits struct {
+	Minimum int
+	Maximum int
+}
+
+// Options tune the strategies that take them.
+type Options struct {
+	Spread        int     // the ramp's top bet, in table minimums
+	KellyFraction float64 // the share of a full Kelly bet kelly makes
+	Bankroll      int     // what kelly sizes bets from, the player's stack when 0
+}
+
+// Strategies make a new strategy, with its own progression, by the name the
+// -betting flag takes.
+var Strategies = map[string]func(options Options) Strategy{
+	"flat": func(options Options) Strategy { return Flat{} },
+	"ramp": func(options Options) Strategy { return Ramp{Spread: options.Spread} },
+	"kelly": func(options Options) Strategy {
+		return Kelly{Fraction: options.KellyFraction, Bankroll: options.Bankroll}
+	},
+	"martingale": func(options Options) Strategy { return Martingale{} },
+	"paroli":     func(options Options) Strategy { return Paroli{} },
+	"1326":       func(options Options) Strategy { return OneThreeTwoSix{} },
+	"dalembert":  func(options Options) Strategy { return DAlembert{} },
+	"fibonacci":  func(options Options) Strategy { return &Fibonacci{} },
+	"oscar":      func(options Options) Strategy { return &OscarsGrind{} },
+	"streak":     func(options Options) Strategy { return Streak{} },
+}
+
+const DefaultStrategy = "flat"
+
+// StrategyNames lists the names of Strategies in order.
+func StrategyNames() []string {
+	names := make([]string, 0, len(Strategies))
+	for name := range Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func New(name string, options Options) (Strategy, error) {
+	if newStrategy, ok := Strategies[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return newStrategy(options), nil
+	}
+	return nil, fmt.Errorf("betting %q should be one of %s", name, strings.Join(StrategyNames(), ", "))
+}
+
+// ForPlayer makes the strategy for seat i from spec, a comma separated list
+// with one strategy per seat. Seats past the end of the list start it over.
+func ForPlayer(spec string, i int, options Options) (Strategy, error) {
+	names := strings.Split(spec, ",")
+	if strings.TrimSpace(spec) == "" {
+		names = []string{DefaultStrategy}
+	}
+	return New(names[i%len(names)], options)
+}
+
+// Parse checks every strategy in spec.
+func Parse(spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+	for _, name := range strings.Split(spec, ",") {
+		if _, err := New(name, Options{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bet is strategy's wager for p kept between the table's limits and to what
+// p has in front of them.
+func Bet(strategy Strategy, t *game.Table, p *player.Player, limits Limits) int {
+	wager := strategy.Wager(t, p, limits)
+
+	if limits.Maximum > 0 && wager > limits.Maximum {
+		wager = limits.Maximum
+	}
+	if wager < limits.Minimum {
+		wager = limits.Minimum
+	}
+	if wager > p.Stack {
+		wager = p.Stack
+	}
+
+	return wager
+}
+
+// lastBet is p's last wager on each hand, Blackjack Switch staking it on two.
+func lastBet(t *game.Table, p *player.Player) int {
+	if t.Mode == game.Switch {
+		return p.LastWager / 2
+	}
+	return p.LastWager
+}
+
+// lastRound is how p's last wager went, false for all three before their
+// first bet.
+func lastRound(p *player.Player) (won bool, pushed bool, lost bool) {
+	if p.LastWager == 0 {
+		return false, false, false
+	}
+	return p.LastHandWon, p.LastHandPushed, !p.LastHandWon && !p.LastHandPushed
+}
+
+// Flat bets the table minimum every round.
+type Flat struct{}
+
+func (Flat) Name() string { return "Flat" }
+
+func (Flat) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	return limits.Minimum
+}
+
+// Ramp bets a table minimum for every point of true count, from one minimum
+// at +1 or less up to Spread minimums.
+type Ramp struct {
+	Spread int
+}
+
+func (r Ramp) Name() string { return fmt.Sprintf("Ramp 1-%d", r.Spread) }
+
+func (r Ramp) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	units := int(math.Floor(game.TrueCount(t)))
+	if units > r.Spread {
+		units = r.Spread
+	}
+	if units < 1 {
+		units = 1
+	}
+	return units * limits.Minimum
+}
+
+// kelly's edge is the usual Hi-Lo rule of thumb, half a percent a true count
+// up from half a percent down, with blackjack's variance of about 1.3.
+const kellyBaseEdge = -.005
+const kellyEdgePerCount = .005
+const kellyVariance = 1.3
+
+// Kelly bets Fraction of the Kelly criterion bet for the edge the true count
+// gives, the minimum whenever the player has no edge.
+type Kelly struct {
+	Fraction float64
+	Bankroll int
+}
+
+func (k Kelly) Name() string { return fmt.Sprintf("Kelly %g", k.Fraction) }
+
+func (k Kelly) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	bankroll := k.Bankroll
+	if bankroll <= 0 {
+		bankroll = p.Stack
+	}
+
+	edge := kellyBaseEdge + kellyEdgePerCount*game.TrueCount(t)
+	if edge <= 0 {
+		return limits.Minimum
+	}
+	return int(k.Fraction * edge / kellyVariance * float64(bankroll))
+}
+
+// Martingale doubles the last wager after a loss and goes back to one
+// minimum after anything else.
+type Martingale struct{}
+
+func (Martingale) Name() string { return "Martingale" }
+
+func (Martingale) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	if _, _, lost := lastRound(p); lost {
+		return 2 * lastBet(t, p)
+	}
+	return limits.Minimum
+}
+
+// Paroli doubles the last wager after a win, for up to three wins in a row.
+type Paroli struct{}
+
+func (Paroli) Name() string { return "Paroli" }
+
+func (Paroli) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	if won, pushed, _ := lastRound(p); won && p.WinStreak%3 != 0 {
+		return 2 * lastBet(t, p)
+	} else if pushed {
+		return lastBet(t, p)
+	}
+	return limits.Minimum
+}
+
+var oneThreeTwoSix = []int{1, 3, 2, 6}
+
+// OneThreeTwoSix bets 1, 3, 2 and then 6 minimums through a run of wins,
+// starting over after a loss or the fourth win.
+type OneThreeTwoSix struct{}
+
+func (OneThreeTwoSix) Name() string { return "1-3-2-6" }
+
+func (OneThreeTwoSix) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	if won, pushed, _ := lastRound(p); won {
+		return oneThreeTwoSix[p.WinStreak%len(oneThreeTwoSix)] * limits.Minimum
+	} else if pushed {
+		return lastBet(t, p)
+	}
+	return limits.Minimum
+}
+
+// DAlembert bets one minimum more after a loss and one less after a win.
+type DAlembert struct{}
+
+func (DAlembert) Name() string { return "D'Alembert" }
+
+func (DAlembert) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	won, pushed, lost := lastRound(p)
+	switch {
+	case lost:
+		return lastBet(t, p) + limits.Minimum
+	case won:
+		return lastBet(t, p) - limits.Minimum
+	case pushed:
+		return lastBet(t, p)
+	}
+	return limits.Minimum
+}
+
+// Fibonacci moves one step up the Fibonacci sequence of minimums after a loss
+// and two steps back after a win.
+type Fibonacci struct {
+	step int
+}
+
+func (f *Fibonacci) Name() string { return "Fibonacci" }
+
+func (f *Fibonacci) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	won, pushed, lost := lastRound(p)
+	switch {
+	case lost:
+		f.step += 1
+	case won:
+		f.step = utils.Max(f.step-2, 0)
+	case !pushed:
+		f.step = 0
+	}
+
+	previous, current := 0, 1
+	for i := 0; i < f.step; i++ {
+		previous, current = current, previous+current
+	}
+
+	return current * limits.Minimum
+}
+
+// OscarsGrind plays for one minimum of profit at a time, counting what each
+// round actually netted with its doubles, splits and blackjacks. It bets a
+// minimum more after each win, but never more than would finish the grind,
+// and the same again after a loss.
+type OscarsGrind struct {
+	profit int
+}
+
+func (o *OscarsGrind) Name() string { return "Oscar's Grind" }
+
+func (o *OscarsGrind) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	if p.LastWager == 0 {
+		return limits.Minimum
+	}
+
+	o.profit += p.LastNet
+	wager := lastBet(t, p)
+	if won, _, _ := lastRound(p); won {
+		wager += limits.Minimum
+	}
+
+	if o.profit >= limits.Minimum {
+		// the grind is over, start the next one
+		o.profit = 0
+		return limits.Minimum
+	}
+
+	if needed := limits.Minimum - o.profit; wager > needed {
+		wager = needed
+	}
+	return wager
+}
+
+// Streak lets a push ride and presses a run of wins, going all in after
+// seven in a row.
+type Streak struct{}
+
+func (Streak) Name() string { return "Streak" }
+
+func (Streak) Wager(t *game.Table, p *player.Player, limits Limits) int {
+	won, pushed, _ := lastRound(p)
+	if pushed {
+		return lastBet(t, p)
+	}
+
+	if won {
+		if p.WinStreak > 6 {
+			// go for the gusto!
+			return p.Stack
+		} else if p.WinStreak > 3 {
+			return (1 << (p.WinStreak % 3)) * limits.Minimum
+		}
+	}
+
+	return limits.Minimum
+}
@@ -0,0 +1,150 @@
+package betting
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+var limits = Limits{Minimum: 10, Maximum: 500}
+
+// playRound records how p's wager went the way the dealer does, winning or
+// losing just the wager.
+func playRound(p *player.Player, wager int, won bool, pushed bool) {
+	p.LastWager = wager
+	p.LastHandWon = won
+	p.LastHandPushed = pushed
+	switch {
+	case won:
+		p.LastNet = wager
+	case pushed:
+		p.LastNet = 0
+	default:
+		p.LastNet = -wager
+	}
+	if won {
+		p.WinStreak += 1
+	} else {
+		p.WinStreak = 0
+	}
+}
+
+func TestProgressions(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+
+	tests := []struct {
+		name     string
+		outcomes []bool // true for a win
+		want     []int
+	}{
+		{"martingale", []bool{false, false, false, true}, []int{10, 20, 40, 80, 10}},
+		{"paroli", []bool{true, true, true, true}, []int{10, 20, 40, 10, 20}},
+		{"1326", []bool{true, true, true, true, false}, []int{10, 30, 20, 60, 10, 10}},
+		{"dalembert", []bool{false, false, true, true}, []int{10, 20, 30, 20, 10}},
+		{"fibonacci", []bool{false, false, false, false, true}, []int{10, 10, 20, 30, 50, 20}},
+		{"oscar", []bool{false, false, true, true}, []int{10, 10, 10, 20, 10}},
+	}
+
+	for _, test := range tests {
+		strategy, err := New(test.name, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := &player.Player{Stack: 10000}
+
+		for round, want := range test.want {
+			wager := Bet(strategy, table, p, limits)
+			if wager != want {
+				t.Fatalf("%s round %d bet %d want %d", test.name, round+1, wager, want)
+			}
+			if round < len(test.outcomes) {
+				playRound(p, wager, test.outcomes[round], false)
+			}
+		}
+	}
+}
+
+func TestOscarsGrindCountsWhatWasWon(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	p := &player.Player{Stack: 10000}
+	oscar := &OscarsGrind{}
+
+	for round, want := range []int{10, 10, 10, 10} {
+		wager := Bet(oscar, table, p, limits)
+		if wager != want {
+			t.Fatalf("round %d bet %d want %d", round+1, wager, want)
+		}
+		switch round {
+		case 0, 1:
+			playRound(p, wager, false, false)
+		case 2:
+			// a doubled win makes up both losses, so the grind is still one minimum short
+			playRound(p, wager, true, false)
+			p.LastNet = 2 * wager
+		}
+	}
+}
+
+func TestSwitchBetsPerHand(t *testing.T) {
+	table := game.NewTable(game.Switch, 1)
+	p := &player.Player{Stack: 10000}
+	// losing 20 on each of the two hands
+	playRound(p, 40, false, false)
+
+	if wager := Bet(Martingale{}, table, p, limits); wager != 40 {
+		t.Fatalf("bet %d should double the 20 each hand lost", wager)
+	}
+}
+
+func TestBetKeepsToLimits(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	p := &player.Player{Stack: 10000}
+	playRound(p, 400, false, false)
+
+	if wager := Bet(Martingale{}, table, p, limits); wager != limits.Maximum {
+		t.Fatalf("bet %d should stop at the table maximum %d", wager, limits.Maximum)
+	}
+
+	p.Stack = 150
+	if wager := Bet(Martingale{}, table, p, limits); wager != 150 {
+		t.Fatalf("bet %d should stop at the player's stack", wager)
+	}
+
+	playRound(p, 10, true, false)
+	if wager := Bet(DAlembert{}, table, p, limits); wager != limits.Minimum {
+		t.Fatalf("bet %d should not go under the table minimum", wager)
+	}
+}
+
+func TestRamp(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	game.CreateShoe(table, 6)
+	p := &player.Player{Stack: 10000}
+	ramp := Ramp{Spread: 8}
+
+	for _, test := range []struct {
+		count float64
+		want  int
+	}{{-12, 10}, {6, 10}, {18, 30}, {120, 80}} {
+		table.State.Count = test.count
+		if wager := Bet(ramp, table, p, limits); wager != test.want {
+			t.Fatalf("running count %g bet %d want %d", test.count, wager, test.want)
+		}
+	}
+}
+
+func TestForPlayer(t *testing.T) {
+	for i, want := range []string{"Flat", "Paroli", "Flat"} {
+		strategy, err := ForPlayer("flat, paroli", i, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strategy.Name() != want {
+			t.Fatalf("seat %d plays %s want %s", i, strategy.Name(), want)
+		}
+	}
+
+	if err := Parse("flat,roulette"); err == nil {
+		t.Fatalf("unknown strategy should not parse")
+	}
+}
@@ -436,7 +436,11 @@ func IsOneEyedJack(card Card) bool {
 	return card.Value == Jack && (card.Suite == Hearts || card.Suite == Spades)
 }
 
-func ShuffleCards(cards []Card) {
+func IsRed(card Card) bool {
+	return card.Suite == Hearts || card.Suite == Diamonds
+}
+
+func ShuffleCards(rng *rand.Rand, cards []Card) {
 	numOfCards := len(cards)
-	rand.Shuffle(numOfCards, func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	rng.Shuffle(numOfCards, func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
 }
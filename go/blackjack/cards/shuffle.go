@@ -0,0 +1,90 @@
+package cards
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// The shuffles below are what a dealer's hands do to a stack of cards, the
+// first card on top, rather than a perfectly random shuffle. Each returns the
+// shuffled stack and leaves cards as it was.
+
+// Riffle is a Gilbert-Shannon-Reeds riffle: the stack is cut about in half,
+// binomially, and the halves are let fall together a card at a time, each
+// from a half in proportion to how many it has left.
+func Riffle(rng *rand.Rand, cards []Card) []Card {
+	cut := 0
+	for range cards {
+		cut += rng.Intn(2)
+	}
+
+	left, right := cards[:cut], cards[cut:]
+	riffled := make([]Card, 0, len(cards))
+	for len(left) > 0 || len(right) > 0 {
+		if rng.Intn(len(left)+len(right)) < len(left) {
+			riffled, left = append(riffled, left[0]), left[1:]
+		} else {
+			riffled, right = append(riffled, right[0]), right[1:]
+		}
+	}
+	return riffled
+}
+
+// Strip pulls about packets packets off the top one after another and drops
+// each on the last, so their order is reversed but not the cards in them.
+func Strip(rng *rand.Rand, cards []Card, packets int) []Card {
+	stripped := make([]Card, 0, len(cards))
+	for top := 0; top < len(cards); {
+		size := aboutSize(rng, len(cards)/packets, len(cards)-top)
+		stripped = append(append([]Card{}, cards[top:top+size]...), stripped...)
+		top += size
+	}
+	return stripped
+}
+
+// Wash spreads the cards face down and pushes them around the table, which
+// moves each a random distance from where it was, mostly within a quarter of
+// the stack.
+func Wash(rng *rand.Rand, cards []Card) []Card {
+	spread := float64(len(cards)) / 4
+	places := make([]float64, len(cards))
+	for i := range places {
+		places[i] = float64(i) + rng.NormFloat64()*spread
+	}
+
+	order := make([]int, len(cards))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return places[order[i]] < places[order[j]]
+	})
+
+	washed := make([]Card, len(cards))
+	for i, from := range order {
+		washed[i] = cards[from]
+	}
+	return washed
+}
+
+// Cut moves the top of the stack, down to about the middle, to the bottom.
+func Cut(rng *rand.Rand, cards []Card) []Card {
+	if len(cards) < 2 {
+		return append([]Card{}, cards...)
+	}
+	cut := aboutSize(rng, len(cards)/2, len(cards)-1)
+	return append(append([]Card{}, cards[cut:]...), cards[:cut]...)
+}
+
+// aboutSize is a packet of around size cards, as a dealer's hands judge it,
+// of at least one and at most most.
+func aboutSize(rng *rand.Rand, size int, most int) int {
+	size += int(rng.NormFloat64() * float64(size) / 5)
+	if size > most {
+		size = most
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
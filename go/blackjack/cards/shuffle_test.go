@@ -0,0 +1,126 @@
+package cards
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// deckInOrder is a deck numbered 0 to 51 by its place, in Value then Suite
+// order, so a shuffle's moves can be read back off the cards.
+func deckInOrder() []Card {
+	deck := make([]Card, 0, 52)
+	for value := Two; value <= Ace; value++ {
+		for _, suite := range Suites {
+			deck = append(deck, CreateCard(suite, value))
+		}
+	}
+	return deck
+}
+
+func place(card Card) int {
+	return int(card.Value-Two)*len(Suites) + int(card.Suite)
+}
+
+func places(cards []Card) []int {
+	placed := make([]int, len(cards))
+	for i, card := range cards {
+		placed[i] = place(card)
+	}
+	return placed
+}
+
+// risingSequences counts the runs of consecutive places a shuffle left, one
+// for a deck in order and two at most after a riffle.
+func risingSequences(cards []Card) int {
+	where := make([]int, len(cards))
+	for i, card := range cards {
+		where[place(card)] = i
+	}
+	sequences := 1
+	for p := 1; p < len(where); p++ {
+		if where[p] < where[p-1] {
+			sequences++
+		}
+	}
+	return sequences
+}
+
+// TestShufflesKeepTheCards checks every shuffle deals the same cards it was
+// given and leaves what it was given alone.
+func TestShufflesKeepTheCards(t *testing.T) {
+	shuffles := map[string]func(rng *rand.Rand, cards []Card) []Card{
+		"riffle": Riffle,
+		"strip":  func(rng *rand.Rand, cards []Card) []Card { return Strip(rng, cards, 6) },
+		"wash":   Wash,
+		"cut":    Cut,
+	}
+
+	for name, shuffle := range shuffles {
+		deck := deckInOrder()
+		shuffled := shuffle(rand.New(rand.NewSource(1)), deck)
+		if !reflect.DeepEqual(deck, deckInOrder()) {
+			t.Fatalf("%s changed the cards it was given", name)
+		}
+
+		placed := places(shuffled)
+		sort.Ints(placed)
+		if !reflect.DeepEqual(placed, places(deck)) {
+			t.Fatalf("%s lost or duplicated cards: %v", name, places(shuffled))
+		}
+	}
+}
+
+// TestRiffleInterleavesTwoPackets checks a riffle only interleaves the two
+// halves, so a deck riffled once has at most two rising sequences and more
+// riffles mix it further.
+func TestRiffleInterleavesTwoPackets(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	deck := Riffle(rng, deckInOrder())
+	if sequences := risingSequences(deck); sequences > 2 {
+		t.Fatalf("expected at most 2 rising sequences got %d", sequences)
+	}
+
+	for i := 0; i < 3; i++ {
+		deck = Riffle(rng, deck)
+	}
+	if sequences := risingSequences(deck); sequences <= 2 || sequences > 16 {
+		t.Fatalf("expected 3 to 16 rising sequences after four riffles got %d", sequences)
+	}
+}
+
+// TestStripReversesPackets checks a strip keeps the cards of each packet
+// together and in order, reversing the packets.
+func TestStripReversesPackets(t *testing.T) {
+	stripped := places(Strip(rand.New(rand.NewSource(3)), deckInOrder(), 6))
+	if bottom := stripped[len(stripped)-1]; stripped[len(stripped)-1-bottom] != 0 {
+		t.Fatalf("expected the top packet at the bottom got %v", stripped)
+	}
+
+	breaks := 0
+	for i := 1; i < len(stripped); i++ {
+		if stripped[i] != stripped[i-1]+1 {
+			breaks++
+			if stripped[i] > stripped[i-1] {
+				t.Fatalf("expected packets in reverse order got %v", stripped)
+			}
+		}
+	}
+	if breaks < 3 || breaks > 9 {
+		t.Fatalf("expected about 6 packets got %d", breaks+1)
+	}
+}
+
+// TestCutKeepsTheOrderAround checks a cut only moves the top to the bottom.
+func TestCutKeepsTheOrderAround(t *testing.T) {
+	cut := places(Cut(rand.New(rand.NewSource(4)), deckInOrder()))
+	if cut[0] < 10 || cut[0] > 42 {
+		t.Fatalf("expected the cut near the middle got %d", cut[0])
+	}
+	for i := 1; i < len(cut); i++ {
+		if cut[i] != (cut[i-1]+1)%52 {
+			t.Fatalf("expected the order kept around the cut got %v", cut)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package counting
+
+import (
+	"blackjack/cards"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Counter is a card counting system, the tag it gives each card seen and
+// where its running count starts for a fresh shoe.
+type Counter interface {
+	Name() string
+	Tag(card cards.Card) float64
+	InitialCount(decks int) float64
+}
+
+// TagCounter is a counting system with a fixed tag per card value. Tags are
+// indexed by card value, 1 for an ace and 10 for any ten-valued card.
+type TagCounter struct {
+	Label string
+	Tags  [11]float64
+	// an unbalanced count starts at InitialPerDeck per deck plus InitialOffset
+	InitialPerDeck float64
+	InitialOffset  float64
+}
+
+func (c TagCounter) Name() string {
+	return c.Label
+}
+
+func (c TagCounter) Tag(card cards.Card) float64 {
+	if card.Masked {
+		return 0
+	}
+	return c.Tags[cards.CardToValue(card, true)]
+}
+
+func (c TagCounter) InitialCount(decks int) float64 {
+	return c.InitialOffset + c.InitialPerDeck*float64(decks)
+}
+
+var HiLo = TagCounter{Label: "Hi-Lo", Tags: [11]float64{0, -1, 1, 1, 1, 1, 1, 0, 0, 0, -1}}
+var KO = TagCounter{Label: "KO", Tags: [11]float64{0, -1, 1, 1, 1, 1, 1, 1, 0, 0, -1}, InitialPerDeck: -4, InitialOffset: 4}
+var HiOptI = TagCounter{Label: "Hi-Opt I", Tags: [11]float64{0, 0, 0, 1, 1, 1, 1, 0, 0, 0, -1}}
+var HiOptII = TagCounter{Label: "Hi-Opt II", Tags: [11]float64{0, 0, 1, 1, 2, 2, 1, 1, 0, 0, -2}}
+var OmegaII = TagCounter{Label: "Omega II", Tags: [11]float64{0, 0, 1, 1, 2, 2, 2, 1, 0, -1, -2}}
+var Zen = TagCounter{Label: "Zen", Tags: [11]float64{0, -1, 1, 1, 2, 2, 2, 1, 0, 0, -2}}
+var WongHalves = TagCounter{Label: "Wong Halves", Tags: [11]float64{0, -1, .5, 1, 1, 1.5, 1, .5, 0, -.5, -1}}
+
+// Systems are the built in counters by the name the -count flag takes.
+var Systems = map[string]Counter{
+	"hilo":   HiLo,
+	"ko":     KO,
+	"hiopt1": HiOptI,
+	"hiopt2": HiOptII,
+	"omega2": OmegaII,
+	"zen":    Zen,
+	"halves": WongHalves,
+}
+
+const DefaultSystem = "hilo"
+
+// SystemNames lists the names of Systems in order.
+func SystemNames() []string {
+	names := make([]string, 0, len(Systems))
+	for name := range Systems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ParseSystem(systemString string) (Counter, error) {
+	if counter, ok := Systems[strings.ToLower(strings.TrimSpace(systemString))]; ok {
+		return counter, nil
+	}
+	return nil, fmt.Errorf("count %q should be one of %s", systemString, strings.Join(SystemNames(), ", "))
+}
@@ -0,0 +1,58 @@
+package counting
+
+import (
+	"blackjack/cards"
+	"testing"
+)
+
+func deckCount(counter Counter) float64 {
+	count := 0.0
+	cards.ForAllCards(func(card cards.Card) {
+		if card.Value != cards.One {
+			count += counter.Tag(card)
+		}
+	})
+	return count
+}
+
+func TestBalancedSystemsCountADeckToZero(t *testing.T) {
+	for name, counter := range Systems {
+		if name == "ko" {
+			continue
+		}
+		if count := deckCount(counter); count != 0 {
+			t.Errorf("%s counts a full deck to %g, want 0", counter.Name(), count)
+		}
+	}
+}
+
+func TestKOIsUnbalanced(t *testing.T) {
+	if count := deckCount(KO); count != 4 {
+		t.Fatalf("KO counts a full deck to %g, want 4", count)
+	}
+	// starting at 4 - 4 * decks ends a full shoe on +4
+	if start := KO.InitialCount(6); start+6*deckCount(KO) != 4 {
+		t.Fatalf("KO starts 6 decks at %g", start)
+	}
+}
+
+func TestTagIgnoresMaskedCards(t *testing.T) {
+	card := cards.CreateCard(cards.Spades, cards.King)
+	if HiLo.Tag(card) != -1 {
+		t.Fatalf("Hi-Lo should tag a king -1")
+	}
+	card.Masked = true
+	if HiLo.Tag(card) != 0 {
+		t.Fatalf("a masked card has not been seen")
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	counter, err := ParseSystem(" Halves ")
+	if err != nil || counter.Name() != WongHalves.Name() {
+		t.Fatalf("ParseSystem(Halves)=%v, %v", counter, err)
+	}
+	if _, err := ParseSystem("revere"); err == nil {
+		t.Fatalf("expected an error for an unknown system")
+	}
+}
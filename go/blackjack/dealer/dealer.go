@@ -4,195 +4,381 @@ import (
 	"blackjack/cards"
 	"blackjack/flags"
 	"blackjack/game"
+	"blackjack/history"
 	"blackjack/player"
-	"blackjack/random"
 	"blackjack/rules"
 	"blackjack/sidebets"
+	"blackjack/strategy"
 	"blackjack/ui"
 	"blackjack/ui/terminal"
-	"blackjack/utils"
 	"log"
 	"os"
 	"runtime"
+	"sync"
 )
 
-func AskForInsurance(u ui.IO, cfg flags.Config) {
-	for i := 0; i < len(game.State.Players); i++ {
-		player := &game.State.Players[i]
-		u.Render(ui.GameState{AskingForInsurance: true})
+// AskForInsurance offers insurance, or even money, on every hand still in
+// play, before the dealer peeks.
+func AskForInsurance(t *game.Table, u ui.IO, cfg flags.Config) {
+	for i := 0; i < len(t.State.Players); i++ {
+		cardPlayer := &t.State.Players[i]
+		// surrendered hands have nothing left to insure
+		forEachHandInPlay(cardPlayer, func(hand *player.Hand) {
+			u.Render(ui.GameState{AskingForInsurance: true})
 
-		HandlePlayerAction(u, player, cfg)
+			HandlePlayerAction(t, u, cardPlayer, cfg)
+		})
 	}
 }
 
-func BurnCard() {
-	game.State.Shoe.Index = utils.Min(game.State.Shoe.Index+1, len(game.State.Shoe.Cards)-1)
+// AskForSurrender offers early surrender on every hand, before the dealer
+// checks for blackjack. Any answer other than surrender declines.
+func AskForSurrender(t *game.Table, u ui.IO, cfg flags.Config) {
+	for i := 0; i < len(t.State.Players); i++ {
+		playerToAct := &t.State.Players[i]
+		forEachHandInPlay(playerToAct, func(hand *player.Hand) {
+			if !rules.CanSurrender(t, hand, cfg.TableRules) {
+				return
+			}
+
+			u.Render(ui.GameState{AskingForSurrender: true})
+
+			if ReadPlayerAction(u, playerToAct, cfg) == 'u' {
+				recordAction(t, playerToAct, 'u', cfg.TableRules)
+				Surrender(t, playerToAct, cfg.TableRules)
+			} else {
+				recordAction(t, playerToAct, 'n', cfg.TableRules)
+			}
+		})
+	}
+
+	game.SaveBlackjackStateYaml(t)
 }
 
-func DealDealer() {
-	game.State.Dealer.Hands[0].Cards[1].Masked = false
+// forEachHandInPlay calls ask with each of playerToAct's active hands in turn
+// made their ActiveHand, the hand every action plays, so a question before
+// the peek is put to a Blackjack Switch player's second hand too.
+func forEachHandInPlay(playerToAct *player.Player, ask func(hand *player.Hand)) {
+	for j := 0; j < len(playerToAct.Hands); j++ {
+		if !playerToAct.Hands[j].Active {
+			continue
+		}
+
+		held := make([]int, 0)
+		for k := 0; k < j; k++ {
+			if playerToAct.Hands[k].Active {
+				playerToAct.Hands[k].Active = false
+				held = append(held, k)
+			}
+		}
 
-	if rules.CanHit(&game.State.Dealer.Hands[0]) {
-		game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, DealUnmaskedCard())
-		DealDealer()
+		ask(&playerToAct.Hands[j])
+
+		for _, k := range held {
+			playerToAct.Hands[k].Active = true
+		}
 	}
 }
 
-func DealHand(cfg flags.Config) {
-	// make player hands
-	for i := 0; i < len(game.State.Players); i++ {
-		currPlayer := &game.State.Players[i]
+// AskForSwitch offers every Blackjack Switch player the swap of their two
+// hands' second cards, before anything else is played.
+func AskForSwitch(t *game.Table, u ui.IO, cfg flags.Config) {
+	for i := 0; i < len(t.State.Players); i++ {
+		playerToAct := &t.State.Players[i]
+		if !rules.CanSwitch(t, playerToAct) {
+			continue
+		}
 
-		currPlayer.Hands = make([]player.Hand, 0)
+		u.Render(ui.GameState{AskingForSwitch: true})
 
-		if currPlayer.Stack < cfg.MinWager {
-			if currPlayer.Winnings > 0 {
-				currPlayer.Stack = currPlayer.Winnings
-				log.Printf("Player reloaded %s\n", terminal.PrintCurrency(currPlayer.Winnings*100))
-				currPlayer.Winnings = 0
-			} else {
-				currPlayer.Stack = cfg.PlayerStartStack
-				log.Printf("Player takes credit of %s\n", terminal.PrintCurrency(currPlayer.Stack*100))
-				currPlayer.Winnings -= cfg.PlayerStartStack
+		if ReadPlayerAction(u, playerToAct, cfg) == 't' {
+			recordAction(t, playerToAct, 't', cfg.TableRules)
+			SwitchCards(t, playerToAct)
+		} else {
+			recordAction(t, playerToAct, 'n', cfg.TableRules)
+			for j := 0; j < len(playerToAct.Hands); j++ {
+				playerToAct.Hands[j].Switchable = false
 			}
 		}
+	}
+
+	game.SaveBlackjackStateYaml(t)
+}
+
+// SwitchCards swaps the second cards of a Blackjack Switch player's two hands.
+func SwitchCards(t *game.Table, playerToAct *player.Player) {
+	if !rules.CanSwitch(t, playerToAct) {
+		return
+	}
+
+	first, second := &playerToAct.Hands[0], &playerToAct.Hands[1]
+	first.Cards[1], second.Cards[1] = second.Cards[1], first.Cards[1]
+	// repromote the aces
+	for i := 0; i < 2; i++ {
+		first.Cards[i].Demoted = false
+		second.Cards[i].Demoted = false
+	}
+	first.Switchable = false
+	second.Switchable = false
+	first.Switched = true
+	second.Switched = true
+}
+
+func BurnCard(t *game.Table) {
+	game.BurnCard(t)
+}
+
+func DealDealer(t *game.Table, tableRules rules.TableRules) {
+	// the players are done once the dealer plays
+	t.State.Dealer.Hands[0].Active = false
+
+	if len(t.State.Dealer.Hands[0].Cards) == 1 {
+		// without a hole card the dealer's second card comes only now
+		card := DealUnmaskedCard(t)
+		t.State.Dealer.Hands[0].Cards = append(t.State.Dealer.Hands[0].Cards, card)
+		recordCard(t, &t.State.Dealer.Hands[0], card)
+	}
+	RevealHoleCard(t)
+
+	if rules.CanHit(t, &t.State.Dealer.Hands[0], tableRules) {
+		card := DealUnmaskedCard(t)
+		t.State.Dealer.Hands[0].Cards = append(t.State.Dealer.Hands[0].Cards, card)
+		recordCard(t, &t.State.Dealer.Hands[0], card)
+		DealDealer(t, tableRules)
+	}
+}
+
+func DealHand(t *game.Table, cfg flags.Config) {
+	ReloadPlayers(t, cfg)
+	bets := TableSidebets(t, cfg)
+
+	// make player hands
+	for i := 0; i < len(t.State.Players); i++ {
+		currPlayer := &t.State.Players[i]
+
+		currPlayer.Hands = make([]player.Hand, 0)
 
 		if currPlayer.Stack >= cfg.MinWager {
 			hand := player.Hand{Active: true, Cards: make([]cards.Card, 0), Player: currPlayer, Wager: cfg.MinWager}
-			currPlayer.Hands = append(currPlayer.Hands, hand)
 
-			if cfg.Autoplay {
+			if currPlayer.PlaceWager != nil {
+				// autoplay and replayed players bet their own amounts
 				hand.Wager = currPlayer.PlaceWager()
-				currPlayer.LastWager = hand.Wager
 			}
 			currPlayer.Stack -= hand.Wager
+			currPlayer.Hands = append(currPlayer.Hands, hand)
+			recordEvent(t, history.Event{Kind: history.Wager, Seat: i, Amount: hand.Wager})
+
+			if t.Mode == game.Switch && currPlayer.Stack >= hand.Wager {
+				// Blackjack Switch is played on two hands of the same wager
+				currPlayer.Stack -= hand.Wager
+				currPlayer.Hands = append(currPlayer.Hands, hand)
+				recordEvent(t, history.Event{Kind: history.Wager, Seat: i, Hand: 1, Amount: hand.Wager})
+				currPlayer.Hands[0].Switchable = true
+				currPlayer.Hands[1].Switchable = true
+				currPlayer.Hands[1].Dealt = 1
+			}
 
-			if currPlayer.Stack > 0 {
-				if currPlayer.WillPlayTrifecta == nil {
-					currPlayer.WillPlayTrifecta = func(stack int) bool {
-						return true
-					}
+			if currPlayer.PlaceWager != nil {
+				// the stake across every hand dealt
+				currPlayer.LastWager = hand.Wager * len(currPlayer.Hands)
+			}
+
+			if currPlayer.WillPlaySidebet == nil {
+				currPlayer.WillPlaySidebet = func(name string, stack int) bool {
+					return true
 				}
+			}
 
-				if currPlayer.WillPlayTrifecta(currPlayer.Stack) {
-					currPlayer.Stack -= cfg.MinWager / 2
-					currPlayer.Hands[0].TrifectaWager = cfg.MinWager / 2
+			for _, bet := range bets {
+				wager := cfg.MinWager / 2
+				if currPlayer.PlaceSidebet != nil {
+					// replayed players bet what they were logged betting
+					wager = currPlayer.PlaceSidebet(bet.Name())
+				}
+				if wager > 0 && currPlayer.Stack >= wager && currPlayer.WillPlaySidebet(bet.Name(), currPlayer.Stack) {
+					currPlayer.Stack -= wager
+					if currPlayer.Hands[0].Sidebets == nil {
+						currPlayer.Hands[0].Sidebets = make(map[string]int)
+					}
+					currPlayer.Hands[0].Sidebets[bet.Name()] = wager
+					recordEvent(t, history.Event{Kind: history.Sidebet, Seat: i, Sidebet: bet.Name(), Amount: wager})
 				}
 			}
 		}
 	}
 
 	// make dealer hand
-	game.State.Dealer.Hands = make([]player.Hand, 0)
-	game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Active: true, Cards: make([]cards.Card, 0), Player: &game.State.Dealer})
+	t.State.Dealer.Hands = make([]player.Hand, 0)
+	t.State.Dealer.Hands = append(t.State.Dealer.Hands, player.Hand{Active: true, Cards: make([]cards.Card, 0), Player: &t.State.Dealer})
 
 	// deal first card to players
-	player.ForAllPlayers(game.State.Players, func(currPlayer *player.Player) {
-		activeHand := player.ActiveHand(currPlayer)
-		if activeHand != nil {
-			activeHand.Cards = append(activeHand.Cards, DealUnmaskedCard())
+	player.ForAllPlayers(t.State.Players, func(currPlayer *player.Player) {
+		for j := 0; j < len(currPlayer.Hands); j++ {
+			hand := &currPlayer.Hands[j]
+			card := DealUnmaskedCard(t)
+			hand.Cards = append(hand.Cards, card)
+			recordCard(t, hand, card)
 		}
 	})
 
 	// deal first card to dealer
-	game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, DealUnmaskedCard())
+	upCard := DealUnmaskedCard(t)
+	t.State.Dealer.Hands[0].Cards = append(t.State.Dealer.Hands[0].Cards, upCard)
+	recordCard(t, &t.State.Dealer.Hands[0], upCard)
 
 	// deal second card to players
-	player.ForAllPlayers(game.State.Players, func(currPlayer *player.Player) {
-		activeHand := player.ActiveHand(currPlayer)
-		if activeHand != nil {
-			activeHand.Cards = append(activeHand.Cards, DealUnmaskedCard())
+	player.ForAllPlayers(t.State.Players, func(currPlayer *player.Player) {
+		for j := 0; j < len(currPlayer.Hands); j++ {
+			hand := &currPlayer.Hands[j]
+			card := DealUnmaskedCard(t)
+			hand.Cards = append(hand.Cards, card)
+			recordCard(t, hand, card)
 		}
 	})
 
-	// deal second card to dealer
-	game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, DealMaskedCard())
+	// deal second card to dealer, face up in Double Exposure and not until
+	// the players have acted without a hole card
+	if cfg.TableRules.NoHoleCard {
+		return
+	}
+	var holeCard cards.Card
+	if t.Mode == game.DoubleExposure {
+		holeCard = DealUnmaskedCard(t)
+	} else {
+		holeCard = DealMaskedCard(t)
+	}
+	t.State.Dealer.Hands[0].Cards = append(t.State.Dealer.Hands[0].Cards, holeCard)
+	recordCard(t, &t.State.Dealer.Hands[0], holeCard)
 }
 
-func DealPlayers(u ui.IO, cfg flags.Config) {
-	for i := 0; i < len(game.State.Players); i++ {
-		player := &game.State.Players[i]
-		for playerCanPlay := rules.CanPlay(*player, cfg.MinWager); playerCanPlay; playerCanPlay = rules.CanPlay(*player, cfg.MinWager) {
+// badSidebets are the sidebets specs already logged as not parsing, so each
+// is logged once however many rounds and tables fall back from it.
+var badSidebets sync.Map
+
+// TableSidebets are the sidebets cfg has the table offer, none when
+// sidebets are turned off.
+func TableSidebets(t *game.Table, cfg flags.Config) []sidebets.Sidebet {
+	if !cfg.TrifectaStax {
+		return nil
+	}
+
+	bets, err := sidebets.Parse(cfg.Sidebets, t.Mode)
+	if err != nil {
+		if _, logged := badSidebets.LoadOrStore(cfg.Sidebets, true); !logged {
+			log.Printf("%v, playing the game's own sidebets\n", err)
+		}
+		return sidebets.ForGame(t.Mode)
+	}
+	return bets
+}
+
+// ReloadPlayers tops up every player who can no longer cover the minimum,
+// from their winnings when they have any and otherwise on credit.
+func ReloadPlayers(t *game.Table, cfg flags.Config) {
+	for i := 0; i < len(t.State.Players); i++ {
+		currPlayer := &t.State.Players[i]
+
+		if currPlayer.Stack < cfg.MinWager {
+			if currPlayer.Winnings > 0 {
+				currPlayer.Stack = currPlayer.Winnings
+				log.Printf("Player reloaded %s\n", terminal.PrintCurrency(currPlayer.Winnings*100))
+				currPlayer.Winnings = 0
+			} else {
+				currPlayer.Stack = cfg.PlayerStartStack
+				log.Printf("Player takes credit of %s\n", terminal.PrintCurrency(currPlayer.Stack*100))
+				currPlayer.Winnings -= cfg.PlayerStartStack
+			}
+		}
+	}
+}
+
+func DealPlayers(t *game.Table, u ui.IO, cfg flags.Config) {
+	for i := 0; i < len(t.State.Players); i++ {
+		player := &t.State.Players[i]
+		for playerCanPlay := rules.CanPlay(t, *player, cfg.TableRules); playerCanPlay; playerCanPlay = rules.CanPlay(t, *player, cfg.TableRules) {
 			u.Render(ui.GameState{})
 
-			HandlePlayerAction(u, player, cfg)
+			HandlePlayerAction(t, u, player, cfg)
 		}
 	}
 }
 
-func DealRound(u ui.IO, cfg flags.Config) (rune, error) {
+func DealRound(t *game.Table, u ui.IO, cfg flags.Config) (rune, error) {
 	var err error
 	var char rune
 
-	game.State.Rounds += 1
+	t.State.Rounds += 1
 
-	if cfg.Autoplay {
-		if game.State.Rounds > 500 {
-			// clearScr()
-			terminal.PrintStats(cfg.TrifectaStax)
-			if closer, ok := u.(interface{ Close() error }); ok {
-				closer.Close()
-			}
-			if runtime.GOOS != "js" {
-				os.Exit(0)
-			}
-			return 'q', nil
-		}
-	}
-
-	DealHand(cfg)
-
-	if cfg.TrifectaStax {
-		// why does this fail during autoplay?
-		// if !*Autoplay {
-		switch game.GameMode {
-		case game.JackAttack:
-			sidebets.PayJackAttack()
-		case game.Spanish21:
-			sidebets.PaySpanish21Matches()
-		case game.Trifecta:
-			sidebets.PayTrifecta()
-		case game.Trifecta3:
-			sidebets.PayTrifecta3()
-		case game.TrifectaStaxx:
-			sidebets.PayTrifectaStax()
-		case game.Blackjack:
-		default:
-			break
-		}
-		// }
+	if cfg.Autoplay && cfg.Rounds > 0 && t.State.Rounds > cfg.Rounds {
+		// autoplay has played all of its rounds, the caller decides what happens next
+		return 'q', nil
+	}
+
+	// the history starts from the stacks the players bet from
+	ReloadPlayers(t, cfg)
+	beginHistory(t, cfg)
+	winnings := make([]int, len(t.State.Players))
+	for i := 0; i < len(t.State.Players); i++ {
+		winnings[i] = t.State.Players[i].Winnings
+	}
+	DealHand(t, cfg)
+
+	bets := TableSidebets(t, cfg)
+	sidebets.Settle(t, bets, sidebets.AfterDeal)
+	recordSidebetPayouts(t, bets, sidebets.AfterDeal)
+
+	if t.Mode == game.Switch {
+		// the switch is made on the cards as dealt, before the dealer peeks
+		AskForSwitch(t, u, cfg)
+	}
+
+	if cfg.TableRules.Surrender == rules.EarlySurrender && cards.CardToValue(t.State.Dealer.Hands[0].Cards[0], false) >= 10 {
+		// early surrender happens before the dealer peeks under an ace or ten
+		AskForSurrender(t, u, cfg)
 	}
 
-	if game.State.Dealer.Hands[0].Cards[0].Value == cards.Ace {
-		switch game.GameMode {
-		case game.Spanish21:
-			// in Spanish21 Blackjacks are paid out first
-			PayWinners(true, true, false)
+	if t.State.Dealer.Hands[0].Cards[0].Value == cards.Ace && !cfg.TableRules.NoHoleCard {
+		switch t.Mode {
+		case game.Spanish21, game.SuperFun21:
+			// in Spanish21 and Super Fun 21 Blackjacks are paid out first
+			PayWinners(t, true, false, false, cfg.TableRules)
 		default:
 			// no pre-conditions
 		}
 
-		AskForInsurance(u, cfg)
+		if rules.ForGame(t.Mode, cfg.TableRules).InsuranceAllowed {
+			AskForInsurance(t, u, cfg)
+		}
 
-		if rules.IsBlackjack(game.State.Dealer.Hands[0]) {
-			PayInsured()
+		Peek(t)
+		if rules.IsBlackjack(t.State.Dealer.Hands[0]) {
+			PayInsured(t)
 		} else {
-			DealPlayers(u, cfg)
+			CollectInsurance(t)
+			DealPlayers(t, u, cfg)
 		}
 	} else {
-		DealPlayers(u, cfg)
+		Peek(t)
+		DealPlayers(t, u, cfg)
 	}
 
-	DealDealer()
+	DealDealer(t, cfg.TableRules)
+	sidebets.Settle(t, bets, sidebets.AfterDealer)
+	recordSidebetPayouts(t, bets, sidebets.AfterDealer)
 
-	if !rules.CanHit(&game.State.Dealer.Hands[0]) {
-		switch game.GameMode {
-		case game.Spanish21:
-			// payBlackjack is false, because we already paid them in Spanish21
-			PayWinners(false, true, true)
+	if !rules.CanHit(t, &t.State.Dealer.Hands[0], cfg.TableRules) {
+		switch t.Mode {
+		case game.Spanish21, game.SuperFun21:
+			// blackjacks against an ace were already paid before insurance
+			PayWinners(t, t.State.Dealer.Hands[0].Cards[0].Value != cards.Ace || cfg.TableRules.NoHoleCard, true, true, cfg.TableRules)
 		default:
-			PayWinners(true, true, true)
+			PayWinners(t, true, true, true, cfg.TableRules)
+		}
+		for i := 0; i < len(t.State.Players); i++ {
+			t.State.Players[i].LastNet = t.State.Players[i].Winnings - winnings[i]
 		}
+		endHistory(t)
 
 		u.Render(ui.GameState{AskingToDeal: true})
 
@@ -208,14 +394,15 @@ func DealRound(u ui.IO, cfg flags.Config) (rune, error) {
 		}
 
 		if char == 'w' {
-			terminal.PrintStats(cfg.TrifectaStax)
+			terminal.PrintStats(t, cfg.TrifectaStax)
 			char, err = u.ReadAction()
 			if err != nil {
 				log.Fatal(err)
 			}
 		}
 
-		game.ShuffleShoeIfNeeded()
+		game.PickUpCards(t)
+		game.ShuffleShoeIfNeeded(t)
 	} else {
 		u.Render(ui.GameState{})
 	}
@@ -231,76 +418,86 @@ func DeclineInsurance(playerToAct *player.Player) {
 	}
 }
 
-func DoubleDown(playerToAct *player.Player) {
+func DoubleDown(t *game.Table, playerToAct *player.Player, tableRules rules.TableRules) {
 	activeHand := player.ActiveHand(playerToAct)
 	if activeHand != nil {
-		if rules.CanDoubleDown(activeHand) {
-			HitHand(activeHand, true)
-			playerToAct.Stack -= activeHand.Wager
-			activeHand.Wager += activeHand.Wager
+		if rules.CanDoubleDown(t, activeHand, tableRules) {
+			free := rules.IsFreeDouble(t, *activeHand)
+			stake := rules.BuyStake(t, *activeHand)
+			HitHand(t, activeHand, true, tableRules)
+			if free {
+				activeHand.FreeWager += stake
+			} else {
+				playerToAct.Stack -= stake
+			}
+			activeHand.Wager += stake
 		}
 	}
 }
 
-func EvenMoney(playerToAct *player.Player) {
+// EvenMoney pays the active hand's blackjack 1:1 before the dealer peeks and
+// ends the hand, which is settled whatever the dealer has.
+func EvenMoney(t *game.Table, playerToAct *player.Player, tableRules rules.TableRules) {
 	activeHand := player.ActiveHand(playerToAct)
 
 	if activeHand != nil {
-		if rules.CanEvenMoney(activeHand) {
+		if rules.CanEvenMoney(t, activeHand, tableRules) {
 			activeHand.Active = false
 			activeHand.Stand = true
 			activeHand.EvenMoney = true
 
+			// you win original bet + original bet (or 2 * hand.Wager)
+			playerToAct.Stack += 2 * activeHand.Wager
+			t.State.House -= activeHand.Wager
+			t.State.Wins += 1
+			playerToAct.LastHandWon = true
+			playerToAct.LastHandPushed = false
+			playerToAct.Winnings += activeHand.Wager
+			playerToAct.WinStreak += 1
+
 			DeclineInsurance(playerToAct)
 		}
 	}
 }
 
-func HandlePlayerAction(u ui.IO, playerToAct *player.Player, cfg flags.Config) {
-	var char rune
-	var err error
-
-	if cfg.Autoplay {
-		char, err = playerToAct.DoAction()
-	} else {
-		char, err = u.ReadAction()
-	}
-
-	if err != nil {
-		if closer, ok := u.(interface{ Close() error }); ok {
-			closer.Close()
-		}
-		log.Fatal(err)
-	}
+func HandlePlayerAction(t *game.Table, u ui.IO, playerToAct *player.Player, cfg flags.Config) {
+	char := rules.ActionForKey(t.Mode, ReadPlayerAction(u, playerToAct, cfg))
+	recordAction(t, playerToAct, char, cfg.TableRules)
 
 	switch char {
 	case 'a':
-		terminal.PrintAutoPlayTable()
-		HandlePlayerAction(u, playerToAct, cfg)
+		terminal.PrintAutoPlayTable(strategy.ForTable(t, cfg))
+		HandlePlayerAction(t, u, playerToAct, cfg)
 	case 'd':
-		DoubleDown(playerToAct)
+		DoubleDown(t, playerToAct, cfg.TableRules)
 	case 'e':
-		EvenMoney(playerToAct)
+		EvenMoney(t, playerToAct, cfg.TableRules)
 	case 'h':
-		Hit(playerToAct)
+		Hit(t, playerToAct, cfg.TableRules)
 	case 'i':
-		Insure(playerToAct)
+		if activeHand := player.ActiveHand(playerToAct); activeHand != nil && rules.CanInsurance(t, activeHand, cfg.TableRules) {
+			Insure(playerToAct)
+		}
 	case 'n':
 		DeclineInsurance(playerToAct)
 	case 'p':
-		SplitHand(playerToAct)
+		SplitHand(t, playerToAct, cfg.TableRules)
 	case 'r':
-		game.State.Dealer.Hands[0].Cards[1].Masked = false
+		RevealHoleCard(t)
 	case 's':
-		Stand(playerToAct)
+		if activeHand := player.ActiveHand(playerToAct); activeHand == nil || !rules.MustTwist(t, *activeHand, cfg.TableRules) {
+			Stand(playerToAct)
+		}
+	case 'u':
+		Surrender(t, playerToAct, cfg.TableRules)
 	case 'v':
-		terminal.PrintShoeDetails()
-		HandlePlayerAction(u, playerToAct, cfg)
+		terminal.PrintShoeDetails(t)
+		HandlePlayerAction(t, u, playerToAct, cfg)
 	case 'w':
-		terminal.PrintStats(cfg.TrifectaStax)
+		terminal.PrintStats(t, cfg.TrifectaStax)
 		log.Printf("\n=== Bust Cards ===\n")
-		terminal.PrintCards(game.State.BustCards)
-		HandlePlayerAction(u, playerToAct, cfg)
+		terminal.PrintCards(t.State.BustCards)
+		HandlePlayerAction(t, u, playerToAct, cfg)
 	case 'x':
 	case 'q':
 		if closer, ok := u.(interface{ Close() error }); ok {
@@ -313,105 +510,155 @@ func HandlePlayerAction(u ui.IO, playerToAct *player.Player, cfg flags.Config) {
 		break
 	}
 
-	game.SaveBlackjackStateYaml()
+	game.SaveBlackjackStateYaml(t)
 }
 
-func HitHand(hand *player.Hand, doubleDown bool) {
-	if rules.CanHit(hand) {
-		card := DealUnmaskedCard()
+// ReadPlayerAction asks the player, or their autoplay strategy, what to do.
+func ReadPlayerAction(u ui.IO, playerToAct *player.Player, cfg flags.Config) rune {
+	var char rune
+	var err error
+
+	if cfg.Autoplay {
+		char, err = playerToAct.DoAction()
+	} else {
+		char, err = u.ReadAction()
+	}
+
+	if err != nil {
+		if closer, ok := u.(interface{ Close() error }); ok {
+			closer.Close()
+		}
+		log.Fatal(err)
+	}
+
+	return char
+}
+
+func HitHand(t *game.Table, hand *player.Hand, doubleDown bool, tableRules rules.TableRules) {
+	if rules.CanHit(t, hand, tableRules) {
+		card := DealUnmaskedCard(t)
 
 		if doubleDown {
 			card.DoubleDown = true
-			hand.DoubleDown = true
-			hand.Active = false
-			hand.Stand = true
+			if t.Mode == game.Pontoon {
+				// a bought hand plays on, buying or twisting, up to five cards
+				hand.Bought += 1
+				doubleDown = false
+			} else {
+				hand.DoubleDown = true
+			}
+		} else if t.Mode == game.Pontoon {
+			// once twisted a Pontoon hand can only twist or stick
+			hand.Twisted = true
 		}
 
 		hand.Cards = append(hand.Cards, card)
+		recordCard(t, hand, card)
+
+		// settle a bust now, not whenever something next totals the hand
+		value := player.HandValue(hand, false)
+
+		// a Spanish 21 or Super Fun 21 double stays open for the player to stand or rescue it
+		if doubleDown && (!rules.CanRescue(t, hand) || value >= 21) {
+			hand.Active = false
+			hand.Stand = true
+		}
 	}
 }
 
-func Hit(playerToAct *player.Player) {
+func Hit(t *game.Table, playerToAct *player.Player, tableRules rules.TableRules) {
 	activeHand := player.ActiveHand(playerToAct)
 	if activeHand != nil {
-		HitHand(activeHand, false)
+		HitHand(t, activeHand, false, tableRules)
 	}
 }
 
 func Insure(playerToAct *player.Player) {
 	activeHand := player.ActiveHand(playerToAct)
-	if activeHand != nil {
+	if activeHand != nil && playerToAct.Stack >= activeHand.Wager/2 {
 		activeHand.Insured = true
 		activeHand.InsuranceWager = activeHand.Wager / 2
 		activeHand.Player.Stack -= activeHand.InsuranceWager
 	}
 }
 
-func loadShoe() {
-	game.State.Shoe.Cards = make([]cards.Card, 0)
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♣6"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♠10"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♣6"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♥A"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♥A"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♦6"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♥6"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♥3"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♥Q"))
-	game.State.Shoe.Cards = append(game.State.Shoe.Cards, cards.ToCard("♣10"))
-
-	for i := len(game.State.Shoe.Cards); i < len(game.State.Shoe.Decks)*52; i++ {
-		game.State.Shoe.Cards = append(game.State.Shoe.Cards, random.RandomCard())
-	}
-}
-
-func DealUnmaskedCard() cards.Card {
-	cardToDeal := game.State.Shoe.Cards[game.State.Shoe.Index]
+func DealUnmaskedCard(t *game.Table) cards.Card {
+	game.RefillShoe(t)
+	cardToDeal := t.State.Shoe.Cards[t.State.Shoe.Index]
 	cardToDeal.Masked = false
 	cardToDeal.Demoted = false
 	cardToDeal.DoubleDown = false
 
-	// basic hi-lo counting
-	// 2-6 = +1
-	// 7-9 = 0
-	// 10-Ace= -1
-	if cards.CardToValue(cardToDeal, false) == 10 || cardToDeal.Value == cards.Ace || cardToDeal.Value == cards.One {
-		game.State.Count -= 1
-	} else if cardToDeal.Value == 2 || cardToDeal.Value == 3 || cardToDeal.Value == 4 || cardToDeal.Value == 5 || cardToDeal.Value == 6 {
-		game.State.Count += 1
-	} else {
-		game.State.Count += 0
-	}
+	game.CountCard(t, cardToDeal)
 
-	game.State.Shoe.Index += 1
+	t.State.Shoe.Index += 1
 	return cardToDeal
 }
 
-func DealMaskedCard() cards.Card {
-	cardToDeal := game.State.Shoe.Cards[game.State.Shoe.Index]
+func DealMaskedCard(t *game.Table) cards.Card {
+	game.RefillShoe(t)
+	cardToDeal := t.State.Shoe.Cards[t.State.Shoe.Index]
 	cardToDeal.Masked = true
 	cardToDeal.Demoted = false
 	cardToDeal.DoubleDown = false
-	game.State.Shoe.Index += 1
+	t.State.Shoe.Index += 1
 	return cardToDeal
 }
 
-func PayInsured() {
-	player.ForAllPlayers(game.State.Players, func(currPlayer *player.Player) {
+// RevealHoleCard turns the dealer's hole card over, counting it the first
+// time it is seen.
+func RevealHoleCard(t *game.Table) {
+	holeCard := &t.State.Dealer.Hands[0].Cards[1]
+	if holeCard.Masked {
+		holeCard.Masked = false
+		game.CountCard(t, *holeCard)
+		recordEvent(t, history.Event{Kind: history.Reveal, Seat: history.DealerSeat})
+	}
+}
+
+// Peek has the dealer check for blackjack, after which insurance is no longer
+// offered. Without a hole card there is nothing to check, but insurance still
+// closes once the players start to play.
+func Peek(t *game.Table) {
+	t.State.Dealer.Hands[0].Peeked = true
+}
+
+func PayInsured(t *game.Table) {
+	player.ForAllPlayers(t.State.Players, func(currPlayer *player.Player) {
 		player.ForAllHands(currPlayer, func(hand *player.Hand) {
 			if hand.Insured {
-				hand.Player.Stack += 2 * hand.InsuranceWager
-				game.State.House -= hand.InsuranceWager
+				// insurance pays 2:1, plus the insurance wager back
+				hand.Player.Stack += 3 * hand.InsuranceWager
+				t.State.House -= 2 * hand.InsuranceWager
+				hand.Player.Winnings += 2 * hand.InsuranceWager
 			}
 		})
 	})
 
-	game.SaveBlackjackStateYaml()
+	game.SaveBlackjackStateYaml(t)
 }
 
-func PayWinners(payBlackjacks bool, payAllOthers bool, updateStats bool) {
+// CollectInsurance takes every insurance wager for the house once the dealer
+// has peeked and has no blackjack.
+func CollectInsurance(t *game.Table) {
+	player.ForAllPlayers(t.State.Players, func(currPlayer *player.Player) {
+		player.ForAllHands(currPlayer, func(hand *player.Hand) {
+			if hand.Insured {
+				// the insurance wager already came off the stack
+				t.State.House += hand.InsuranceWager
+				hand.Player.Winnings -= hand.InsuranceWager
+			}
+		})
+	})
+
+	game.SaveBlackjackStateYaml(t)
+}
+
+func PayWinners(t *game.Table, payBlackjacks bool, payAllOthers bool, updateStats bool, tableRules rules.TableRules) {
+	tableRules = rules.ForGame(t.Mode, tableRules)
+
 	if payBlackjacks || payAllOthers {
-		dealerHand := game.State.Dealer.Hands[0]
+		dealerHand := t.State.Dealer.Hands[0]
 		softValue := player.HandValue(&dealerHand, true)
 		hardValue := player.HandValue(&dealerHand, false)
 		dealerValue := softValue
@@ -424,21 +671,29 @@ func PayWinners(payBlackjacks bool, payAllOthers bool, updateStats bool) {
 
 		// update dealer stats
 		if updateStats {
-			if rules.IsBlackjack(game.State.Dealer.Hands[0]) {
-				game.State.DealerBlackjacks += 1
+			if rules.IsBlackjack(t.State.Dealer.Hands[0]) {
+				t.State.DealerBlackjacks += 1
 			} else if dealerValue > 21 {
-				game.State.DealerBusts += 1
+				t.State.DealerBusts += 1
 				firstCard := cards.CreateCard(dealerHand.Cards[0].Suite, dealerHand.Cards[0].Value)
-				game.State.BustCards = append(game.State.BustCards, firstCard)
-				game.State.BustCounts[firstCard.Value] += 1
+				t.State.BustCards = append(t.State.BustCards, firstCard)
+				t.State.BustCounts[firstCard.Value] += 1
 			}
 		}
 
-		for i := 0; i < len(game.State.Players); i++ {
-			currPlayer := &game.State.Players[i]
+		for i := 0; i < len(t.State.Players); i++ {
+			currPlayer := &t.State.Players[i]
 			for j := 0; j < len(currPlayer.Hands); j++ {
 				hand := &currPlayer.Hands[j]
 
+				if hand.Surrendered || hand.EvenMoney {
+					// surrendered and even money hands were settled when they were played
+					continue
+				}
+
+				// a free wager is never the player's to lose or get back
+				stake := hand.Wager - hand.FreeWager
+
 				// if hand.Active {
 				softValue := player.HandValue(hand, true)
 				hardValue := player.HandValue(hand, false)
@@ -451,82 +706,119 @@ func PayWinners(payBlackjacks bool, payAllOthers bool, updateStats bool) {
 				}
 
 				if playerValue > 21 {
-					game.State.PlayerBusts += 1
-					game.State.BustCards = append(game.State.BustCards, cards.CreateCard(hand.Cards[0].Suite, hand.Cards[0].Value))
-					game.State.BustCounts[hand.Cards[0].Value] += 1
+					t.State.PlayerBusts += 1
+					t.State.BustCards = append(t.State.BustCards, cards.CreateCard(hand.Cards[0].Suite, hand.Cards[0].Value))
+					t.State.BustCounts[hand.Cards[0].Value] += 1
 				}
 
-				if rules.IsBlackjack(game.State.Dealer.Hands[0]) {
+				if rules.IsBlackjack(t.State.Dealer.Hands[0]) {
 					if payBlackjacks {
-						if hand.EvenMoney {
-							// you win original bet + original bet (or 2 * hand.Wager)
-							// this path shouldn't happen for Spanish21 because we don't AskForInsurance() by the dealer
-							currPlayer.Stack += (2 * hand.Wager)
-							game.State.House -= hand.Wager
-							game.State.Wins += 1
+						if rules.BlackjackAlwaysWins(t) && rules.IsBlackjack(*hand) {
+							// a Spanish21 or Super Fun 21 blackjack still beats the dealer's
+							winnings := rules.BlackjackWinnings(t, *hand, tableRules)
+							currPlayer.Stack += winnings + hand.Wager
+							t.State.House -= winnings
+							t.State.Wins += 1
 							currPlayer.LastHandWon = true
 							currPlayer.LastHandPushed = false
-							currPlayer.Winnings += hand.Wager
+							currPlayer.Winnings += winnings
+							t.State.PlayerBlackjacks += 1
 							currPlayer.WinStreak += 1
+						} else if rules.IsBlackjack(*hand) && !rules.DealerWinsTies(t) {
+							// blackjack against blackjack, you win your original bet back
+							currPlayer.Stack += stake
+							t.State.Pushes += 1
+							currPlayer.LastHandWon = false
+							currPlayer.LastHandPushed = true
+							currPlayer.WinStreak = 0
 						} else {
-							game.State.House += hand.Wager
-							game.State.Losses += 1
+							// without a hole card, original bets only gives back the doubles and splits
+							loss := rules.OriginalBetsOnlyLoss(t, *hand, j, tableRules)
+							currPlayer.Stack += stake - loss
+							t.State.House += loss
+							t.State.Losses += 1
 							currPlayer.LastHandWon = false
 							currPlayer.LastHandPushed = false
 							currPlayer.WinStreak = 0
-							currPlayer.Winnings -= hand.Wager
+							currPlayer.Winnings -= loss
 						}
 					}
 				} else {
 					if rules.IsBlackjack(*hand) {
 						if payBlackjacks {
-							// you win time and a half + orignal bet
-							winnings := hand.Wager + (hand.Wager / 2.0) + hand.Wager
-							currPlayer.Stack += winnings
-							game.State.House -= winnings
-							game.State.Wins += 1
+							// you win the table's blackjack payout + orignal bet
+							winnings := rules.BlackjackWinnings(t, *hand, tableRules)
+							currPlayer.Stack += winnings + hand.Wager
+							t.State.House -= winnings
+							t.State.Wins += 1
 							currPlayer.LastHandWon = true
 							currPlayer.LastHandPushed = false
 							currPlayer.Winnings += winnings
-							game.State.PlayerBlackjacks += 1
+							t.State.PlayerBlackjacks += 1
 							currPlayer.WinStreak += 1
 						}
+					} else if !payAllOthers {
+						// only blackjacks are being paid
+					} else if playerValue > 21 {
+						// player busted, whatever the dealer ends on
+						currPlayer.Stack += 0
+						t.State.House += stake
+						t.State.Losses += 1
+						currPlayer.LastHandWon = false
+						currPlayer.LastHandPushed = false
+						currPlayer.WinStreak = 0
+						currPlayer.Winnings -= stake
+					} else if rules.DealerPushes22(t) {
+						// a Free Bet dealer's 22 pushes every hand still standing
+						currPlayer.Stack += stake
+						t.State.Pushes += 1
+						currPlayer.LastHandWon = false
+						currPlayer.LastHandPushed = true
+						currPlayer.WinStreak = 0
+					} else if winnings, ok := rules.FiveCardWinnings(t, *hand); ok {
+						// a five card trick, or a Super Fun 21 five card 20 or 21, wins whatever the dealer has
+						currPlayer.Stack += winnings + stake
+						t.State.House -= winnings
+						t.State.Wins += 1
+						currPlayer.LastHandWon = true
+						currPlayer.LastHandPushed = false
+						currPlayer.Winnings += winnings
+						currPlayer.WinStreak += 1
+					} else if t.Mode == game.Spanish21 && playerValue == 21 {
+						// a Spanish21 player's 21 always wins, with any bonus it earns
+						winnings := rules.Spanish21Winnings(t, *hand)
+						currPlayer.Stack += winnings + stake
+						t.State.House -= winnings
+						t.State.Wins += 1
+						currPlayer.LastHandWon = true
+						currPlayer.LastHandPushed = false
+						currPlayer.Winnings += winnings
+						currPlayer.WinStreak += 1
 					} else if dealerValue < playerValue {
-						if playerValue <= 21 {
-							// you win original bet + original bet (or 2 * hand.Wager)
-							currPlayer.Stack += 2 * hand.Wager
-							game.State.House -= hand.Wager
-							game.State.Wins += 1
-							currPlayer.LastHandWon = true
-							currPlayer.LastHandPushed = false
-							currPlayer.Winnings += hand.Wager
-							currPlayer.WinStreak += 1
-						} else {
-							// player busted
-							currPlayer.Stack += 0
-							game.State.House += hand.Wager
-							game.State.Losses += 1
-							currPlayer.LastHandWon = false
-							currPlayer.LastHandPushed = false
-							currPlayer.WinStreak = 0
-							currPlayer.Winnings -= hand.Wager
-						}
+						// you win original bet + original bet (or 2 * hand.Wager), less any free wager
+						currPlayer.Stack += stake + hand.Wager
+						t.State.House -= hand.Wager
+						t.State.Wins += 1
+						currPlayer.LastHandWon = true
+						currPlayer.LastHandPushed = false
+						currPlayer.Winnings += hand.Wager
+						currPlayer.WinStreak += 1
 					} else if dealerValue > 21 {
 						if payAllOthers {
-							// you win original bet + original bet (or 2 * hand.Wager)
-							currPlayer.Stack += 2 * hand.Wager
-							game.State.House -= hand.Wager
-							game.State.Wins += 1
+							// you win original bet + original bet (or 2 * hand.Wager), less any free wager
+							currPlayer.Stack += stake + hand.Wager
+							t.State.House -= hand.Wager
+							t.State.Wins += 1
 							currPlayer.LastHandWon = true
 							currPlayer.LastHandPushed = false
-							currPlayer.Winnings += 2 * hand.Wager
+							currPlayer.Winnings += hand.Wager
 							currPlayer.WinStreak += 1
 						}
-					} else if dealerValue == playerValue {
-						if payAllOthers { // you win your original bet back (or hand.Wager)
-							currPlayer.Stack += hand.Wager
-							game.State.House -= 0
-							game.State.Pushes += 1
+					} else if dealerValue == playerValue && !rules.DealerWinsTies(t) {
+						if payAllOthers { // you win your original bet back (or hand.Wager), less any free wager
+							currPlayer.Stack += stake
+							t.State.House -= 0
+							t.State.Pushes += 1
 							currPlayer.LastHandWon = false
 							currPlayer.LastHandPushed = true
 							currPlayer.WinStreak = 0
@@ -535,12 +827,12 @@ func PayWinners(payBlackjacks bool, payAllOthers bool, updateStats bool) {
 					} else {
 						if payAllOthers {
 							currPlayer.Stack += 0
-							game.State.House += hand.Wager
-							game.State.Losses += 1
+							t.State.House += stake
+							t.State.Losses += 1
 							currPlayer.LastHandWon = false
 							currPlayer.LastHandPushed = false
 							currPlayer.WinStreak = 0
-							currPlayer.Winnings -= hand.Wager
+							currPlayer.Winnings -= stake
 						}
 					}
 				}
@@ -549,17 +841,22 @@ func PayWinners(payBlackjacks bool, payAllOthers bool, updateStats bool) {
 		}
 	}
 
-	game.SaveBlackjackStateYaml()
+	game.SaveBlackjackStateYaml(t)
 }
 
-func SplitHand(playerToAct *player.Player) {
+func SplitHand(t *game.Table, playerToAct *player.Player, tableRules rules.TableRules) {
 	activeHand := player.ActiveHand(playerToAct)
 
 	if activeHand != nil {
-		if rules.CanSplit(*activeHand) {
+		if rules.CanSplit(t, *activeHand, tableRules) {
 			activeHand.Split = true
-			newHand := player.Hand{Active: true, Cards: make([]cards.Card, 0), Player: playerToAct, Split: true, Wager: activeHand.Wager}
-			playerToAct.Stack -= newHand.Wager
+			newHand := player.Hand{Active: true, Cards: make([]cards.Card, 0), Player: playerToAct, Split: true, Wager: activeHand.Wager, Dealt: activeHand.Dealt}
+			if rules.IsFreeSplit(t, *activeHand) {
+				// the house puts up the new hand's wager
+				newHand.FreeWager = newHand.Wager
+			} else {
+				playerToAct.Stack -= newHand.Wager
+			}
 
 			newHand.Cards = append(newHand.Cards, activeHand.Cards[1])
 
@@ -567,10 +864,35 @@ func SplitHand(playerToAct *player.Player) {
 			activeHand.Cards[0].Demoted = false
 			newHand.Cards[0].Demoted = false
 
-			activeHand.Cards[1] = DealUnmaskedCard()
-			newHand.Cards = append(newHand.Cards, DealUnmaskedCard())
+			activeHand.Cards[1] = DealUnmaskedCard(t)
+			recordCard(t, activeHand, activeHand.Cards[1])
+			newHand.Cards = append(newHand.Cards, DealUnmaskedCard(t))
 
 			playerToAct.Hands = append(playerToAct.Hands, newHand)
+			recordCard(t, &playerToAct.Hands[len(playerToAct.Hands)-1], newHand.Cards[1])
+		}
+	}
+}
+
+// Surrender forfeits half of the active hand's wager and ends the hand.
+func Surrender(t *game.Table, playerToAct *player.Player, tableRules rules.TableRules) {
+	activeHand := player.ActiveHand(playerToAct)
+	if activeHand != nil {
+		if rules.CanSurrender(t, activeHand, tableRules) {
+			refund := activeHand.Wager / 2
+			forfeit := activeHand.Wager - refund
+
+			activeHand.Surrendered = true
+			activeHand.Active = false
+			activeHand.Stand = true
+
+			playerToAct.Stack += refund
+			t.State.House += forfeit
+			t.State.Surrenders += 1
+			playerToAct.LastHandWon = false
+			playerToAct.LastHandPushed = false
+			playerToAct.WinStreak = 0
+			playerToAct.Winnings -= forfeit
 		}
 	}
 }
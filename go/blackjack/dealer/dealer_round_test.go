@@ -5,6 +5,7 @@ import (
 	"blackjack/flags"
 	"blackjack/game"
 	"blackjack/player"
+	"blackjack/rules"
 	"blackjack/ui"
 	"testing"
 )
@@ -36,31 +37,319 @@ func TestDealRoundUsesIO(t *testing.T) {
 		Autoplay:         false,
 		DrawCards:        false,
 		Clean:            true,
+		TableRules:       rules.DefaultTableRules(),
 	}
 
-	game.State = game.BlackjackState{
-		BustCounts: make(map[cards.CardValue]int),
-		Dealer:     player.Player{Dealer: true},
-		Players:    make([]player.Player, 0),
-	}
-	for _, v := range cards.CardValues {
-		game.State.BustCounts[v] = 0
-	}
+	table := game.NewTable(game.Spanish21, 1)
 
-	p := player.CreatePlayer(cfg.PlayerStartStack, cfg.MinWager)
+	p := player.CreatePlayer(table.Rand, cfg.PlayerStartStack, cfg.MinWager)
 	p.PlaceWager = func() int { return cfg.MinWager }
 	p.DoAction = func() (rune, error) { return 's', nil }
-	game.State.Players = append(game.State.Players, p)
+	table.State.Players = append(table.State.Players, p)
 
-	game.CreateShoe(cfg.NumOfDecks)
-	loadShoe()
-	game.State.Shoe.Index = 0
+	game.CreateShoe(table, cfg.NumOfDecks)
+	stack, err := game.ParseStack("♣6 ♠10 ♣6 ♥A ♥A ♦6 ♥6 ♥3 ♥Q ♣10 random")
+	if err != nil {
+		t.Fatalf("ParseStack returned error: %v", err)
+	}
+	game.StackShoe(table, stack)
 
 	io := &stubIO{}
-	if _, err := DealRound(io, cfg); err != nil {
+	if _, err := DealRound(table, io, cfg); err != nil {
 		t.Fatalf("DealRound returned error: %v", err)
 	}
 	if len(io.renders) == 0 {
 		t.Fatalf("expected DealRound to render game state")
 	}
 }
+
+func TestDealRoundSwitch(t *testing.T) {
+	cfg := flags.Config{
+		NumOfDecks:       1,
+		NumOfPlayers:     1,
+		MinWager:         10,
+		PlayerStartStack: 100,
+		TableRules:       rules.DefaultTableRules(),
+	}
+
+	table := game.NewTable(game.Switch, 1)
+	table.State.Players = append(table.State.Players, player.Player{Stack: 100, PlaceWager: func() int { return 10 }})
+	// each hand's first card, the up card, each hand's second card and the hole card
+	table.State.Shoe = game.Shoe{Cards: player.ToHand([]string{"♠10", "♥6", "♣7", "♦5", "♠K", "♥10", "♣2"}).Cards}
+
+	DealHand(table, cfg)
+	cardPlayer := &table.State.Players[0]
+	if len(cardPlayer.Hands) != 2 || cardPlayer.Stack != 80 || cardPlayer.LastWager != 20 || !rules.CanSwitch(table, cardPlayer) {
+		t.Fatalf("expected two switchable hands of 10, hands %d stack %d wager %d", len(cardPlayer.Hands), cardPlayer.Stack, cardPlayer.LastWager)
+	}
+
+	io := &stubIO{actions: []rune{'t'}}
+	AskForSwitch(table, io, cfg)
+	if len(io.renders) != 1 || !io.renders[0].AskingForSwitch {
+		t.Fatalf("expected to be asked to switch, renders %v", io.renders)
+	}
+	if got := player.HandValue(&cardPlayer.Hands[0], false); got != 20 || rules.CanSwitch(table, cardPlayer) {
+		t.Fatalf("switching 10 5 and 6 K should make 20, got %d", got)
+	}
+}
+
+func TestAskEverySwitchHandBeforeThePeek(t *testing.T) {
+	cfg := flags.Config{MinWager: 10, TableRules: rules.DefaultTableRules()}
+	cfg.TableRules.Surrender = rules.EarlySurrender
+
+	table := game.NewTable(game.Switch, 1)
+	table.State.Players = append(table.State.Players, player.Player{Stack: 100})
+	table.State.Shoe = game.Shoe{Cards: player.ToHand([]string{"♠10", "♥9", "♣A", "♦6", "♠7", "♥5"}).Cards}
+	DealHand(table, cfg)
+	cardPlayer := &table.State.Players[0]
+
+	io := &stubIO{actions: []rune{'i', 'i'}}
+	AskForInsurance(table, io, cfg)
+	if len(io.renders) != 2 || !cardPlayer.Hands[0].Insured || !cardPlayer.Hands[1].Insured || cardPlayer.Stack != 70 {
+		t.Fatalf("both hands should be asked and insured, renders %d stack %d", len(io.renders), cardPlayer.Stack)
+	}
+	if !cardPlayer.Hands[0].Active || !cardPlayer.Hands[1].Active {
+		t.Fatalf("asking should leave both hands to play")
+	}
+
+	cardPlayer.Hands[1].Insured, cardPlayer.Hands[1].InsuranceWager = false, 0
+	Peek(table)
+	if rules.CanInsurance(table, &cardPlayer.Hands[1], cfg.TableRules) {
+		t.Fatalf("insurance should not be offered once the dealer has peeked")
+	}
+
+	// the second hand can be surrendered on its own
+	table.State.Dealer.Hands[0].Cards[0] = cards.CreateCard(cards.Clubs, cards.King)
+	io = &stubIO{actions: []rune{'n', 'u'}}
+	AskForSurrender(table, io, cfg)
+	if len(io.renders) != 2 || cardPlayer.Hands[0].Surrendered || !cardPlayer.Hands[1].Surrendered || !cardPlayer.Hands[0].Active {
+		t.Fatalf("only the second hand should surrender, hands %+v", cardPlayer.Hands)
+	}
+}
+
+func TestPayWinnersSwitch(t *testing.T) {
+	table := game.NewTable(game.Switch, 1)
+	dealerHand := player.ToHand([]string{"♠10", "♥2", "♣K"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 80}}
+	cardPlayer := &table.State.Players[0]
+	for _, cardStrings := range [][]string{{"♣A", "♦K"}, {"♣10", "♦8"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Wager = 10
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+
+	PayWinners(table, true, true, true, rules.DefaultTableRules())
+	if cardPlayer.Stack != 110 || table.State.Pushes != 1 {
+		t.Fatalf("blackjack should pay even money and 18 push against 22, stack %d pushes %d", cardPlayer.Stack, table.State.Pushes)
+	}
+}
+
+func TestSwitchedTwentyOneIsNoBlackjack(t *testing.T) {
+	// 21 pushes against either dealer hand, 18 pushes against 22 and loses to 21
+	for _, test := range []struct {
+		dealerCards []string
+		stack       int
+	}{
+		{[]string{"♠10", "♥2", "♣K"}, 100},
+		{[]string{"♠10", "♥2", "♣9"}, 90},
+	} {
+		table := game.NewTable(game.Switch, 1)
+		dealerHand := player.ToHand(test.dealerCards)
+		dealerHand.Player = &table.State.Dealer
+		table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+		table.State.Players = []player.Player{{Stack: 80}}
+		cardPlayer := &table.State.Players[0]
+		for _, cardStrings := range [][]string{{"♣A", "♦8"}, {"♣10", "♦K"}} {
+			hand := player.ToHand(cardStrings)
+			hand.Player = cardPlayer
+			hand.Wager = 10
+			hand.Switchable = true
+			cardPlayer.Hands = append(cardPlayer.Hands, hand)
+		}
+
+		// switching makes A K and 10 8
+		SwitchCards(table, cardPlayer)
+		if rules.IsBlackjack(cardPlayer.Hands[0]) {
+			t.Fatalf("a switched A K should not be a blackjack")
+		}
+
+		PayWinners(table, true, true, true, rules.DefaultTableRules())
+		if cardPlayer.Stack != test.stack || table.State.PlayerBlackjacks != 0 {
+			t.Fatalf("a switched A K should push against %v, stack %d", test.dealerCards, cardPlayer.Stack)
+		}
+	}
+}
+
+func TestDealHandWithoutHoleCard(t *testing.T) {
+	cfg := flags.Config{MinWager: 10, TableRules: rules.DefaultTableRules()}
+	cfg.TableRules.NoHoleCard = true
+
+	table := game.NewTable(game.Blackjack, 1)
+	table.State.Players = append(table.State.Players, player.Player{Stack: 100})
+	table.State.Shoe = game.Shoe{Cards: player.ToHand([]string{"♠10", "♥6", "♣7", "♦K", "♠2"}).Cards}
+
+	DealHand(table, cfg)
+	if got := len(table.State.Dealer.Hands[0].Cards); got != 1 || !rules.DealerToPlay(table) {
+		t.Fatalf("the dealer should only have an up card, has %d", got)
+	}
+	if !rules.CanDoubleDown(table, &table.State.Players[0].Hands[0], cfg.TableRules) {
+		t.Fatalf("the player should still be able to act")
+	}
+
+	DealDealer(table, cfg.TableRules)
+	if got := player.HandValue(&table.State.Dealer.Hands[0], false); got != 18 || rules.DealerToPlay(table) {
+		t.Fatalf("the dealer should draw its second card once the players are done and hit 16 to 18, got %d", got)
+	}
+}
+
+func TestPayWinnersNoHoleCardOriginalBetsOnly(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	dealerHand := player.ToHand([]string{"♠10", "♥A"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 0}}
+	cardPlayer := &table.State.Players[0]
+	for i, cardStrings := range [][]string{{"♣8", "♦3", "♥9"}, {"♦8", "♣10"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Split = true
+		hand.Wager = 10
+		if i == 0 {
+			hand.DoubleDown = true
+			hand.Wager = 20
+		}
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+
+	tableRules := rules.DefaultTableRules()
+	tableRules.NoHoleCard = true
+	PayWinners(table, true, true, true, tableRules)
+	if cardPlayer.Stack != 20 || table.State.House != 10 {
+		t.Fatalf("only the original 10 should be lost, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+
+	cardPlayer.Stack, table.State.House = 0, 0
+	tableRules.OriginalBetsOnly = false
+	PayWinners(table, true, true, true, tableRules)
+	if cardPlayer.Stack != 0 || table.State.House != 30 {
+		t.Fatalf("all 30 should be lost, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+}
+
+func TestDoubleExposure(t *testing.T) {
+	cfg := flags.Config{MinWager: 10, TableRules: rules.DefaultTableRules()}
+
+	table := game.NewTable(game.DoubleExposure, 1)
+	table.State.Players = append(table.State.Players, player.Player{Stack: 100})
+	table.State.Shoe = game.Shoe{Cards: player.ToHand([]string{"♠10", "♥10", "♣8", "♦8"}).Cards}
+
+	DealHand(table, cfg)
+	if table.State.Dealer.Hands[0].Cards[1].Masked || !rules.DealerToPlay(table) {
+		t.Fatalf("both dealer cards should be face up while the players act")
+	}
+	if rules.CanInsurance(table, &table.State.Players[0].Hands[0], cfg.TableRules) {
+		t.Fatalf("there is nothing to insure against in Double Exposure")
+	}
+
+	// the dealer's 18 takes the player's 18 and their blackjack is paid even money
+	cardPlayer := &table.State.Players[0]
+	cardPlayer.Hands = append(cardPlayer.Hands, player.Hand{Cards: player.ToHand([]string{"♣A", "♦K"}).Cards, Player: cardPlayer, Wager: 10})
+	cardPlayer.Stack = 0
+	DealDealer(table, cfg.TableRules)
+	PayWinners(table, true, true, true, cfg.TableRules)
+	if cardPlayer.Stack != 20 || table.State.House != 0 {
+		t.Fatalf("the tie should lose and the blackjack win 10, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+}
+
+func TestDiscardTrayHoldsEveryDealtCard(t *testing.T) {
+	for _, mode := range []game.Game{game.Blackjack, game.Spanish21, game.Switch, game.Pontoon, game.DoubleExposure} {
+		cfg := flags.Config{
+			NumOfDecks:       2,
+			NumOfPlayers:     3,
+			MinWager:         1,
+			PlayerStartStack: 1000,
+			Autoplay:         true,
+			Clean:            true,
+			Seed:             5,
+			TableRules:       rules.DefaultTableRules(),
+			ShuffleRecipe:    game.ShuffleRecipes["standard"],
+		}
+		cfg.TableRules.Surrender = rules.LateSurrender
+
+		table := OpenTable(cfg, mode, "")
+		AutoplayPlayers(table, cfg)
+		for round := 0; round < 200; round++ {
+			if _, err := DealRound(table, &stubIO{}, cfg); err != nil {
+				t.Fatalf("DealRound returned error: %v", err)
+			}
+			if len(table.State.Shoe.Discards) != table.State.Shoe.Index {
+				t.Fatalf("%s round %d: %d cards on the tray, %d dealt", mode, round, len(table.State.Shoe.Discards), table.State.Shoe.Index)
+			}
+		}
+	}
+}
+
+func TestSingleDeckRoundsAtAFullTable(t *testing.T) {
+	cfg := flags.Config{
+		NumOfDecks:       1,
+		NumOfPlayers:     6,
+		MinWager:         1,
+		PlayerStartStack: 1000,
+		Autoplay:         true,
+		Clean:            true,
+		Seed:             3,
+		TableRules:       rules.DefaultTableRules(),
+		Penetration:      game.Penetration{Percent: game.MaxPenetration},
+	}
+
+	// Super Fun 21 is always dealt from one deck, however many seats are played
+	table := OpenTable(cfg, game.SuperFun21, "")
+	AutoplayPlayers(table, cfg)
+	for round := 0; round < 500; round++ {
+		if _, err := DealRound(table, &stubIO{}, cfg); err != nil {
+			t.Fatalf("DealRound returned error: %v", err)
+		}
+		if len(table.State.Shoe.Cards) != 52 || len(table.State.Shoe.Discards) != table.State.Shoe.Index {
+			t.Fatalf("round %d: %d cards in the shoe, %d on the tray, %d dealt", round, len(table.State.Shoe.Cards), len(table.State.Shoe.Discards), table.State.Shoe.Index)
+		}
+	}
+}
+
+func TestStackedShoeForcesDealerBlackjack(t *testing.T) {
+	cfg := flags.Config{
+		NumOfDecks:       6,
+		NumOfPlayers:     1,
+		MinWager:         10,
+		PlayerStartStack: 1000,
+		Autoplay:         true,
+		Clean:            true,
+		Seed:             1,
+		TableRules:       rules.DefaultTableRules(),
+		StackedShoe:      "8♠ A♥ 8♦ K♣",
+	}
+
+	table := OpenTable(cfg, game.Blackjack, "")
+	AutoplayPlayers(table, cfg)
+	if _, err := DealRound(table, &stubIO{}, cfg); err != nil {
+		t.Fatalf("DealRound returned error: %v", err)
+	}
+
+	dealerHand := table.State.Dealer.Hands[0]
+	if !rules.IsBlackjack(dealerHand) || dealerHand.Cards[0].Value != cards.Ace {
+		t.Fatalf("expected the stacked dealer blackjack got %v", dealerHand.Cards)
+	}
+	playerHand := table.State.Players[0].Hands[0]
+	if playerHand.Cards[0].Value != cards.Eight || playerHand.Cards[1].Value != cards.Eight || len(playerHand.Cards) != 2 {
+		t.Fatalf("expected the stacked pair of eights, unplayed, got %v", playerHand.Cards)
+	}
+	if table.State.Players[0].Stack != 990 {
+		t.Fatalf("expected the wager lost to the blackjack got a stack of %d", table.State.Players[0].Stack)
+	}
+}
@@ -1,39 +1,465 @@
 package dealer
 
 import (
+	"blackjack/cards"
+	"blackjack/flags"
 	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+	"blackjack/sidebets"
+	"bytes"
+	"log"
+	"os"
+	"strings"
 	"testing"
 )
 
-func setupShoe() {
-	game.CreateShoe(1)
-	game.State.Shoe.Index = 0
+func setupShoe() *game.Table {
+	table := game.NewTable(game.Blackjack, 1)
+	game.CreateShoe(table, 1)
+	table.State.Shoe.Index = 0
+	return table
 }
 
 func TestBurnCard(t *testing.T) {
-	setupShoe()
-	initial := game.State.Shoe.Index
-	BurnCard()
-	if game.State.Shoe.Index != initial+1 {
-		t.Fatalf("expected index %d got %d", initial+1, game.State.Shoe.Index)
-	}
-	game.State.Shoe.Index = len(game.State.Shoe.Cards) - 1
-	BurnCard()
-	if game.State.Shoe.Index != len(game.State.Shoe.Cards)-1 {
+	table := setupShoe()
+	initial := table.State.Shoe.Index
+	BurnCard(table)
+	if table.State.Shoe.Index != initial+1 {
+		t.Fatalf("expected index %d got %d", initial+1, table.State.Shoe.Index)
+	}
+	table.State.Shoe.Index = len(table.State.Shoe.Cards) - 1
+	BurnCard(table)
+	if table.State.Shoe.Index != len(table.State.Shoe.Cards)-1 {
 		t.Fatalf("burn should not advance past end")
 	}
 }
 
 func TestDealMaskedCard(t *testing.T) {
-	setupShoe()
-	c := DealMaskedCard()
+	table := setupShoe()
+	c := DealMaskedCard(table)
 	if !c.Masked {
 		t.Fatalf("card should be masked")
 	}
 	if c.Demoted || c.DoubleDown {
 		t.Fatalf("card flags not reset: %+v", c)
 	}
-	if game.State.Shoe.Index != 1 {
-		t.Fatalf("expected shoe index 1 got %d", game.State.Shoe.Index)
+	if table.State.Shoe.Index != 1 {
+		t.Fatalf("expected shoe index 1 got %d", table.State.Shoe.Index)
+	}
+}
+
+func TestSurrender(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	dealerHand := player.ToHand([]string{"♠10", "♥7"})
+	dealerHand.Player = &table.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 90}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣10", "♦6"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+
+	tableRules := rules.DefaultTableRules()
+	Surrender(table, cardPlayer, tableRules)
+	if cardPlayer.Hands[0].Surrendered {
+		t.Fatalf("surrender should not be allowed when the table does not offer it")
+	}
+
+	tableRules.Surrender = rules.LateSurrender
+	Surrender(table, cardPlayer, tableRules)
+	if !cardPlayer.Hands[0].Surrendered || cardPlayer.Hands[0].Active {
+		t.Fatalf("expected hand to be surrendered and inactive")
+	}
+	if cardPlayer.Stack != 95 {
+		t.Fatalf("expected half the wager back, stack %d want 95", cardPlayer.Stack)
+	}
+	if table.State.House != 5 || table.State.Surrenders != 1 {
+		t.Fatalf("unexpected house %d surrenders %d", table.State.House, table.State.Surrenders)
+	}
+}
+
+func TestRevealHoleCardCountsOnce(t *testing.T) {
+	table := setupShoe()
+	game.ResetCount(table)
+	table.State.Dealer.Hands = []player.Hand{{
+		Cards: []cards.Card{cards.CreateCard(cards.Spades, cards.King), cards.CreateCard(cards.Hearts, cards.Five)},
+	}}
+	table.State.Dealer.Hands[0].Cards[1].Masked = true
+
+	RevealHoleCard(table)
+	RevealHoleCard(table)
+
+	if table.State.Dealer.Hands[0].Cards[1].Masked {
+		t.Fatalf("hole card should be revealed")
+	}
+	if table.State.Count != 1 {
+		t.Fatalf("hole card should be counted once, count %g", table.State.Count)
+	}
+}
+
+func TestPayWinnersBustedPlayerLoses(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	dealerHand := player.ToHand([]string{"♠10", "♥6", "♣9"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 90}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣10", "♦4", "♥8"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+
+	PayWinners(table, true, true, true, rules.DefaultTableRules())
+	if cardPlayer.Stack != 90 || table.State.Losses != 1 {
+		t.Fatalf("22 should lose to a dealer bust of 25, stack %d losses %d", cardPlayer.Stack, table.State.Losses)
+	}
+}
+
+func TestPayWinnersBlackjackPushesDealerBlackjack(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	dealerHand := player.ToHand([]string{"♠A", "♥K"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 80}}
+	cardPlayer := &table.State.Players[0]
+	for _, cardStrings := range [][]string{{"♣A", "♦Q"}, {"♣10", "♦9"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Wager = 10
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+
+	PayWinners(table, true, true, true, rules.DefaultTableRules())
+	if cardPlayer.Stack != 90 || table.State.Pushes != 1 || table.State.House != 10 {
+		t.Fatalf("the blackjack should push and the 19 lose, stack %d pushes %d house %d", cardPlayer.Stack, table.State.Pushes, table.State.House)
+	}
+}
+
+func TestPayInsuredPaysTwoToOne(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	table.State.Players = []player.Player{{Stack: 85}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣10", "♦8"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	hand.Insured = true
+	hand.InsuranceWager = 5
+	cardPlayer.Hands = []player.Hand{hand}
+
+	PayInsured(table)
+	if cardPlayer.Stack != 100 || table.State.House != -10 {
+		t.Fatalf("insurance should pay 2:1, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+}
+
+func TestEvenMoneyIsPaidOnce(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	dealerHand := player.ToHand([]string{"♠A", "♥7"})
+	dealerHand.Player = &table.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 0}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣A", "♦K"})
+	hand.Player = cardPlayer
+	hand.Active = true
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+	tableRules := rules.DefaultTableRules()
+
+	EvenMoney(table, cardPlayer, tableRules)
+	if cardPlayer.Stack != 20 || table.State.House != -10 || cardPlayer.Winnings != 10 {
+		t.Fatalf("even money should pay 1:1 when it is taken, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+
+	// the dealer has no blackjack, and the hand is not paid again
+	RevealHoleCard(table)
+	PayWinners(table, true, true, true, tableRules)
+	if cardPlayer.Stack != 20 || table.State.House != -10 || cardPlayer.Winnings != 10 {
+		t.Fatalf("an even money hand should not be paid again, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+}
+
+func TestCollectInsurance(t *testing.T) {
+	table := game.NewTable(game.Blackjack, 1)
+	table.State.Players = []player.Player{{Stack: 85}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣10", "♦8"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	hand.Insured = true
+	hand.InsuranceWager = 5
+	cardPlayer.Hands = []player.Hand{hand}
+
+	CollectInsurance(table)
+	if cardPlayer.Stack != 85 || cardPlayer.Winnings != -5 || table.State.House != 5 {
+		t.Fatalf("lost insurance should go to the house, stack %d winnings %d house %d", cardPlayer.Stack, cardPlayer.Winnings, table.State.House)
+	}
+}
+
+func TestPayWinnersSpanish21(t *testing.T) {
+	table := game.NewTable(game.Spanish21, 1)
+	dealerHand := player.ToHand([]string{"♠9", "♥5", "♣7"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 80}}
+	cardPlayer := &table.State.Players[0]
+	for _, cardStrings := range [][]string{{"♣9", "♦A", "♥A"}, {"♣2", "♦3", "♥4", "♠5", "♣7"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Wager = 10
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+
+	PayWinners(table, true, true, true, rules.DefaultTableRules())
+	if cardPlayer.Stack != 125 || table.State.Wins != 2 {
+		t.Fatalf("21s should beat the dealer's 21, the five card 21 at 3:2, stack %d wins %d", cardPlayer.Stack, table.State.Wins)
+	}
+}
+
+func TestPayWinnersSpanish21Blackjacks(t *testing.T) {
+	table := game.NewTable(game.Spanish21, 1)
+	dealerHand := player.ToHand([]string{"♠A", "♥K"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 90}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣A", "♦K"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+
+	PayWinners(table, true, false, false, rules.DefaultTableRules())
+	if cardPlayer.Stack != 115 {
+		t.Fatalf("a Spanish 21 blackjack should beat the dealer's at 3:2, stack %d", cardPlayer.Stack)
+	}
+}
+
+func TestRescueDouble(t *testing.T) {
+	table := game.NewTable(game.Spanish21, 1)
+	table.State.Shoe = game.Shoe{Cards: []cards.Card{cards.CreateCard(cards.Clubs, cards.Five)}}
+	dealerHand := player.ToHand([]string{"♠9", "♥7"})
+	dealerHand.Player = &table.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 90}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣7", "♦4"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+
+	tableRules := rules.DefaultTableRules()
+	DoubleDown(table, cardPlayer, tableRules)
+	if !cardPlayer.Hands[0].Active || cardPlayer.Hands[0].Stand {
+		t.Fatalf("a Spanish 21 double should stay open to be rescued")
+	}
+
+	Surrender(table, cardPlayer, tableRules)
+	if !cardPlayer.Hands[0].Surrendered || cardPlayer.Stack != 90 || table.State.House != 10 {
+		t.Fatalf("a rescue should return the double, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+}
+
+func TestFreeBet(t *testing.T) {
+	table := game.NewTable(game.FreeBet, 1)
+	table.State.Shoe = game.Shoe{Cards: player.ToHand([]string{"♣2", "♦9", "♥10"}).Cards}
+	dealerHand := player.ToHand([]string{"♠10", "♥6"})
+	dealerHand.Player = &table.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 0}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣8", "♦8"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+
+	tableRules := rules.DefaultTableRules()
+	SplitHand(table, cardPlayer, tableRules)
+	if len(cardPlayer.Hands) != 2 || cardPlayer.Stack != 0 || cardPlayer.Hands[1].FreeWager != 10 {
+		t.Fatalf("the split should be free, hands %d stack %d", len(cardPlayer.Hands), cardPlayer.Stack)
+	}
+
+	// 8 2 is a free double to 8 2 10
+	DoubleDown(table, cardPlayer, tableRules)
+	if cardPlayer.Hands[0].Wager != 20 || cardPlayer.Hands[0].FreeWager != 10 || cardPlayer.Stack != 0 {
+		t.Fatalf("the double should be free, wager %d free %d stack %d", cardPlayer.Hands[0].Wager, cardPlayer.Hands[0].FreeWager, cardPlayer.Stack)
+	}
+
+	table.State.Dealer.Hands[0].Cards[1].Masked = false
+	PayWinners(table, true, true, true, tableRules)
+	if cardPlayer.Stack != 40 || table.State.House != -30 {
+		t.Fatalf("20 and the free 17 should win 30 on the player's 10, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+}
+
+func TestFreeBetDealer22Pushes(t *testing.T) {
+	table := game.NewTable(game.FreeBet, 1)
+	dealerHand := player.ToHand([]string{"♠10", "♥6", "♣6"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 80}}
+	cardPlayer := &table.State.Players[0]
+	for _, cardStrings := range [][]string{{"♣10", "♦8"}, {"♣A", "♦K"}, {"♣10", "♦6", "♥9"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Wager = 10
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+
+	PayWinners(table, true, true, true, rules.DefaultTableRules())
+	if cardPlayer.Stack != 115 || table.State.Pushes != 1 {
+		t.Fatalf("18 should push, blackjack win and 25 lose against 22, stack %d pushes %d", cardPlayer.Stack, table.State.Pushes)
+	}
+}
+
+func TestSwitchCardsRepromotesAces(t *testing.T) {
+	table := game.NewTable(game.Switch, 1)
+	table.State.Players = []player.Player{{Stack: 80}}
+	cardPlayer := &table.State.Players[0]
+	for _, cardStrings := range [][]string{{"♣4", "♥10"}, {"♣A", "♠A"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Wager = 10
+		hand.Switchable = true
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+	// totalling a pair of aces demotes one of them
+	player.HandValue(&cardPlayer.Hands[1], false)
+
+	SwitchCards(table, cardPlayer)
+	if got := player.HandValue(&cardPlayer.Hands[1], false); got != 21 || rules.IsBlackjack(cardPlayer.Hands[1]) {
+		t.Fatalf("switching in the 10 should make a 21 that is no blackjack, got %d", got)
+	}
+}
+
+func TestPayWinnersPontoon(t *testing.T) {
+	table := game.NewTable(game.Pontoon, 1)
+	dealerHand := player.ToHand([]string{"♠10", "♥K"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 0}}
+	cardPlayer := &table.State.Players[0]
+	for _, cardStrings := range [][]string{{"♣10", "♦Q"}, {"♣2", "♦3", "♥4", "♠5", "♣2"}, {"♣A", "♦K"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Wager = 10
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+
+	PayWinners(table, true, true, true, rules.DefaultTableRules())
+	if cardPlayer.Stack != 60 || table.State.House != -30 {
+		t.Fatalf("the tie should lose and the five card trick and pontoon win 2:1, stack %d house %d", cardPlayer.Stack, table.State.House)
+	}
+}
+
+func TestPontoonBuyPlaysOn(t *testing.T) {
+	table := game.NewTable(game.Pontoon, 1)
+	table.State.Shoe = game.Shoe{Cards: player.ToHand([]string{"♠2", "♥3", "♣2", "♦4"}).Cards}
+	dealerHand := player.ToHand([]string{"♠7", "♥K"})
+	dealerHand.Player = &table.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 100}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣5", "♦4"})
+	hand.Player = cardPlayer
+	hand.Active = true
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+	tableRules := rules.DefaultTableRules()
+
+	DoubleDown(table, cardPlayer, tableRules)
+	DoubleDown(table, cardPlayer, tableRules)
+	bought := &cardPlayer.Hands[0]
+	if len(bought.Cards) != 4 || !bought.Active || bought.Wager != 30 || cardPlayer.Stack != 80 {
+		t.Fatalf("each buy should add the dealt stake and play on, cards %d wager %d stack %d", len(bought.Cards), bought.Wager, cardPlayer.Stack)
+	}
+
+	// a fifth card makes the five card trick, which takes no more cards
+	Hit(table, cardPlayer, tableRules)
+	if len(bought.Cards) != 5 || rules.CanHit(table, bought, tableRules) || rules.CanDoubleDown(table, bought, tableRules) {
+		t.Fatalf("expected a complete five card trick, got %v", bought.Cards)
+	}
+}
+
+func TestPontoonNoBuyAfterTwist(t *testing.T) {
+	table := game.NewTable(game.Pontoon, 1)
+	table.State.Shoe = game.Shoe{Cards: player.ToHand([]string{"♠2", "♥3"}).Cards}
+	dealerHand := player.ToHand([]string{"♠7", "♥K"})
+	dealerHand.Player = &table.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 100}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣5", "♦4"})
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+	tableRules := rules.DefaultTableRules()
+
+	Hit(table, cardPlayer, tableRules)
+	twisted := &cardPlayer.Hands[0]
+	if !rules.CanHit(table, twisted, tableRules) || rules.CanDoubleDown(table, twisted, tableRules) {
+		t.Fatalf("a twisted hand should twist on but not buy, cards %v", twisted.Cards)
+	}
+
+	DoubleDown(table, cardPlayer, tableRules)
+	if len(twisted.Cards) != 3 || twisted.Wager != 10 || cardPlayer.Stack != 100 {
+		t.Fatalf("buying after a twist should do nothing, cards %d wager %d stack %d", len(twisted.Cards), twisted.Wager, cardPlayer.Stack)
+	}
+}
+
+func TestPayWinnersSuperFun21(t *testing.T) {
+	table := game.NewTable(game.SuperFun21, 1)
+	dealerHand := player.ToHand([]string{"♠A", "♥K"})
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 0}}
+	cardPlayer := &table.State.Players[0]
+	for _, cardStrings := range [][]string{{"♦A", "♦K"}, {"♣A", "♦K"}} {
+		hand := player.ToHand(cardStrings)
+		hand.Player = cardPlayer
+		hand.Wager = 10
+		cardPlayer.Hands = append(cardPlayer.Hands, hand)
+	}
+
+	PayWinners(table, true, false, false, rules.DefaultTableRules())
+	if cardPlayer.Stack != 50 {
+		t.Fatalf("both blackjacks should beat the dealer's, diamonds at 2:1, stack %d", cardPlayer.Stack)
+	}
+}
+
+func TestTableSidebetsLogsABadSpec(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	table := game.NewTable(game.Blackjack, 1)
+	cfg := flags.Config{TrifectaStax: true, Sidebets: "21+3,nosuchbet"}
+	for i := 0; i < 3; i++ {
+		if got := TableSidebets(table, cfg); len(got) != len(sidebets.ForGame(game.Blackjack)) {
+			t.Fatalf("a bad spec should fall back to the game's own sidebets, got %d", len(got))
+		}
+	}
+	if strings.Count(logged.String(), "playing the game's own sidebets") != 1 {
+		t.Fatalf("the bad spec should be logged once, got %q", logged.String())
 	}
 }
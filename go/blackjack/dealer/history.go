@@ -0,0 +1,180 @@
+package dealer
+
+import (
+	"blackjack/cards"
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/history"
+	"blackjack/player"
+	"blackjack/rules"
+	"blackjack/sidebets"
+	"encoding/json"
+	"log"
+	"strings"
+)
+
+// beginHistory starts logging a round, from the table rules, decks, minimum
+// and sidebets it is dealt under, the stacks the players bring to it and the progressives it
+// could pay. Nothing is logged when the table has no HistoryPath.
+func beginHistory(t *game.Table, cfg flags.Config) {
+	if t.HistoryPath == "" {
+		return
+	}
+
+	t.History = history.Round{
+		Round:     t.State.Rounds,
+		Seed:      t.State.Seed,
+		Game:      t.Mode.String(),
+		Decks:     cfg.NumOfDecks,
+		Minimum:   cfg.MinWager,
+		Sidebets:  sidebets.Spec(TableSidebets(t, cfg)),
+		ShoeIndex: t.State.Shoe.Index,
+		House:     t.State.House,
+		Stacks:    make([]int, len(t.State.Players)),
+		Events:    make([]history.Event, 0),
+	}
+
+	if tableRules, err := json.Marshal(cfg.TableRules); err == nil {
+		t.History.TableRules = tableRules
+	} else {
+		log.Printf("Could not log the table rules of round %d: %v\n", t.History.Round, err)
+	}
+
+	for i := 0; i < len(t.State.Players); i++ {
+		t.History.Stacks[i] = t.State.Players[i].Stack
+	}
+
+	for _, meter := range t.Progressives.Meters() {
+		if t.History.Meters == nil {
+			t.History.Meters = make(map[string]int)
+		}
+		t.History.Meters[meter.Name] = meter.Amount
+	}
+}
+
+// endHistory logs what each seat and the house netted and appends the round
+// to the table's hand history.
+func endHistory(t *game.Table) {
+	if t.HistoryPath == "" {
+		return
+	}
+
+	for i := 0; i < len(t.State.Players) && i < len(t.History.Stacks); i++ {
+		recordEvent(t, history.Event{Kind: history.Net, Seat: i, Amount: t.State.Players[i].Stack - t.History.Stacks[i]})
+	}
+	recordEvent(t, history.Event{Kind: history.Net, Seat: history.DealerSeat, Amount: t.State.House - t.History.House})
+
+	if err := history.Append(t.HistoryPath, t.History); err != nil {
+		log.Printf("Could not log round %d: %v\n", t.History.Round, err)
+	}
+}
+
+func recordEvent(t *game.Table, event history.Event) {
+	if t.HistoryPath == "" {
+		return
+	}
+	t.History.Events = append(t.History.Events, event)
+}
+
+// recordSidebetPayouts logs what the bets that resolve at timing paid.
+func recordSidebetPayouts(t *game.Table, bets []sidebets.Sidebet, timing sidebets.Timing) {
+	if t.HistoryPath == "" {
+		return
+	}
+
+	for i := 0; i < len(t.State.Players); i++ {
+		hands := t.State.Players[i].Hands
+		if len(hands) == 0 {
+			continue
+		}
+		for _, bet := range bets {
+			if winnings := hands[0].SidebetWinnings[bet.Name()]; winnings > 0 && bet.Resolves() == timing {
+				recordEvent(t, history.Event{Kind: history.SidebetPayout, Seat: i, Sidebet: bet.Name(), Amount: winnings})
+			}
+		}
+	}
+}
+
+// recordCard logs card being dealt to hand.
+func recordCard(t *game.Table, hand *player.Hand, card cards.Card) {
+	if t.HistoryPath == "" {
+		return
+	}
+
+	kind := history.Deal
+	if card.Masked {
+		kind = history.Hole
+	}
+
+	seat, handIndex := seatOf(t, hand)
+	recordEvent(t, history.Event{Kind: kind, Seat: seat, Hand: handIndex, Card: history.CardString(card)})
+}
+
+// recordAction logs action being played on playerToAct's active hand, before
+// it is played. Plays that will not go ahead are left out, and free doubles
+// and splits add nothing.
+func recordAction(t *game.Table, playerToAct *player.Player, action rune, tableRules rules.TableRules) {
+	if t.HistoryPath == "" {
+		return
+	}
+
+	hand := player.ActiveHand(playerToAct)
+	if hand == nil || !strings.ContainsRune("dehinpstu", action) {
+		return
+	}
+
+	amount := 0
+	switch action {
+	case 'd':
+		if !rules.CanDoubleDown(t, hand, tableRules) {
+			return
+		}
+		if !rules.IsFreeDouble(t, *hand) {
+			amount = rules.BuyStake(t, *hand)
+		}
+	case 'p':
+		if !rules.CanSplit(t, *hand, tableRules) {
+			return
+		}
+		if !rules.IsFreeSplit(t, *hand) {
+			amount = hand.Wager
+		}
+	case 'e':
+		if !rules.CanEvenMoney(t, hand, tableRules) {
+			return
+		}
+	case 'h':
+		if !rules.CanHit(t, hand, tableRules) {
+			return
+		}
+	case 'i':
+		if !rules.CanInsurance(t, hand, tableRules) {
+			return
+		}
+		amount = hand.Wager / 2
+	case 'u':
+		if !rules.CanSurrender(t, hand, tableRules) {
+			return
+		}
+	case 's':
+		if rules.MustTwist(t, *hand, tableRules) {
+			return
+		}
+	}
+
+	seat, handIndex := seatOf(t, hand)
+	recordEvent(t, history.Event{Kind: history.Action, Seat: seat, Hand: handIndex, Action: string(action), Amount: amount})
+}
+
+// seatOf finds hand at the table, history.DealerSeat for the dealer's hand.
+func seatOf(t *game.Table, hand *player.Hand) (int, int) {
+	for i := 0; i < len(t.State.Players); i++ {
+		for j := 0; j < len(t.State.Players[i].Hands); j++ {
+			if &t.State.Players[i].Hands[j] == hand {
+				return i, j
+			}
+		}
+	}
+
+	return history.DealerSeat, 0
+}
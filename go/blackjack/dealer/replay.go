@@ -0,0 +1,204 @@
+package dealer
+
+import (
+	"blackjack/cards"
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/history"
+	"blackjack/player"
+	"blackjack/sidebets"
+	"blackjack/ui"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// script plays back a round's logged actions in the order they were asked
+// for, dealing again whenever the table asks to deal.
+type script struct {
+	actions []rune
+	dealing bool
+	overran bool
+}
+
+func (s *script) ReadAction() (rune, error) {
+	if s.dealing {
+		return 'd', nil
+	}
+	if len(s.actions) == 0 {
+		// the replay asked for more than was logged, stand and let the
+		// payouts show where it went wrong
+		s.overran = true
+		return 's', nil
+	}
+
+	action := s.actions[0]
+	s.actions = s.actions[1:]
+	return action, nil
+}
+
+func (s *script) Render(state ui.GameState) {
+	s.dealing = state.AskingToDeal
+}
+
+// ReplayRound deals round again, as it was logged to a hand history, from
+// the same cards, wagers, actions and progressive meters and checks each
+// seat's stack, the house's total and the sidebet payouts come out as they
+// were logged. The round is dealt under the table rules, decks and minimum it
+// logged, and under cfg's when it was logged without them. The sidebets are
+// always the ones it logged, played for the logged amounts.
+func ReplayRound(round history.Round, cfg flags.Config) error {
+	mode, err := game.ParseGame(round.Game)
+	if err != nil {
+		return fmt.Errorf("round %d: %w", round.Round, err)
+	}
+
+	if round.Decks > 0 {
+		cfg.NumOfDecks = round.Decks
+	}
+	if round.Minimum > 0 {
+		cfg.MinWager = round.Minimum
+	}
+	cfg.TrifectaStax = true
+	cfg.Sidebets = round.Sidebets
+	if cfg.Sidebets == "" {
+		// logged before the sidebets were, offer the ones that were played
+		cfg.Sidebets = sidebets.Spec(playedSidebets(round))
+	}
+	if len(round.TableRules) > 0 {
+		if err := json.Unmarshal(round.TableRules, &cfg.TableRules); err != nil {
+			return fmt.Errorf("round %d: table rules: %w", round.Round, err)
+		}
+	}
+
+	t := game.NewTable(mode, round.Seed)
+	game.ResetState(t)
+	t.State.Rounds = round.Round - 1
+	t.State.House = round.House
+	t.State.Dealer = player.Player{Dealer: true}
+
+	// the progressives start where they were, to pay what they paid
+	sidebets.AddMeters(t, TableSidebets(t, cfg))
+	for name, amount := range round.Meters {
+		t.Progressives.Set(name, amount)
+	}
+
+	// stack the shoe with the logged cards, in the order they were dealt
+	game.CreateShoe(t, cfg.NumOfDecks)
+	dealt := 0
+	for _, event := range round.Events {
+		if event.Kind == history.Deal || event.Kind == history.Hole {
+			if dealt == len(t.State.Shoe.Cards) {
+				t.State.Shoe.Cards = append(t.State.Shoe.Cards, cards.Card{})
+			}
+			t.State.Shoe.Cards[dealt] = cards.ToCard(event.Card)
+			dealt++
+		}
+	}
+	t.State.Shoe.Index = 0
+	t.State.Shoe.Cut = len(t.State.Shoe.Cards)
+	t.State.Shoe.Discards = nil
+
+	wagers := make([]int, len(round.Stacks))
+	played := make([]map[string]int, len(round.Stacks))
+	u := &script{}
+	for _, event := range round.Events {
+		switch {
+		case event.Seat < 0 || event.Seat >= len(round.Stacks):
+		case event.Kind == history.Wager:
+			wagers[event.Seat] = event.Amount
+		case event.Kind == history.Sidebet:
+			if played[event.Seat] == nil {
+				played[event.Seat] = make(map[string]int)
+			}
+			played[event.Seat][event.Sidebet] = event.Amount
+		case event.Kind == history.Action && event.Action != "":
+			u.actions = append(u.actions, []rune(event.Action)[0])
+		}
+	}
+
+	for i, stack := range round.Stacks {
+		wager, sidebetsPlayed := wagers[i], played[i]
+		t.State.Players = append(t.State.Players, player.Player{
+			Hands:           make([]player.Hand, 0),
+			Stack:           stack,
+			PlaceWager:      func() int { return wager },
+			WillPlaySidebet: func(name string, stack int) bool { return sidebetsPlayed[name] > 0 },
+			PlaceSidebet:    func(name string) int { return sidebetsPlayed[name] },
+		})
+	}
+
+	cfg.Autoplay = false
+	if _, err := DealRound(t, u, cfg); err != nil {
+		return fmt.Errorf("round %d: %w", round.Round, err)
+	}
+
+	return compareRound(t, round, u)
+}
+
+// playedSidebets are the sidebets wagered on in round, in the order they
+// were first played.
+func playedSidebets(round history.Round) []sidebets.Sidebet {
+	bets := make([]sidebets.Sidebet, 0)
+	seen := make(map[string]bool)
+	for _, event := range round.Events {
+		if event.Kind != history.Sidebet || seen[event.Sidebet] {
+			continue
+		}
+		seen[event.Sidebet] = true
+		if bet, ok := sidebets.ByName(event.Sidebet); ok {
+			bets = append(bets, bet)
+		}
+	}
+	return bets
+}
+
+// compareRound lists every way the replayed table differs from round.
+func compareRound(t *game.Table, round history.Round, u *script) error {
+	problems := make([]string, 0)
+	if u.overran {
+		problems = append(problems, "asked for more actions than were logged")
+	} else if len(u.actions) > 0 {
+		problems = append(problems, fmt.Sprintf("left %d logged actions unplayed", len(u.actions)))
+	}
+
+	payouts := make([]map[string]int, len(round.Stacks))
+	for _, event := range round.Events {
+		if event.Seat < history.DealerSeat || event.Seat >= len(round.Stacks) {
+			continue
+		}
+
+		switch {
+		case event.Kind == history.Net && event.Seat == history.DealerSeat:
+			if got := t.State.House - round.House; got != event.Amount {
+				problems = append(problems, fmt.Sprintf("house netted %d, logged %d", got, event.Amount))
+			}
+		case event.Kind == history.Net:
+			if got := t.State.Players[event.Seat].Stack - round.Stacks[event.Seat]; got != event.Amount {
+				problems = append(problems, fmt.Sprintf("seat %d netted %d, logged %d", event.Seat+1, got, event.Amount))
+			}
+		case event.Kind == history.SidebetPayout:
+			if payouts[event.Seat] == nil {
+				payouts[event.Seat] = make(map[string]int)
+			}
+			payouts[event.Seat][event.Sidebet] = event.Amount
+		}
+	}
+
+	for i := range payouts {
+		hands := t.State.Players[i].Hands
+		if len(hands) == 0 {
+			continue
+		}
+		for _, name := range sidebets.Wagered(hands[0]) {
+			if got := hands[0].SidebetWinnings[name]; got != payouts[i][name] {
+				problems = append(problems, fmt.Sprintf("seat %d %s paid %d, logged %d", i+1, name, got, payouts[i][name]))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("round %d: %s", round.Round, strings.Join(problems, ", "))
+	}
+	return nil
+}
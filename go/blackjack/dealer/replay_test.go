@@ -0,0 +1,114 @@
+package dealer
+
+import (
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/history"
+	"blackjack/player"
+	"blackjack/rules"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplayRound(t *testing.T) {
+	cfg := flags.Config{
+		NumOfDecks:       6,
+		NumOfPlayers:     3,
+		MinWager:         10,
+		PlayerStartStack: 1000,
+		TrifectaStax:     true,
+		Autoplay:         true,
+		Clean:            true,
+		Seed:             11,
+		HistoryPath:      filepath.Join(t.TempDir(), "history.jsonl"),
+		Betting:          "flat,martingale",
+		TableRules:       rules.DefaultTableRules(),
+	}
+	cfg.TableRules.Surrender = rules.LateSurrender
+
+	for _, mode := range []game.Game{game.Blackjack, game.Spanish21} {
+		table := OpenTable(cfg, mode, "")
+		AutoplayPlayers(table, cfg)
+		for round := 0; round < 200; round++ {
+			DealRound(table, &stubIO{}, cfg)
+		}
+	}
+
+	rounds, err := history.ReadFile(cfg.HistoryPath)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	if len(rounds) != 400 {
+		t.Fatalf("got %d rounds want 400", len(rounds))
+	}
+
+	for _, round := range rounds {
+		if err := ReplayRound(round, cfg); err != nil {
+			t.Fatalf("%s %v", round.Game, err)
+		}
+	}
+
+	// a payout that differs from the log is reported
+	round := rounds[len(rounds)-1]
+	round.Events[len(round.Events)-1].Amount += 5
+	if err := ReplayRound(round, cfg); err == nil {
+		t.Fatalf("a wrong house total should not replay")
+	}
+	round.Events[len(round.Events)-1].Amount -= 5
+
+	// rounds replay under the rules, decks, minimum and sidebets they logged,
+	// whatever cfg says
+	cfg.TableRules.Surrender = rules.NoSurrender
+	cfg.NumOfDecks = 1
+	cfg.MinWager = 50
+	cfg.TrifectaStax = false
+	for _, round := range rounds {
+		if err := ReplayRound(round, cfg); err != nil {
+			t.Fatalf("%s under other flags %v", round.Game, err)
+		}
+	}
+
+	// and a round logged without them under cfg's
+	mismatched := 0
+	for _, round := range rounds {
+		round.TableRules = nil
+		if err := ReplayRound(round, cfg); err != nil {
+			mismatched++
+		}
+	}
+	if mismatched == 0 {
+		t.Fatalf("rounds with surrenders should not replay without surrender")
+	}
+}
+
+func TestRecordActionLeavesOutPlaysNotMade(t *testing.T) {
+	cfg := flags.Config{MinWager: 10, TableRules: rules.DefaultTableRules()}
+
+	table := game.NewTable(game.Blackjack, 1)
+	table.HistoryPath = filepath.Join(t.TempDir(), "history.jsonl")
+	dealerHand := player.ToHand([]string{"♠7", "♥K"})
+	dealerHand.Player = &table.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	table.State.Players = []player.Player{{Stack: 100}}
+	cardPlayer := &table.State.Players[0]
+	hand := player.ToHand([]string{"♣K", "♦5", "♥6"})
+	hand.Player = cardPlayer
+	hand.Active = true
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+
+	// 21 takes no card, there is no surrender and nothing to insure against a 7
+	HandlePlayerAction(table, &stubIO{actions: []rune{'h'}}, cardPlayer, cfg)
+	HandlePlayerAction(table, &stubIO{actions: []rune{'u'}}, cardPlayer, cfg)
+	HandlePlayerAction(table, &stubIO{actions: []rune{'i'}}, cardPlayer, cfg)
+	if len(table.History.Events) != 0 || cardPlayer.Hands[0].Insured || cardPlayer.Stack != 100 {
+		t.Fatalf("plays that were not made should not be logged, got %+v", table.History.Events)
+	}
+
+	HandlePlayerAction(table, &stubIO{actions: []rune{'s'}}, cardPlayer, cfg)
+	if len(table.History.Events) != 1 || table.History.Events[0].Action != "s" {
+		t.Fatalf("the stand should be logged, got %+v", table.History.Events)
+	}
+}
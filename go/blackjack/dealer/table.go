@@ -0,0 +1,161 @@
+package dealer
+
+import (
+	"blackjack/betting"
+	"blackjack/cards"
+	"blackjack/counting"
+	"blackjack/deviations"
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/progressive"
+	"blackjack/rules"
+	"blackjack/sidebets"
+	"blackjack/strategy"
+	"log"
+)
+
+// OpenTable sets up a table for cfg. It seeds the table's random source,
+// restores the state saved at statePath unless cfg.Clean (otherwise it builds
+// and cuts a fresh shoe), seats cfg.NumOfPlayers players and logs every round
+// to cfg.HistoryPath. Its progressives are kept in cfg.ProgressivesPath,
+// shared with every other table opened on the same path, and set up by
+// cfg.MetersPath.
+func OpenTable(cfg flags.Config, mode game.Game, statePath string) *game.Table {
+	t := game.NewTable(mode, cfg.Seed)
+	t.StatePath = statePath
+	t.HistoryPath = cfg.HistoryPath
+	seed := t.State.Seed
+
+	if counter, err := counting.ParseSystem(cfg.CountSystem); err == nil {
+		t.Counter = counter
+	} else if cfg.CountSystem != "" {
+		log.Printf("%v, counting with %s\n", err, t.Counter.Name())
+	}
+
+	if bank, err := progressive.Open(cfg.ProgressivesPath); err == nil {
+		t.Progressives = bank
+	} else {
+		log.Printf("%v, starting the progressives over\n", err)
+	}
+	if settings, err := progressive.LoadSettings(cfg.MetersPath); err == nil {
+		t.Progressives.Configure(settings...)
+	} else {
+		log.Printf("%v, keeping the meters' settings\n", err)
+	}
+	sidebets.AddMeters(t, TableSidebets(t, cfg))
+
+	if err := game.LoadBlackjackStateYaml(t, cfg.Clean); err != nil {
+		game.ResetState(t)
+		t.State.House = cfg.HouseStart
+		newShoe(t, cfg)
+	} else if cfg.StackedShoe != "" {
+		// a stacked shoe is dealt now, in place of the saved one
+		newShoe(t, cfg)
+	} else {
+		// a saved shoe is dealt on, and shuffled, as this session asks
+		t.State.Shoe.Penetration, t.State.Shoe.Shuffler, t.State.Shoe.Recipe = cfg.Penetration, cfg.Shuffler, cfg.ShuffleRecipe
+	}
+
+	// remember the seed so this session can be replayed with -seed
+	t.State.Seed = seed
+
+	if t.State.Players == nil || len(t.State.Players) == 0 {
+		for i := 0; i < cfg.NumOfPlayers; i++ {
+			t.State.Players = append(t.State.Players, player.CreatePlayer(t.Rand, cfg.PlayerStartStack, cfg.MinWager))
+		}
+	}
+
+	if !t.State.Dealer.Dealer {
+		t.State.Dealer = player.Player{Dealer: true}
+	}
+
+	return t
+}
+
+// newShoe shuffles, burns and cuts a new shoe for cfg, or deals its stacked
+// cards first from it, unburnt, when cfg.StackedShoe has some.
+func newShoe(t *game.Table, cfg flags.Config) {
+	game.CreateShoe(t, cfg.NumOfDecks)
+	t.State.Shoe.Penetration, t.State.Shoe.Shuffler, t.State.Shoe.Recipe = cfg.Penetration, cfg.Shuffler, cfg.ShuffleRecipe
+
+	if cfg.StackedShoe != "" {
+		stack, err := game.LoadStack(cfg.StackedShoe)
+		if err == nil {
+			game.CutShoe(t)
+			game.StackShoe(t, stack)
+			return
+		}
+		log.Printf("%v, dealing a shuffled shoe\n", err)
+	}
+
+	BurnCard(t)
+	game.CutShoe(t)
+	game.ResetCount(t)
+}
+
+// AutoplayPlayers lets the table's players wager by cfg.Betting and act on
+// their own, by the table's basic strategy and cfg.Deviations.
+func AutoplayPlayers(t *game.Table, cfg flags.Config) {
+	strategyTable := strategy.ForTable(t, cfg)
+	deviationSet, err := deviations.Load(cfg.Deviations)
+	if err != nil {
+		log.Printf("%v, playing basic strategy\n", err)
+	}
+
+	limits := betting.Limits{Minimum: cfg.MinWager, Maximum: cfg.MaxWager}
+	bettingOptions := betting.Options{Spread: cfg.BetSpread, KellyFraction: cfg.KellyFraction, Bankroll: cfg.Bankroll}
+
+	for i := 0; i < len(t.State.Players); i++ {
+		cardPlayer := &t.State.Players[i]
+
+		bettingStrategy, err := betting.ForPlayer(cfg.Betting, i, bettingOptions)
+		if err != nil {
+			log.Printf("%v, betting flat\n", err)
+			bettingStrategy = betting.Flat{}
+		}
+
+		cardPlayer.PlaceWager = func() int {
+			return betting.Bet(bettingStrategy, t, cardPlayer, limits)
+		}
+
+		cardPlayer.DoAction = func() (rune, error) {
+			defer func() {
+				if r := recover(); r != nil {
+					if err, ok := r.(error); ok && err != nil {
+						log.Printf("We panicked!!! Why?!?!?")
+					}
+				}
+			}()
+
+			activeHand := player.ActiveHand(cardPlayer)
+			dealerFaceUpCard := cards.CardToValue(t.State.Dealer.Hands[0].Cards[0], true)
+
+			if rules.CanSwitch(t, cardPlayer) {
+				// only asked straight after the deal, answering it settles it
+				if strategy.ShouldSwitch(t, cfg, cardPlayer.Hands[0], cardPlayer.Hands[1]) {
+					return 't', nil
+				}
+				return 'n', nil
+			}
+
+			if activeHand != nil && !activeHand.Insured && (rules.CanInsurance(t, activeHand, cfg.TableRules) || rules.CanEvenMoney(t, activeHand, cfg.TableRules)) {
+				// only asked while the dealer offers insurance, answering it settles it
+				if deviations.Insure(deviationSet, game.TrueCount(t)) == 'i' {
+					if rules.CanEvenMoney(t, activeHand, cfg.TableRules) {
+						return 'e', nil
+					}
+					return 'i', nil
+				}
+				return 'n', nil
+			}
+
+			roundStrategy := strategyTable
+			if t.Mode == game.DoubleExposure {
+				// played against both of the dealer's cards
+				roundStrategy = strategy.ForRound(t, cfg)
+			}
+			return deviations.GetAutoPlayPlayerAction(t, activeHand, dealerFaceUpCard, cfg.TableRules, roundStrategy, deviationSet)
+		}
+	}
+}
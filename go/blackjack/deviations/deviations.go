@@ -0,0 +1,278 @@
+package deviations
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// HandKind is which of a hand's charts a deviation changes.
+type HandKind string
+
+const (
+	Hard      HandKind = "hard"
+	Soft      HandKind = "soft"
+	Pair      HandKind = "pair"
+	Insurance HandKind = "insurance"
+)
+
+// Deviation is an index play, Action instead of the chart for Hand against
+// the dealer's Up card once the true count reaches Index, or while it is
+// below Index when Below. Total is the hard or soft total, or the paired card
+// for a pair, and Up counts an ace as 1.
+type Deviation struct {
+	Hand   HandKind `yaml:"hand"`
+	Total  int      `yaml:"total"`
+	Up     int      `yaml:"up"`
+	Index  float64  `yaml:"index"`
+	Below  bool     `yaml:"below"`
+	Action string   `yaml:"action"`
+}
+
+// Set is a list of deviations, played on top of a basic strategy chart.
+type Set []Deviation
+
+// Illustrious18 are the 18 Hi-Lo index plays worth the most, for a multi-deck
+// shoe where the dealer stands on soft 17.
+var Illustrious18 = Set{
+	{Hand: Insurance, Up: 1, Index: 3, Action: "i"},
+	{Hand: Hard, Total: 16, Up: 10, Index: 0, Action: "s"},
+	{Hand: Hard, Total: 15, Up: 10, Index: 4, Action: "s"},
+	{Hand: Pair, Total: 10, Up: 5, Index: 5, Action: "p"},
+	{Hand: Pair, Total: 10, Up: 6, Index: 4, Action: "p"},
+	{Hand: Hard, Total: 10, Up: 10, Index: 4, Action: "d"},
+	{Hand: Hard, Total: 12, Up: 3, Index: 2, Action: "s"},
+	{Hand: Hard, Total: 12, Up: 2, Index: 3, Action: "s"},
+	{Hand: Hard, Total: 11, Up: 1, Index: 1, Action: "d"},
+	{Hand: Hard, Total: 9, Up: 2, Index: 1, Action: "d"},
+	{Hand: Hard, Total: 10, Up: 1, Index: 4, Action: "d"},
+	{Hand: Hard, Total: 9, Up: 7, Index: 3, Action: "d"},
+	{Hand: Hard, Total: 16, Up: 9, Index: 5, Action: "s"},
+	{Hand: Hard, Total: 13, Up: 2, Index: -1, Below: true, Action: "h"},
+	{Hand: Hard, Total: 12, Up: 4, Index: 0, Below: true, Action: "h"},
+	{Hand: Hard, Total: 12, Up: 5, Index: -2, Below: true, Action: "h"},
+	{Hand: Hard, Total: 12, Up: 6, Index: -1, Below: true, Action: "h"},
+	{Hand: Hard, Total: 13, Up: 3, Index: -2, Below: true, Action: "h"},
+}
+
+// Fab4 are the four Hi-Lo surrender index plays worth the most.
+var Fab4 = Set{
+	{Hand: Hard, Total: 14, Up: 10, Index: 3, Action: "u"},
+	{Hand: Hard, Total: 15, Up: 10, Index: 0, Action: "u"},
+	{Hand: Hard, Total: 15, Up: 9, Index: 2, Action: "u"},
+	{Hand: Hard, Total: 15, Up: 1, Index: 1, Action: "u"},
+}
+
+// Sets are the built in deviations by the name the -deviations flag takes.
+var Sets = map[string]Set{
+	"i18":  Illustrious18,
+	"fab4": Fab4,
+}
+
+// SetNames lists the names of Sets in order.
+func SetNames() []string {
+	names := make([]string, 0, len(Sets))
+	for name := range Sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var cache = make(map[string]Set)
+var cacheMutex sync.Mutex
+
+// Load returns the deviations spec names, a comma separated list of built in
+// sets and YAML files of deviations. An empty spec or "none" plays the chart
+// as it is.
+func Load(spec string) (Set, error) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if set, ok := cache[spec]; ok {
+		return set, nil
+	}
+
+	set := Set{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ToLower(name) == "none" {
+			continue
+		}
+
+		if builtIn, ok := Sets[strings.ToLower(name)]; ok {
+			set = append(set, builtIn...)
+			continue
+		}
+
+		loaded, err := loadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, loaded...)
+	}
+
+	cache[spec] = set
+
+	return set, nil
+}
+
+func loadFile(path string) (Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("deviations %q should be one of %s or a YAML file: %w", path, strings.Join(SetNames(), ", "), err)
+	}
+
+	set := Set{}
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("reading deviations %q: %w", path, err)
+	}
+
+	for i, deviation := range set {
+		if err := validate(deviation); err != nil {
+			return nil, fmt.Errorf("deviation %d in %q: %w", i+1, path, err)
+		}
+	}
+
+	return set, nil
+}
+
+func validate(deviation Deviation) error {
+	if deviation.Up < 1 || deviation.Up > 10 {
+		return fmt.Errorf("up %d should be 1 (an ace) to 10", deviation.Up)
+	}
+
+	switch deviation.Hand {
+	case Hard, Soft:
+		if deviation.Total < 4 || deviation.Total > 21 {
+			return fmt.Errorf("total %d should be 4 to 21", deviation.Total)
+		}
+		if !strings.Contains("hsdu", deviation.Action) || len(deviation.Action) != 1 {
+			return fmt.Errorf("action %q should be h, s, d or u", deviation.Action)
+		}
+	case Pair:
+		if deviation.Total < 1 || deviation.Total > 10 {
+			return fmt.Errorf("pair %d should be 1 (aces) to 10", deviation.Total)
+		}
+		if !strings.Contains("hsdpu", deviation.Action) || len(deviation.Action) != 1 {
+			return fmt.Errorf("action %q should be h, s, d, p or u", deviation.Action)
+		}
+	case Insurance:
+		if deviation.Action != "i" && deviation.Action != "n" {
+			return fmt.Errorf("action %q should be i or n", deviation.Action)
+		}
+	default:
+		return fmt.Errorf("hand %q should be hard, soft, pair or insurance", deviation.Hand)
+	}
+
+	return nil
+}
+
+// Applies reports whether deviation is in play at trueCount.
+func Applies(deviation Deviation, trueCount float64) bool {
+	if deviation.Below {
+		return trueCount < deviation.Index
+	}
+	return trueCount >= deviation.Index
+}
+
+// Insure answers the insurance, or even money, question, taking it when a
+// deviation in set says to at trueCount. Basic strategy never insures.
+func Insure(set Set, trueCount float64) rune {
+	for _, deviation := range set {
+		if deviation.Hand == Insurance && Applies(deviation, trueCount) {
+			return rune(deviation.Action[0])
+		}
+	}
+	return 'n'
+}
+
+// GetAutoPlayPlayerAction plays activeHand by strategyTable as
+// rules.GetAutoPlayPlayerAction does, unless a deviation in set is in play at
+// the table's true count and the hand can make its play. Surrenders are looked
+// at first, since surrendering is decided before anything else.
+func GetAutoPlayPlayerAction(t *game.Table, activeHand *player.Hand, dealerFaceUpCard int, tableRules rules.TableRules, strategyTable *rules.StrategyTable, set Set) (rune, error) {
+	if activeHand == nil || !activeHand.Active || strategyTable == nil || len(set) == 0 {
+		return rules.GetAutoPlayPlayerAction(t, activeHand, dealerFaceUpCard, tableRules, strategyTable)
+	}
+
+	trueCount := game.TrueCount(t)
+	canSplit := rules.CanSplit(t, *activeHand, tableRules)
+	chartAction := rules.StrategyDecision(strategyTable, activeHand, dealerFaceUpCard, canSplit).Action
+	if chartAction == 'u' && rules.CanSurrender(t, activeHand, tableRules) {
+		// surrendering is decided first, before any other index play
+		return 'u', nil
+	}
+	// a pair the chart splits is only played differently by a pair deviation
+	chartSplits := chartAction == 'p'
+
+	for _, surrenders := range []bool{true, false} {
+		for _, deviation := range set {
+			if (deviation.Action == "u") != surrenders || !Applies(deviation, trueCount) || !matches(deviation, activeHand, dealerFaceUpCard, canSplit) {
+				continue
+			}
+			if chartSplits && deviation.Hand != Pair {
+				continue
+			}
+
+			if action := rune(deviation.Action[0]); canMake(t, activeHand, action, tableRules) {
+				return action, nil
+			}
+		}
+	}
+
+	return rules.GetAutoPlayPlayerAction(t, activeHand, dealerFaceUpCard, tableRules, strategyTable)
+}
+
+// matches reports whether deviation is for hand against dealerFaceUpCard,
+// looking a pair up as a pair only when it can be split.
+func matches(deviation Deviation, hand *player.Hand, dealerFaceUpCard int, canSplit bool) bool {
+	if deviation.Up != dealerFaceUpCard {
+		return false
+	}
+
+	total := 0
+	hasAce := false
+	for i := 0; i < len(hand.Cards); i++ {
+		total += cards.CardToValue(hand.Cards[i], true)
+		hasAce = hasAce || cards.IsAce(hand.Cards[i])
+	}
+
+	isPair := len(hand.Cards) == 2 && cards.CardToValue(hand.Cards[0], true) == cards.CardToValue(hand.Cards[1], true)
+
+	switch deviation.Hand {
+	case Pair:
+		return canSplit && isPair && deviation.Total == cards.CardToValue(hand.Cards[0], true)
+	case Soft:
+		return hasAce && total+10 <= 21 && deviation.Total == total+10
+	case Hard:
+		return !(hasAce && total+10 <= 21) && deviation.Total == total
+	}
+
+	return false
+}
+
+func canMake(t *game.Table, hand *player.Hand, action rune, tableRules rules.TableRules) bool {
+	switch action {
+	case 'd':
+		return rules.CanDoubleDown(t, hand, tableRules)
+	case 'h':
+		return rules.CanHit(t, hand, tableRules)
+	case 'p':
+		return rules.CanSplit(t, *hand, tableRules)
+	case 'u':
+		return rules.CanSurrender(t, hand, tableRules)
+	case 's':
+		return !rules.MustTwist(t, *hand, tableRules)
+	}
+
+	return true
+}
@@ -0,0 +1,123 @@
+package deviations
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupTable deals the dealer up, with a masked hole card, from a six deck
+// shoe with count as the running count.
+func setupTable(up string, count float64) *game.Table {
+	table := game.NewTable(game.Blackjack, 1)
+	game.CreateShoe(table, 6)
+	table.State.Count = count
+
+	table.State.Dealer = player.Player{Dealer: true}
+	dealerHand := player.ToHand([]string{up, "♥9"})
+	dealerHand.Cards[1].Masked = true
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	return table
+}
+
+func setupHand(cardPlayer *player.Player, cardStrings ...string) *player.Hand {
+	hand := player.ToHand(cardStrings)
+	hand.Active = true
+	hand.Player = cardPlayer
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+	return &cardPlayer.Hands[0]
+}
+
+func TestGetAutoPlayPlayerAction(t *testing.T) {
+	strategyTable := &rules.StrategyTable{}
+	strategyTable.Hard[16][10] = rules.Decision{Action: 'h', Fallback: 'h'}
+	strategyTable.Hard[15][10] = rules.Decision{Action: 'h', Fallback: 'h'}
+	strategyTable.Pairs[8][10] = rules.Decision{Action: 'p', Fallback: 'h'}
+	strategyTable.Hard[16][9] = rules.Decision{Action: 'u', Fallback: 'h'}
+	tableRules := rules.DefaultTableRules()
+	tableRules.Surrender = rules.LateSurrender
+
+	tests := []struct {
+		name  string
+		cards []string
+		count float64
+		want  rune
+	}{
+		{"16 v 10 below the index hits", []string{"♠10", "♥6"}, -6, 'h'},
+		{"16 v 10 at the index stands", []string{"♠10", "♥6"}, 0, 's'},
+		{"three card 16 v 10 stands too", []string{"♠4", "♥5", "♣7"}, 6, 's'},
+		{"8,8 v 10 is still split", []string{"♠8", "♥8"}, 6, 'p'},
+		{"15 v 10 surrenders before standing", []string{"♠10", "♥5"}, 30, 'u'},
+		{"three card 15 v 10 cannot surrender", []string{"♠4", "♥5", "♣6"}, 30, 's'},
+	}
+
+	for _, test := range tests {
+		table := setupTable("♠K", test.count)
+		cardPlayer := player.Player{Stack: 100}
+		hand := setupHand(&cardPlayer, test.cards...)
+
+		action, err := GetAutoPlayPlayerAction(table, hand, 10, tableRules, strategyTable, append(Illustrious18, Fab4...))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if action != test.want {
+			t.Errorf("%s: got %c want %c", test.name, action, test.want)
+		}
+	}
+
+	// the chart's surrender comes before 16 v 9's stand at +5
+	table := setupTable("♠9", 60)
+	cardPlayer := player.Player{Stack: 100}
+	hand := setupHand(&cardPlayer, "♠10", "♥6")
+	if action, _ := GetAutoPlayPlayerAction(table, hand, 9, tableRules, strategyTable, Illustrious18); action != 'u' {
+		t.Errorf("16 v 9 the chart surrenders: got %c want u", action)
+	}
+}
+
+func TestInsure(t *testing.T) {
+	if Insure(Illustrious18, 2.9) != 'n' {
+		t.Fatalf("should not insure below +3")
+	}
+	if Insure(Illustrious18, 3) != 'i' {
+		t.Fatalf("should insure at +3")
+	}
+	if Insure(nil, 10) != 'n' {
+		t.Fatalf("basic strategy should never insure")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "indices.yaml")
+	contents := "- {hand: soft, total: 19, up: 6, index: 1, action: d}\n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := Load("fab4, " + path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(set) != len(Fab4)+1 {
+		t.Fatalf("got %d deviations want %d", len(set), len(Fab4)+1)
+	}
+	if last := set[len(set)-1]; last.Hand != Soft || last.Total != 19 || last.Action != "d" {
+		t.Fatalf("file deviation read as %+v", last)
+	}
+
+	if set, err := Load("none"); err != nil || len(set) != 0 {
+		t.Fatalf("none should play the chart, got %v, %v", set, err)
+	}
+
+	if err := os.WriteFile(path, []byte("- {hand: hard, total: 16, up: 10, action: x}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("bad action should not load")
+	}
+}
@@ -2,23 +2,215 @@
 
 package flags
 
-import "syscall/js"
+import (
+	"blackjack/betting"
+	"blackjack/counting"
+	"blackjack/game"
+	"blackjack/rules"
+	"syscall/js"
+)
 
 // FromJS builds a Config from a JavaScript object when running in a WASM
 // environment. The object is expected to contain fields matching the command
 // line flag names.
 func FromJS(v js.Value) Config {
 	return Config{
-		NumOfDecks:       v.Get("decks").Int(),
-		NumOfPlayers:     v.Get("players").Int(),
-		MinWager:         v.Get("minimum").Int(),
-		UseGlyphs:        v.Get("glyph").Bool(),
-		DrawCards:        v.Get("draw").Bool(),
-		HouseStart:       v.Get("house").Int(),
-		PlayerStartStack: v.Get("stack").Int(),
-		TrifectaStax:     v.Get("trifectaStax").Bool(),
-		Autoplay:         v.Get("autoplay").Bool(),
-		Clean:            v.Get("clean").Bool(),
-		ColorTerminal:    v.Get("colorTerminal").Bool(),
+		NumOfDecks:           v.Get("decks").Int(),
+		Penetration:          penetrationFromJS(v),
+		Shuffler:             shufflerFromJS(v),
+		ShuffleRecipe:        shuffleRecipeFromJS(v),
+		StackedShoe:          stackedShoeFromJS(v),
+		Game:                 gameFromJS(v),
+		NumOfPlayers:         v.Get("players").Int(),
+		MinWager:             v.Get("minimum").Int(),
+		MaxWager:             intFromJS(v, "maximum"),
+		UseGlyphs:            v.Get("glyph").Bool(),
+		DrawCards:            v.Get("draw").Bool(),
+		HouseStart:           v.Get("house").Int(),
+		PlayerStartStack:     v.Get("stack").Int(),
+		TrifectaStax:         v.Get("trifectaStax").Bool(),
+		Sidebets:             sidebetsFromJS(v),
+		Autoplay:             v.Get("autoplay").Bool(),
+		Clean:                v.Get("clean").Bool(),
+		ColorTerminal:        v.Get("colorTerminal").Bool(),
+		Seed:                 seedFromJS(v),
+		Rounds:               roundsFromJS(v),
+		CompositionDependent: v.Get("cdStrategy").Bool(),
+		CountSystem:          countSystemFromJS(v),
+		Deviations:           deviationsFromJS(v),
+		Betting:              bettingFromJS(v),
+		BetSpread:            intFromJS(v, "spread"),
+		KellyFraction:        kellyFromJS(v),
+		Bankroll:             intFromJS(v, "bankroll"),
+		TableRules:           tableRulesFromJS(v),
 	}
 }
+
+// seedFromJS reads the optional seed, 0 when it is not given.
+func seedFromJS(v js.Value) int64 {
+	if seed := v.Get("seed"); !seed.IsUndefined() {
+		return int64(seed.Float())
+	}
+	return 0
+}
+
+// gameFromJS reads the optional game, the default when it is not given or
+// not known.
+func gameFromJS(v js.Value) game.Game {
+	if name := v.Get("game"); name.Type() == js.TypeString {
+		if mode, err := game.ParseGame(name.String()); err == nil {
+			return mode
+		}
+	}
+	return game.DefaultGameMode
+}
+
+// penetrationFromJS reads the optional penetration, a rolled cut when it is
+// not given or not understood.
+func penetrationFromJS(v js.Value) game.Penetration {
+	if penetration := v.Get("penetration"); penetration.Type() == js.TypeString {
+		if parsed, err := game.ParsePenetration(penetration.String()); err == nil {
+			return parsed
+		}
+	}
+	return game.Penetration{}
+}
+
+// shufflerFromJS reads the optional shuffler, a hand shuffle when it is not
+// given or not known.
+func shufflerFromJS(v js.Value) game.Shuffler {
+	if name := v.Get("shuffler"); name.Type() == js.TypeString {
+		if shuffler, err := game.ParseShuffler(name.String()); err == nil {
+			return shuffler
+		}
+	}
+	return game.HandShuffle
+}
+
+// shuffleRecipeFromJS reads the optional shuffle recipe, a perfectly random
+// shuffle when it is not given or not understood.
+func shuffleRecipeFromJS(v js.Value) game.ShuffleRecipe {
+	if recipe := v.Get("shuffle"); recipe.Type() == js.TypeString {
+		if parsed, err := game.ParseShuffleRecipe(recipe.String()); err == nil {
+			return parsed
+		}
+	}
+	return nil
+}
+
+// roundsFromJS reads the optional number of autoplay rounds, 0 when it is not
+// given.
+func roundsFromJS(v js.Value) int {
+	if rounds := v.Get("rounds"); !rounds.IsUndefined() {
+		return rounds.Int()
+	}
+	return 0
+}
+
+// countSystemFromJS reads the optional counting system, the default when it
+// is not given.
+func countSystemFromJS(v js.Value) string {
+	if system := v.Get("count"); system.Type() == js.TypeString {
+		return system.String()
+	}
+	return counting.DefaultSystem
+}
+
+// deviationsFromJS reads the optional built in deviations, none when they are
+// not given.
+func deviationsFromJS(v js.Value) string {
+	if spec := v.Get("deviations"); spec.Type() == js.TypeString {
+		return spec.String()
+	}
+	return ""
+}
+
+// sidebetsFromJS reads the optional sidebets, the game's own when they are
+// not given.
+func sidebetsFromJS(v js.Value) string {
+	if spec := v.Get("sidebets"); spec.Type() == js.TypeString {
+		return spec.String()
+	}
+	return ""
+}
+
+// stackedShoeFromJS reads the optional stacked cards, none when they are not
+// given.
+func stackedShoeFromJS(v js.Value) string {
+	if spec := v.Get("stackedShoe"); spec.Type() == js.TypeString {
+		return spec.String()
+	}
+	return ""
+}
+
+// bettingFromJS reads the optional betting strategies, the default when they
+// are not given.
+func bettingFromJS(v js.Value) string {
+	if spec := v.Get("betting"); spec.Type() == js.TypeString {
+		return spec.String()
+	}
+	return betting.DefaultStrategy
+}
+
+// kellyFromJS reads the optional Kelly fraction, 0 when it is not given.
+func kellyFromJS(v js.Value) float64 {
+	if fraction := v.Get("kelly"); !fraction.IsUndefined() {
+		return fraction.Float()
+	}
+	return 0
+}
+
+// intFromJS reads an optional whole number, 0 when it is not given.
+func intFromJS(v js.Value, name string) int {
+	if value := v.Get(name); !value.IsUndefined() {
+		return value.Int()
+	}
+	return 0
+}
+
+// tableRulesFromJS starts from the default table rules and overrides any rule
+// the JavaScript object specifies.
+func tableRulesFromJS(v js.Value) rules.TableRules {
+	tableRules := rules.DefaultTableRules()
+
+	if h17 := v.Get("h17"); !h17.IsUndefined() {
+		tableRules.DealerHitsSoft17 = h17.Bool()
+	}
+	if das := v.Get("das"); !das.IsUndefined() {
+		tableRules.DoubleAfterSplit = das.Bool()
+	}
+	if double9to11 := v.Get("double9to11"); !double9to11.IsUndefined() {
+		tableRules.DoubleNineToEleven = double9to11.Bool()
+	}
+	if maxSplitHands := v.Get("maxSplitHands"); !maxSplitHands.IsUndefined() {
+		tableRules.MaxSplitHands = maxSplitHands.Int()
+	}
+	if resplitAces := v.Get("resplitAces"); !resplitAces.IsUndefined() {
+		tableRules.ResplitAces = resplitAces.Bool()
+	}
+	if hitSplitAces := v.Get("hitSplitAces"); !hitSplitAces.IsUndefined() {
+		tableRules.HitSplitAces = hitSplitAces.Bool()
+	}
+	if blackjackPays := v.Get("blackjackPays"); !blackjackPays.IsUndefined() {
+		if payout, err := rules.ParsePayout(blackjackPays.String()); err == nil {
+			tableRules.BlackjackPayout = payout
+		}
+	}
+	if insurance := v.Get("insurance"); !insurance.IsUndefined() {
+		tableRules.InsuranceAllowed = insurance.Bool()
+	}
+
+	if surrender := v.Get("surrender"); !surrender.IsUndefined() {
+		if parsed, err := rules.ParseSurrender(surrender.String()); err == nil {
+			tableRules.Surrender = parsed
+		}
+	}
+	if enhc := v.Get("enhc"); !enhc.IsUndefined() {
+		tableRules.NoHoleCard = enhc.Bool()
+	}
+	if obo := v.Get("obo"); !obo.IsUndefined() {
+		tableRules.OriginalBetsOnly = obo.Bool()
+	}
+
+	return tableRules
+}
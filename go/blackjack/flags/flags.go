@@ -1,35 +1,99 @@
 package flags
 
-import "flag"
+import (
+	"blackjack/betting"
+	"blackjack/counting"
+	"blackjack/deviations"
+	"blackjack/game"
+	"blackjack/rules"
+	"blackjack/sidebets"
+	"flag"
+	"runtime"
+	"strings"
+)
 
 // the following are flags
 var NumOfDecks = flag.Int("decks", 5, "the number of decks in the shoe")
+var Penetration = penetrationFlag("penetration", "how much of the shoe is dealt before it is shuffled, a percentage such as 75% or a number of decks such as 4.5 (default a rolled cut)")
+var Shuffler = shufflerFlag("shuffler", game.HandShuffle, "how the shoe is shuffled: hand, csm (continuous, after every round) or auto (only the played cards, at the cut card)")
+var ShuffleRecipe = shuffleRecipeFlag("shuffle", "how the dealer shuffles by hand: "+strings.Join(game.ShuffleRecipeNames(), ", ")+" or steps such as riffle,strip,riffle,cut, from riffle, strip, box, wash and cut (default perfect)")
+var StackedShoe = flag.String("stackedShoe", "", "cards dealt first from a new shoe, such as \"A♠ 10h 8 8 random\", or a file listing them; the shoe's own cards follow, or random cards after a last word random")
+var GameMode = gameFlag("game", game.DefaultGameMode, "the game dealt: "+strings.Join(game.GameNames(), ", "))
 var NumOfPlayers = flag.Int("players", 1, "the number of players in the game")
 var MinWager = flag.Int("minimum", 25, "the minimum bet")
+var MaxWager = flag.Int("maximum", 0, "the maximum bet, 0 for no maximum")
 var UseGlyphs = flag.Bool("glyph", false, "use UTF-8 glyphs, overrules \"draw\" flag")
 var DrawCards = flag.Bool("draw", true, "use rudimentary drawing")
 var HouseStart = flag.Int("house", 0, "override the house's initial starting winnings")
 var PlayerStartStack = flag.Int("stack", 0, "set the starting stack for ALL players")
-var TrifectaStax = flag.Bool("trifectaStax", true, "turn on/off Trifecta Stax payouts")
-var Autoplay = flag.Bool("autoplay", false, "turn on/off (will play 500 rounds, will not play trifecta)")
+var TrifectaStax = flag.Bool("trifectaStax", true, "turn on/off sidebets")
+var Sidebets = sidebetsFlag("sidebets", "", "comma separated sidebets each player plays: "+strings.Join(sidebets.SidebetNames(), ", ")+" or none (default the game's own)")
+var Autoplay = flag.Bool("autoplay", false, "turn on/off (will play -rounds rounds, will not play trifecta)")
+var Rounds = flag.Int("rounds", 500, "the number of rounds autoplay plays, or sim simulates (1000000 unless set), 0 for no limit")
+var Tables = flag.Int("tables", runtime.NumCPU(), "the number of tables sim plays in parallel")
+var CountSystem = countSystemFlag("count", counting.DefaultSystem, "the card counting system shown and played by: "+strings.Join(counting.SystemNames(), ", "))
+var Deviations = deviationsFlag("deviations", "", "index plays autoplay and hints make by the true count: "+strings.Join(deviations.SetNames(), ", ")+" and/or YAML files, comma separated")
+var Betting = bettingFlag("betting", betting.DefaultStrategy, "how autoplay players bet, one per player and comma separated: "+strings.Join(betting.StrategyNames(), ", "))
+var BetSpread = flag.Int("spread", 8, "the ramp betting strategy's top bet, in minimum bets")
+var KellyFraction = flag.Float64("kelly", .5, "the share of a full Kelly bet the kelly betting strategy makes")
+var Bankroll = flag.Int("bankroll", 0, "the bankroll the kelly betting strategy sizes bets from, 0 for the player's stack")
+var CompositionDependent = flag.Bool("cdStrategy", false, "autoplay and hints play two card hands by their cards, not just their total")
 var Clean = flag.Bool("clean", true, "whether or not to read initial state from State.out file")
 var ColorTerminal = flag.Bool("colorTerminal", true, "whether or not to try to use color codes for coloring the terminal")
+var HistoryPath = flag.String("history", "history.jsonl", "the file every round is logged to, empty to log nothing")
+var ProgressivesPath = flag.String("progressives", "progressives.yaml", "the file the progressive meters are kept in, empty to start them over every time")
+var MetersPath = flag.String("meters", "", "a YAML list of progressive meters to change the seed, reset or increment of, by name")
+var Seed = flag.Int64("seed", 0, "seed for shuffles and cuts so a session can be replayed, 0 picks one from the clock")
+
+// the following flags make up the table rules
+var DealerHitsSoft17 = flag.Bool("h17", false, "the dealer hits soft 17s")
+var DoubleAfterSplit = flag.Bool("das", true, "allow doubling down after a split")
+var DoubleNineToEleven = flag.Bool("double9to11", false, "only allow doubling down on 9, 10 or 11 (otherwise any two cards)")
+var MaxSplitHands = flag.Int("maxSplitHands", 4, "the most hands a player may split each dealt hand to, 0 for no limit")
+var ResplitAces = flag.Bool("resplitAces", false, "allow splitting aces again")
+var HitSplitAces = flag.Bool("hitSplitAces", false, "allow hitting split aces")
+var BlackjackPays = payoutFlag("blackjackPays", rules.ThreeToTwo, "what a blackjack pays, e.g. 3:2 or 6:5")
+var InsuranceAllowed = flag.Bool("insurance", true, "offer insurance and even money")
+var Surrender = surrenderFlag("surrender", rules.NoSurrender, "when a player may surrender: none, late or early")
+var NoHoleCard = flag.Bool("enhc", false, "European no hole card: the dealer's second card is dealt after the players act")
+var OriginalBetsOnly = flag.Bool("obo", true, "with -enhc, a dealer blackjack takes only the original bets, not doubles or splits")
 
 // Config holds runtime configuration for the application. Fields mirror the
 // command line flags so configuration can be passed around without relying on
 // the flag package.
 type Config struct {
-	NumOfDecks       int
-	NumOfPlayers     int
-	MinWager         int
-	UseGlyphs        bool
-	DrawCards        bool
-	HouseStart       int
-	PlayerStartStack int
-	TrifectaStax     bool
-	Autoplay         bool
-	Clean            bool
-	ColorTerminal    bool
+	NumOfDecks           int
+	Penetration          game.Penetration
+	Shuffler             game.Shuffler
+	ShuffleRecipe        game.ShuffleRecipe
+	StackedShoe          string
+	Game                 game.Game
+	NumOfPlayers         int
+	MinWager             int
+	MaxWager             int
+	UseGlyphs            bool
+	DrawCards            bool
+	HouseStart           int
+	PlayerStartStack     int
+	TrifectaStax         bool
+	Sidebets             string
+	Autoplay             bool
+	Clean                bool
+	ColorTerminal        bool
+	Seed                 int64
+	HistoryPath          string
+	ProgressivesPath     string
+	MetersPath           string
+	Rounds               int
+	Tables               int
+	CompositionDependent bool
+	CountSystem          string
+	Deviations           string
+	Betting              string
+	BetSpread            int
+	KellyFraction        float64
+	Bankroll             int
+	TableRules           rules.TableRules
 }
 
 // Cfg contains the active configuration. It should be populated by calling
@@ -39,16 +103,144 @@ var Cfg Config
 // FromFlags builds a Config from the parsed flag values.
 func FromFlags() Config {
 	return Config{
-		NumOfDecks:       *NumOfDecks,
-		NumOfPlayers:     *NumOfPlayers,
-		MinWager:         *MinWager,
-		UseGlyphs:        *UseGlyphs,
-		DrawCards:        *DrawCards,
-		HouseStart:       *HouseStart,
-		PlayerStartStack: *PlayerStartStack,
-		TrifectaStax:     *TrifectaStax,
-		Autoplay:         *Autoplay,
-		Clean:            *Clean,
-		ColorTerminal:    *ColorTerminal,
+		NumOfDecks:           *NumOfDecks,
+		Penetration:          *Penetration,
+		Shuffler:             *Shuffler,
+		ShuffleRecipe:        *ShuffleRecipe,
+		StackedShoe:          *StackedShoe,
+		Game:                 *GameMode,
+		NumOfPlayers:         *NumOfPlayers,
+		MinWager:             *MinWager,
+		MaxWager:             *MaxWager,
+		UseGlyphs:            *UseGlyphs,
+		DrawCards:            *DrawCards,
+		HouseStart:           *HouseStart,
+		PlayerStartStack:     *PlayerStartStack,
+		TrifectaStax:         *TrifectaStax,
+		Sidebets:             *Sidebets,
+		Autoplay:             *Autoplay,
+		Clean:                *Clean,
+		ColorTerminal:        *ColorTerminal,
+		Seed:                 *Seed,
+		HistoryPath:          *HistoryPath,
+		ProgressivesPath:     *ProgressivesPath,
+		MetersPath:           *MetersPath,
+		Rounds:               *Rounds,
+		Tables:               *Tables,
+		CompositionDependent: *CompositionDependent,
+		CountSystem:          *CountSystem,
+		Deviations:           *Deviations,
+		Betting:              *Betting,
+		BetSpread:            *BetSpread,
+		KellyFraction:        *KellyFraction,
+		Bankroll:             *Bankroll,
+		TableRules: rules.TableRules{
+			DealerHitsSoft17:   *DealerHitsSoft17,
+			DoubleAfterSplit:   *DoubleAfterSplit,
+			DoubleNineToEleven: *DoubleNineToEleven,
+			MaxSplitHands:      *MaxSplitHands,
+			ResplitAces:        *ResplitAces,
+			HitSplitAces:       *HitSplitAces,
+			BlackjackPayout:    *BlackjackPays,
+			InsuranceAllowed:   *InsuranceAllowed,
+			Surrender:          *Surrender,
+			NoHoleCard:         *NoHoleCard,
+			OriginalBetsOnly:   *OriginalBetsOnly,
+		},
 	}
 }
+
+// IsSet reports whether the named flag was given on the command line.
+func IsSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func payoutFlag(name string, value rules.Payout, usage string) *rules.Payout {
+	payout := value
+	flag.Var(&payout, name, usage)
+	return &payout
+}
+
+func gameFlag(name string, value game.Game, usage string) *game.Game {
+	mode := value
+	flag.Var(&mode, name, usage)
+	return &mode
+}
+
+func penetrationFlag(name string, usage string) *game.Penetration {
+	penetration := game.Penetration{}
+	flag.Var(&penetration, name, usage)
+	return &penetration
+}
+
+func shufflerFlag(name string, value game.Shuffler, usage string) *game.Shuffler {
+	shuffler := value
+	flag.Var(&shuffler, name, usage)
+	return &shuffler
+}
+
+func shuffleRecipeFlag(name string, usage string) *game.ShuffleRecipe {
+	recipe := game.ShuffleRecipe{}
+	flag.Var(&recipe, name, usage)
+	return &recipe
+}
+
+func surrenderFlag(name string, value rules.Surrender, usage string) *rules.Surrender {
+	surrender := value
+	flag.Var(&surrender, name, usage)
+	return &surrender
+}
+
+func countSystemFlag(name string, value string, usage string) *string {
+	system := value
+	flag.Func(name, usage+" (default "+value+")", func(systemString string) error {
+		if _, err := counting.ParseSystem(systemString); err != nil {
+			return err
+		}
+		system = strings.ToLower(strings.TrimSpace(systemString))
+		return nil
+	})
+	return &system
+}
+
+func bettingFlag(name string, value string, usage string) *string {
+	spec := value
+	flag.Func(name, usage+" (default "+value+")", func(specString string) error {
+		if err := betting.Parse(specString); err != nil {
+			return err
+		}
+		spec = specString
+		return nil
+	})
+	return &spec
+}
+
+func sidebetsFlag(name string, value string, usage string) *string {
+	spec := value
+	flag.Func(name, usage, func(specString string) error {
+		if _, err := sidebets.Parse(specString, game.DefaultGameMode); err != nil {
+			return err
+		}
+		spec = specString
+		return nil
+	})
+	return &spec
+}
+
+func deviationsFlag(name string, value string, usage string) *string {
+	spec := value
+	flag.Func(name, usage, func(specString string) error {
+		if _, err := deviations.Load(specString); err != nil {
+			return err
+		}
+		spec = specString
+		return nil
+	})
+	return &spec
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"blackjack/cards"
+)
+
+// CountCard adds a card the players have seen to the table's running count
+// and ace side count.
+func CountCard(t *Table, card cards.Card) {
+	if card.Masked {
+		return
+	}
+
+	t.State.Count += t.Counter.Tag(card)
+
+	if cards.IsAce(card) {
+		t.State.AcesSeen += 1
+	}
+}
+
+// ResetCount starts the count over for a freshly shuffled shoe.
+func ResetCount(t *Table) {
+	t.State.Count = t.Counter.InitialCount(len(t.State.Shoe.Decks))
+	t.State.AcesSeen = 0
+}
+
+// RemainingDecks is how many decks are left to be dealt from the shoe.
+func RemainingDecks(shoe Shoe) float64 {
+	if len(shoe.Decks) == 0 || len(shoe.Cards) == 0 {
+		return 0
+	}
+
+	cardsPerDeck := float64(len(shoe.Cards)) / float64(len(shoe.Decks))
+	return float64(len(shoe.Cards)-shoe.Index) / cardsPerDeck
+}
+
+// TrueCount is the running count per remaining deck. Less than half a deck
+// is treated as half a deck so the last few cards do not swing it wildly.
+func TrueCount(t *Table) float64 {
+	remainingDecks := RemainingDecks(t.State.Shoe)
+	if remainingDecks < .5 {
+		remainingDecks = .5
+	}
+
+	return t.State.Count / remainingDecks
+}
+
+// AceSurplus is how many more aces are left in the shoe than an even share of
+// them would leave, negative when aces have come out early.
+func AceSurplus(t *Table) float64 {
+	acesPerDeck := float64(len(cards.Suites))
+	acesLeft := acesPerDeck*float64(len(t.State.Shoe.Decks)) - float64(t.State.AcesSeen)
+
+	return acesLeft - acesPerDeck*RemainingDecks(t.State.Shoe)
+}
@@ -0,0 +1,43 @@
+package game
+
+import (
+	"blackjack/cards"
+	"blackjack/counting"
+	"testing"
+)
+
+func TestTrueCount(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 4)
+	ResetCount(table)
+
+	CountCard(table, cards.CreateCard(cards.Hearts, cards.Five))
+	CountCard(table, cards.CreateCard(cards.Spades, cards.Ace))
+	CountCard(table, cards.CreateCard(cards.Clubs, cards.Four))
+	if table.State.Count != 1 || table.State.AcesSeen != 1 {
+		t.Fatalf("Count=%g AcesSeen=%d want 1 and 1", table.State.Count, table.State.AcesSeen)
+	}
+
+	// two of the four decks have been dealt
+	table.State.Shoe.Index = 104
+	if trueCount := TrueCount(table); trueCount != .5 {
+		t.Fatalf("TrueCount=%g want 0.5", trueCount)
+	}
+	// 15 aces are left where an even share would leave 8
+	if surplus := AceSurplus(table); surplus != 7 {
+		t.Fatalf("AceSurplus=%g want 7", surplus)
+	}
+}
+
+func TestResetCountStartsUnbalancedCounts(t *testing.T) {
+	table := NewTable(Spanish21, 1)
+	table.Counter = counting.KO
+	CreateShoe(table, 6)
+	ResetCount(table)
+	if table.State.Count != -20 {
+		t.Fatalf("KO should start 6 decks at -20, got %g", table.State.Count)
+	}
+	if RemainingDecks(table.State.Shoe) != 6 {
+		t.Fatalf("RemainingDecks=%g want 6", RemainingDecks(table.State.Shoe))
+	}
+}
@@ -2,23 +2,32 @@ package game
 
 import (
 	"blackjack/cards"
+	"blackjack/counting"
+	"blackjack/history"
 	"blackjack/player"
+	"blackjack/progressive"
 	"blackjack/utils"
 
 	"errors"
 	"fmt"
+	"math/rand"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
 type BlackjackState struct {
-	Count            int                     `yaml:"count"`
+	Count            float64                 `yaml:"count"`
+	AcesSeen         int                     `yaml:"aces-seen"`
 	House            int                     `yaml:"house"`
 	Wins             int                     `yaml:"wins"`
 	Losses           int                     `yaml:"losses"`
 	Pushes           int                     `yaml:"pushes"`
+	Surrenders       int                     `yaml:"surrenders"`
 	DealerBlackjacks int                     `yaml:"dealer-blackjacks"`
 	DealerBusts      int                     `yaml:"dealer-busts"`
 	PlayerBlackjacks int                     `yaml:"player-blackjacks"`
@@ -31,6 +40,7 @@ type BlackjackState struct {
 	Players          []player.Player         `yaml:"players"`
 	Shoe             Shoe                    `yaml:"shoe"`
 	Rounds           int                     `yaml:"rounds"`
+	Seed             int64                   `yaml:"seed"`
 }
 
 type Deck struct {
@@ -38,17 +48,33 @@ type Deck struct {
 }
 
 type Shoe struct {
-	Decks []Deck       `yaml:"deck"`
-	Cards []cards.Card `yaml:"cards"`
-	Cut   int          `yaml:"cut"`
-	Index int          `yaml:"index"`
+	Decks       []Deck        `yaml:"deck"`
+	Cards       []cards.Card  `yaml:"cards"`
+	Cut         int           `yaml:"cut"`
+	Index       int           `yaml:"index"`
+	Penetration Penetration   `yaml:"penetration"` // where the cut card goes, rolled for when it is not set
+	Shuffler    Shuffler      `yaml:"shuffler"`
+	Recipe      ShuffleRecipe `yaml:"recipe"`   // how a hand shuffle is done, perfectly randomly when empty
+	Discards    []cards.Card  `yaml:"discards"` // the discard tray, in the order the cards went in
 }
 
-var State BlackjackState
+// Table owns everything one blackjack table needs, its shoe, dealer, players
+// and stats in State, its game mode, progressives and random source, so any
+// number of independent tables can run in one process.
+type Table struct {
+	State        BlackjackState
+	Mode         Game
+	Progressives *progressive.Bank
+	Rand         *rand.Rand
+	Counter      counting.Counter
+	StatePath    string // where State is saved, empty when it should not be saved
+	HistoryPath  string // where every round is logged, empty when it should not be
+	History      history.Round
+}
 
-// inMemoryState holds the serialized game state when running under WebAssembly
-// where a traditional filesystem is unavailable.
-var inMemoryState []byte
+// inMemoryState holds the serialized game state, keyed by state path, when
+// running under WebAssembly where a traditional filesystem is unavailable.
+var inMemoryState = make(map[string][]byte)
 
 type Game int8
 
@@ -59,32 +85,150 @@ const (
 	Trifecta3
 	TrifectaStaxx
 	Spanish21
+	FreeBet
+	Switch
+	DoubleExposure
+	Pontoon
+	SuperFun21
 )
 
-var GameMode Game = Spanish21
+const DefaultGameMode Game = Spanish21
+
+var gameNames = map[Game]string{
+	Blackjack:      "Blackjack",
+	JackAttack:     "JackAttack",
+	Trifecta:       "Trifecta",
+	Trifecta3:      "Trifecta3",
+	TrifectaStaxx:  "TrifectaStaxx",
+	Spanish21:      "Spanish21",
+	FreeBet:        "FreeBet",
+	Switch:         "BlackjackSwitch",
+	DoubleExposure: "DoubleExposure",
+	Pontoon:        "Pontoon",
+	SuperFun21:     "SuperFun21",
+}
+
+func (g Game) String() string {
+	if name, ok := gameNames[g]; ok {
+		return name
+	}
+	return fmt.Sprintf("Game(%d)", g)
+}
+
+// ParseGame reads a game by the name String gives it.
+func ParseGame(name string) (Game, error) {
+	for g, gameName := range gameNames {
+		if strings.EqualFold(gameName, strings.TrimSpace(name)) {
+			return g, nil
+		}
+	}
+	return 0, fmt.Errorf("no game called %q", name)
+}
+
+// Set lets a Game be used directly as a command line flag.
+func (g *Game) Set(name string) error {
+	mode, err := ParseGame(name)
+	if err != nil {
+		return err
+	}
+	*g = mode
+	return nil
+}
+
+// GameNames lists the names of every game in order.
+func GameNames() []string {
+	names := make([]string, 0, len(gameNames))
+	for _, name := range gameNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTable creates an empty table for the given game, seeding its random source
+// with seed, or from the clock when seed is 0.
+func NewTable(mode Game, seed int64) *Table {
+	t := &Table{
+		Mode:         mode,
+		Progressives: progressive.NewBank(),
+		Counter:      counting.HiLo,
+	}
+	SeedRand(t, seed)
+	ResetState(t)
+
+	return t
+}
+
+// ResetState clears the table's state, keeping only its seed.
+func ResetState(t *Table) {
+	seed := t.State.Seed
+	t.State = BlackjackState{
+		Wins:             0,
+		Losses:           0,
+		Pushes:           0,
+		DealerBlackjacks: 0,
+		DealerBusts:      0,
+		PlayerBlackjacks: 0,
+		PlayerBusts:      0,
+		BustCards:        []cards.Card{},
+		BustCounts:       make(map[cards.CardValue]int),
+		SidebetWinnings:  0,
+		SidebetLosings:   0,
+		Players:          make([]player.Player, 0),
+		Dealer:           player.Player{Dealer: true},
+		Rounds:           0,
+		Seed:             seed,
+	}
+
+	for _, value := range cards.CardValues {
+		t.State.BustCounts[value] = 0
+	}
+}
+
+// SeedRand reseeds the table's random source and returns the seed used,
+// picking one from the clock when seed is 0.
+func SeedRand(t *Table, seed int64) int64 {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	t.Rand = rand.New(rand.NewSource(seed))
+	t.State.Seed = seed
+
+	return seed
+}
 
-func LoadBlackjackStateYaml(clean bool) error {
+func LoadBlackjackStateYaml(t *Table, clean bool) error {
 	if clean {
 		return errors.New("clean state is required")
 	}
 
+	if t.StatePath == "" {
+		return errors.New("state is not saved for this table")
+	}
+
 	if runtime.GOOS == "js" {
-		if len(inMemoryState) == 0 {
+		if len(inMemoryState[t.StatePath]) == 0 {
 			return errors.New("state not available")
 		}
-		return yaml.UnmarshalStrict(inMemoryState, &State)
+		return yaml.UnmarshalStrict(inMemoryState[t.StatePath], &t.State)
 	}
 
-	bytes, err := os.ReadFile("state.out")
+	bytes, err := os.ReadFile(t.StatePath)
 	if err != nil {
 		return err
 	}
 
-	return yaml.UnmarshalStrict(bytes, &State)
+	return yaml.UnmarshalStrict(bytes, &t.State)
 }
 
-func SaveBlackjackStateYaml() {
-	yamlData, err := yaml.Marshal(&State)
+func SaveBlackjackStateYaml(t *Table) {
+	if t.StatePath == "" {
+		// this table is not persisted, e.g. a simulation or a test
+		return
+	}
+
+	yamlData, err := yaml.Marshal(&t.State)
 
 	if err != nil {
 		fmt.Printf("Error while Marshaling. %v", err)
@@ -92,84 +236,78 @@ func SaveBlackjackStateYaml() {
 
 	if runtime.GOOS == "js" {
 		// Store state in memory when running in WebAssembly.
-		inMemoryState = yamlData
+		inMemoryState[t.StatePath] = yamlData
 		return
 	}
 
-	if err = os.WriteFile("state.out", yamlData, 0644); err != nil {
+	if err = os.WriteFile(t.StatePath, yamlData, 0644); err != nil {
 		panic("Unable to write data into the file")
 	}
 }
 
-func CreateShoe(numOfDecks int) {
-	State.Shoe = Shoe{
+func CreateShoe(t *Table, numOfDecks int) {
+	t.State.Shoe = Shoe{
 		Cards: make([]cards.Card, 0),
 		Decks: make([]Deck, 0),
 		Index: 0,
 	}
 
-	if numOfDecks <= 0 {
-		numOfDecks = 1
-	}
-
+	numOfDecks = DecksFor(t.Mode, numOfDecks)
 	for i := 0; i < numOfDecks; i++ {
-		deck := CreateDeck()
+		deck := CreateDeck(t)
 
-		State.Shoe.Decks = append(State.Shoe.Decks, deck)
-		State.Shoe.Cards = append(State.Shoe.Cards, deck.Cards...)
+		t.State.Shoe.Decks = append(t.State.Shoe.Decks, deck)
+		t.State.Shoe.Cards = append(t.State.Shoe.Cards, deck.Cards...)
 	}
 
-	cards.ShuffleCards(State.Shoe.Cards)
-}
-
-func CutShoe() {
-	die1 := utils.RollDice()
-	die2 := utils.RollDice()
-
-	if die1+die2 > int(float32(.8)*float32(len(utils.Die)*2)) {
-		// this still not right
-		CutShoe()
-	} else {
-		State.Shoe.Cut = int(len(State.Shoe.Cards) * (die1 + die2) / (2 * utils.Die[len(utils.Die)-1]))
-	}
+	cards.ShuffleCards(t.Rand, t.State.Shoe.Cards)
 }
 
-func CreateDeck() Deck {
+func CreateDeck(t *Table) Deck {
 	deck := Deck{}
 	deck.Cards = make([]cards.Card, 0)
 
 	for _, suite := range cards.Suites {
 		for _, value := range cards.CardValues {
-			if value != cards.One {
-				// it's not a One until it is Demoted, adding these to the deck would duplicate Aces
-				switch GameMode {
-				case Spanish21:
-					if value != cards.Ten {
-						// there are no 10s in Spanish21
-						deck.Cards = append(deck.Cards, cards.CreateCard(suite, value))
-					}
-				default:
-					deck.Cards = append(deck.Cards, cards.CreateCard(suite, value))
-				}
+			if InDeck(t.Mode, value) {
+				deck.Cards = append(deck.Cards, cards.CreateCard(suite, value))
 			}
 		}
 	}
 
-	cards.ShuffleCards(deck.Cards)
+	cards.ShuffleCards(t.Rand, deck.Cards)
 
 	return deck
 }
 
-func burnCard() {
-	State.Shoe.Index = utils.Min(State.Shoe.Index+1, len(State.Shoe.Cards)-1)
+// DecksFor is how many decks mode is dealt from when asked for decks: always
+// one for Super Fun 21, and at least one for every other game.
+func DecksFor(mode Game, decks int) int {
+	if mode == SuperFun21 || decks <= 0 {
+		return 1
+	}
+	return decks
+}
+
+// InDeck reports whether a deck for mode has cards of value.
+func InDeck(mode Game, value cards.CardValue) bool {
+	if value == cards.One {
+		// it's not a One until it is Demoted, adding these to the deck would duplicate Aces
+		return false
+	}
+
+	if mode == Spanish21 && value == cards.Ten {
+		// there are no 10s in Spanish21
+		return false
+	}
+
+	return true
 }
 
-func ShuffleShoeIfNeeded() {
-	if State.Shoe.Cut <= State.Shoe.Index {
-		cards.ShuffleCards(State.Shoe.Cards)
-		CutShoe()
-		State.Shoe.Index = 0
-		burnCard()
-		State.Count = 0
+// BurnCard puts the next card in the shoe on the discard tray unseen.
+func BurnCard(t *Table) {
+	if t.State.Shoe.Index < len(t.State.Shoe.Cards)-1 {
+		Discard(t, t.State.Shoe.Cards[t.State.Shoe.Index])
 	}
+	t.State.Shoe.Index = utils.Min(t.State.Shoe.Index+1, len(t.State.Shoe.Cards)-1)
 }
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeedRandReplaysShoe(t *testing.T) {
+	table := NewTable(Blackjack, 42)
+	CreateShoe(table, 2)
+	CutShoe(table)
+	first := table.State.Shoe
+
+	replayed := NewTable(Blackjack, 42)
+	CreateShoe(replayed, 2)
+	CutShoe(replayed)
+	if !reflect.DeepEqual(first, replayed.State.Shoe) {
+		t.Fatalf("same seed should build the same shoe")
+	}
+
+	other := NewTable(Blackjack, 43)
+	CreateShoe(other, 2)
+	if reflect.DeepEqual(first.Cards, other.State.Shoe.Cards) {
+		t.Fatalf("different seeds should not build the same shoe")
+	}
+}
+
+func TestSeedRandPicksSeed(t *testing.T) {
+	table := NewTable(Blackjack, 0)
+	if table.State.Seed == 0 {
+		t.Fatalf("expected a seed to be picked from the clock")
+	}
+	if seed := SeedRand(table, 7); seed != 7 || table.State.Seed != 7 {
+		t.Fatalf("expected seed 7 got %d", seed)
+	}
+}
+
+func TestTablesAreIndependent(t *testing.T) {
+	first := NewTable(Blackjack, 1)
+	second := NewTable(Spanish21, 1)
+	CreateShoe(first, 1)
+	CreateShoe(second, 1)
+
+	if len(first.State.Shoe.Cards) != 52 || len(second.State.Shoe.Cards) != 48 {
+		t.Fatalf("unexpected shoe sizes %d and %d", len(first.State.Shoe.Cards), len(second.State.Shoe.Cards))
+	}
+
+	first.State.Wins = 3
+	if second.State.Wins != 0 {
+		t.Fatalf("tables should not share state")
+	}
+}
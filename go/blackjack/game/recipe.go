@@ -0,0 +1,195 @@
+package game
+
+import (
+	"blackjack/cards"
+	"blackjack/utils"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// ShuffleStep is one thing a dealer does to the cards when shuffling by hand.
+type ShuffleStep int8
+
+const (
+	RiffleStep ShuffleStep = iota // riffle a grab from each half of the stack together, a deck at a time
+	StripStep                     // strip each deck's worth into a handful of packets
+	BoxStep                       // cut the whole stack into four and restack them the other way up
+	WashStep                      // spread the cards over the table and push them around
+	CutStep                       // have the player cut the stack
+)
+
+// stripPackets is about how many packets a deck is stripped into.
+const stripPackets = 6
+
+// boxPackets is how many packets a box cuts the stack into.
+const boxPackets = 4
+
+var ShuffleStepToString = map[ShuffleStep]string{
+	RiffleStep: "riffle",
+	StripStep:  "strip",
+	BoxStep:    "box",
+	WashStep:   "wash",
+	CutStep:    "cut",
+}
+
+func ParseShuffleStep(stepString string) (ShuffleStep, error) {
+	for step, name := range ShuffleStepToString {
+		if strings.EqualFold(name, strings.TrimSpace(stepString)) {
+			return step, nil
+		}
+	}
+	return RiffleStep, fmt.Errorf("shuffle step %q should be riffle, strip, box, wash or cut", stepString)
+}
+
+// ShuffleRecipe is the steps a dealer shuffles the shoe by, in order. The
+// empty recipe is a perfectly random shuffle.
+type ShuffleRecipe []ShuffleStep
+
+// ShuffleRecipes are recipes casinos shuffle by, by name.
+var ShuffleRecipes = map[string]ShuffleRecipe{
+	"perfect":  nil,
+	"quick":    {RiffleStep, RiffleStep, CutStep},
+	"standard": {RiffleStep, RiffleStep, StripStep, RiffleStep, CutStep},
+	"box":      {RiffleStep, BoxStep, RiffleStep, StripStep, RiffleStep, CutStep},
+	"wash":     {WashStep, RiffleStep, StripStep, RiffleStep, CutStep},
+}
+
+func ShuffleRecipeNames() []string {
+	names := make([]string, 0, len(ShuffleRecipes))
+	for name := range ShuffleRecipes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseShuffleRecipe reads a recipe by its name in ShuffleRecipes, or as
+// its steps separated by commas, such as riffle,strip,riffle,cut.
+func ParseShuffleRecipe(recipeString string) (ShuffleRecipe, error) {
+	recipeString = strings.ToLower(strings.TrimSpace(recipeString))
+	if recipeString == "" {
+		return nil, nil
+	}
+	if recipe, ok := ShuffleRecipes[recipeString]; ok {
+		return recipe, nil
+	}
+
+	recipe := ShuffleRecipe{}
+	for _, stepString := range strings.Split(recipeString, ",") {
+		step, err := ParseShuffleStep(stepString)
+		if err != nil {
+			return nil, fmt.Errorf("%w, or the recipe one of %s", err, strings.Join(ShuffleRecipeNames(), ", "))
+		}
+		recipe = append(recipe, step)
+	}
+	return recipe, nil
+}
+
+func (r *ShuffleRecipe) Set(recipeString string) error {
+	recipe, err := ParseShuffleRecipe(recipeString)
+	if err != nil {
+		return err
+	}
+	*r = recipe
+	return nil
+}
+
+func (r *ShuffleRecipe) String() string {
+	if r == nil {
+		return ""
+	}
+	steps := make([]string, len(*r))
+	for i, step := range *r {
+		steps[i] = ShuffleStepToString[step]
+	}
+	return strings.Join(steps, ",")
+}
+
+// shuffle puts stack through every step of the recipe, grabbing deck cards
+// at a time where a dealer's hands could not hold the whole stack.
+func (r ShuffleRecipe) shuffle(rng *rand.Rand, stack []cards.Card, deck int) []cards.Card {
+	if deck <= 0 {
+		deck = len(stack)
+	}
+
+	for _, step := range r {
+		switch step {
+		case RiffleStep:
+			stack = riffleInGrabs(rng, stack, deck)
+		case StripStep:
+			stack = inGrabs(stack, deck, func(grab []cards.Card) []cards.Card {
+				return cards.Strip(rng, grab, stripPackets)
+			})
+		case BoxStep:
+			stack = cards.Strip(rng, stack, boxPackets)
+		case WashStep:
+			stack = cards.Wash(rng, stack)
+		case CutStep:
+			stack = cards.Cut(rng, stack)
+		}
+	}
+	return stack
+}
+
+// riffleInGrabs splits stack in two and riffles half a deck from the top of
+// each half together, then the next, so a card stays in its part of the shoe.
+func riffleInGrabs(rng *rand.Rand, stack []cards.Card, deck int) []cards.Card {
+	left, right := stack[:len(stack)/2], stack[len(stack)/2:]
+	half := utils.Max(1, deck/2)
+
+	riffled := make([]cards.Card, 0, len(stack))
+	for top := 0; top < len(right); top += half {
+		grab := append([]cards.Card{}, left[utils.Min(top, len(left)):utils.Min(top+half, len(left))]...)
+		grab = append(grab, right[top:utils.Min(top+half, len(right))]...)
+		riffled = append(riffled, cards.Riffle(rng, grab)...)
+	}
+	return riffled
+}
+
+// inGrabs does shuffle to size cards of stack at a time.
+func inGrabs(stack []cards.Card, size int, shuffle func(grab []cards.Card) []cards.Card) []cards.Card {
+	shuffled := make([]cards.Card, 0, len(stack))
+	for top := 0; top < len(stack); top += size {
+		shuffled = append(shuffled, shuffle(stack[top:utils.Min(top+size, len(stack))])...)
+	}
+	return shuffled
+}
+
+// Discard puts played cards on the shoe's discard tray, face down as they
+// were before they were dealt.
+func Discard(t *Table, played ...cards.Card) {
+	for _, card := range played {
+		t.State.Shoe.Discards = append(t.State.Shoe.Discards, cards.CreateCard(card.Suite, card.Value))
+	}
+}
+
+// PickUpCards clears the round's cards to the discard tray, each player's
+// hands by seat and the dealer's last.
+func PickUpCards(t *Table) {
+	for _, currPlayer := range t.State.Players {
+		for _, hand := range currPlayer.Hands {
+			Discard(t, hand.Cards...)
+		}
+	}
+	for _, hand := range t.State.Dealer.Hands {
+		Discard(t, hand.Cards...)
+	}
+}
+
+// pickUpShoe is the stack the dealer shuffles: the cards behind the cut card
+// go on the discard tray, and the tray is lifted out, its last cards on top.
+func pickUpShoe(shoe Shoe) []cards.Card {
+	tray := shoe.Discards
+	if len(tray) != shoe.Index {
+		// a shoe saved before it kept a tray has its cards as they were dealt
+		tray = shoe.Cards[:shoe.Index]
+	}
+
+	stack := append([]cards.Card{}, shoe.Cards[shoe.Index:]...)
+	for i := len(tray) - 1; i >= 0; i-- {
+		stack = append(stack, tray[i])
+	}
+	return stack
+}
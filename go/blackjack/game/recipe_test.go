@@ -0,0 +1,94 @@
+package game
+
+import (
+	"blackjack/cards"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseShuffleRecipe(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ShuffleRecipe
+		err  bool
+	}{
+		{in: "", want: nil},
+		{in: "perfect", want: nil},
+		{in: "Standard", want: ShuffleRecipe{RiffleStep, RiffleStep, StripStep, RiffleStep, CutStep}},
+		{in: "wash, riffle,box,cut", want: ShuffleRecipe{WashStep, RiffleStep, BoxStep, CutStep}},
+		{in: "riffle,shake", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseShuffleRecipe(test.in)
+		if (err != nil) != test.err {
+			t.Fatalf("%q: unexpected error %v", test.in, err)
+		}
+		if !test.err && !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("%q: expected %v got %v", test.in, test.want, got)
+		}
+	}
+
+	recipe := ShuffleRecipes["box"]
+	if again, err := ParseShuffleRecipe(recipe.String()); err != nil || !reflect.DeepEqual(again, recipe) {
+		t.Fatalf("expected %q to read back as itself got %v", recipe.String(), again)
+	}
+}
+
+func TestPickUpShoeFromTheTray(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 1)
+	shoe := &table.State.Shoe
+	shoe.Index = 3
+	hole := shoe.Cards[2]
+	hole.Masked = true
+	Discard(table, hole, shoe.Cards[0], shoe.Cards[1])
+
+	stack := pickUpShoe(*shoe)
+	if !reflect.DeepEqual(stack[:49], shoe.Cards[3:]) {
+		t.Fatalf("expected the cards behind the cut on top")
+	}
+	want := []cards.Card{shoe.Cards[1], shoe.Cards[0], shoe.Cards[2]}
+	if !reflect.DeepEqual(stack[49:], want) {
+		t.Fatalf("expected the tray's last cards on top got %v", stack[49:])
+	}
+
+	// without a tray the played cards come back as they were dealt
+	shoe.Discards = nil
+	if stack := pickUpShoe(*shoe); !reflect.DeepEqual(stack[49:], []cards.Card{shoe.Cards[2], shoe.Cards[1], shoe.Cards[0]}) {
+		t.Fatalf("expected the dealt cards got %v", stack[49:])
+	}
+}
+
+func TestShuffleShoeByRecipe(t *testing.T) {
+	for name, recipe := range ShuffleRecipes {
+		table := NewTable(Blackjack, 1)
+		CreateShoe(table, 6)
+		shoe := &table.State.Shoe
+		shoe.Recipe = recipe
+		shoe.Penetration = Penetration{Percent: 75}
+		before := counted(shoe.Cards)
+
+		shoe.Index = 250
+		Discard(table, shoe.Cards[:250]...)
+		shoe.Cut = 234
+		ShuffleShoeIfNeeded(table)
+
+		if !reflect.DeepEqual(counted(shoe.Cards), before) {
+			t.Fatalf("%s: the shuffle lost or duplicated cards", name)
+		}
+		if shoe.Index != 1 || shoe.Cut != 234 || len(shoe.Discards) != 1 || shoe.Discards[0] != shoe.Cards[0] {
+			t.Fatalf("%s: expected a cut shoe with its burn card on the tray got index %d cut %d tray %v", name, shoe.Index, shoe.Cut, shoe.Discards)
+		}
+	}
+}
+
+func counted(shoe []cards.Card) []string {
+	names := make([]string, len(shoe))
+	for i, card := range shoe {
+		names[i] = cards.CardToString(card, false, false, false)
+	}
+	sort.Strings(names)
+	return names
+}
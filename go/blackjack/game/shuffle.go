@@ -0,0 +1,236 @@
+package game
+
+import (
+	"blackjack/cards"
+	"blackjack/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Shuffler is how the shoe's cards are put back and shuffled.
+type Shuffler int8
+
+const (
+	HandShuffle        Shuffler = iota // the dealer shuffles the whole shoe once the cut card comes out
+	ContinuousShuffler                 // every card played goes back into the machine after each round
+	AutoShuffler                       // at the cut card only the played cards are shuffled, under those still to come
+)
+
+var ShufflerToString = map[Shuffler]string{
+	HandShuffle:        "hand",
+	ContinuousShuffler: "csm",
+	AutoShuffler:       "auto",
+}
+
+func ParseShuffler(shufflerString string) (Shuffler, error) {
+	for shuffler, name := range ShufflerToString {
+		if strings.EqualFold(name, strings.TrimSpace(shufflerString)) {
+			return shuffler, nil
+		}
+	}
+	return HandShuffle, fmt.Errorf("shuffler %q should be hand, csm or auto", shufflerString)
+}
+
+func (s *Shuffler) Set(shufflerString string) error {
+	shuffler, err := ParseShuffler(shufflerString)
+	if err != nil {
+		return err
+	}
+	*s = shuffler
+	return nil
+}
+
+func (s *Shuffler) String() string {
+	if s == nil {
+		return ""
+	}
+	return ShufflerToString[*s]
+}
+
+// MaxPenetration is the most of the shoe dealt before the cut card.
+const MaxPenetration = 90
+
+// CardsPerSeat is how many cards the shoe keeps back for the dealer and each
+// hand dealt: one with fewer left is shuffled before the next round, however
+// far off the cut card is. A round that runs through them anyway has the
+// discards shuffled back in, see RefillShoe.
+const CardsPerSeat = 6
+
+// Penetration is how much of the shoe is dealt before it is shuffled, as a
+// percentage or a number of decks. The zero Penetration has the cut rolled.
+type Penetration struct {
+	Percent float64 `yaml:"percent,omitempty"`
+	Decks   float64 `yaml:"decks,omitempty"`
+}
+
+// ParsePenetration reads a percentage such as 75% or a number of decks such
+// as 4.5, empty for a rolled cut.
+func ParsePenetration(penetrationString string) (Penetration, error) {
+	penetrationString = strings.TrimSpace(penetrationString)
+	if penetrationString == "" {
+		return Penetration{}, nil
+	}
+
+	percent := strings.HasSuffix(penetrationString, "%")
+	amount, err := strconv.ParseFloat(strings.TrimSuffix(penetrationString, "%"), 64)
+	if err != nil {
+		return Penetration{}, fmt.Errorf("penetration %q should look like 75%% or 4.5", penetrationString)
+	}
+	if amount <= 0 {
+		return Penetration{}, errors.New("penetration must be positive")
+	}
+
+	if percent {
+		if amount > MaxPenetration {
+			return Penetration{}, fmt.Errorf("penetration may be at most %d%%", MaxPenetration)
+		}
+		return Penetration{Percent: amount}, nil
+	}
+	return Penetration{Decks: amount}, nil
+}
+
+func (p *Penetration) Set(penetrationString string) error {
+	penetration, err := ParsePenetration(penetrationString)
+	if err != nil {
+		return err
+	}
+	*p = penetration
+	return nil
+}
+
+func (p *Penetration) String() string {
+	switch {
+	case p == nil:
+		return ""
+	case p.Percent > 0:
+		return strconv.FormatFloat(p.Percent, 'f', -1, 64) + "%"
+	case p.Decks > 0:
+		return strconv.FormatFloat(p.Decks, 'f', -1, 64)
+	}
+	return ""
+}
+
+// cut is where penetration puts the cut card in shoe, and whether it is set.
+// It is never deeper than MaxPenetration.
+func (p Penetration) cut(shoe Shoe) (int, bool) {
+	total := len(shoe.Cards)
+	var cut int
+	switch {
+	case p.Percent > 0:
+		cut = int(float64(total) * p.Percent / 100)
+	case p.Decks > 0 && len(shoe.Decks) > 0:
+		cut = int(p.Decks * float64(total) / float64(len(shoe.Decks)))
+	default:
+		return 0, false
+	}
+
+	return utils.Max(1, utils.Min(cut, total*MaxPenetration/100)), true
+}
+
+// CutShoe places the cut card by the shoe's Penetration, or else where two
+// d20s put it, rolled again until it is no deeper than 80%.
+func CutShoe(t *Table) {
+	if cut, ok := t.State.Shoe.Penetration.cut(t.State.Shoe); ok {
+		t.State.Shoe.Cut = cut
+		return
+	}
+
+	for {
+		die1 := utils.RollDice(t.Rand)
+		die2 := utils.RollDice(t.Rand)
+
+		if die1+die2 <= int(float32(.8)*float32(len(utils.Die)*2)) {
+			t.State.Shoe.Cut = int(len(t.State.Shoe.Cards) * (die1 + die2) / (2 * utils.Die[len(utils.Die)-1]))
+			return
+		}
+	}
+}
+
+// ShuffleShoeIfNeeded shuffles between rounds as the shoe's Shuffler does:
+// by hand or in part once the cut card is out or too few cards are left for
+// the next round, or after every round. A hand shuffle is done by the shoe's
+// Recipe.
+func ShuffleShoeIfNeeded(t *Table) {
+	shoe := &t.State.Shoe
+	switch {
+	case shoe.Shuffler == ContinuousShuffler:
+		// the machine deals from everything, so there is no cut or burn
+		if shoe.Index == 0 {
+			return
+		}
+		cards.ShuffleCards(t.Rand, shoe.Cards)
+		shoe.Index = 0
+		shoe.Cut = len(shoe.Cards)
+		shoe.Discards = nil
+		ResetCount(t)
+	case shoe.Cut > shoe.Index && len(shoe.Cards)-shoe.Index >= reserve(t):
+	case shoe.Shuffler == AutoShuffler:
+		// the cards behind the cut are dealt first, unshuffled, then the played ones
+		played := append([]cards.Card{}, shoe.Cards[:shoe.Index]...)
+		cards.ShuffleCards(t.Rand, played)
+		shoe.Cards = append(append([]cards.Card{}, shoe.Cards[shoe.Index:]...), played...)
+		shuffled(t)
+	case len(shoe.Recipe) > 0:
+		// the dealer shuffles the tray's cards as they were played
+		deck := 0
+		if len(shoe.Decks) > 0 {
+			deck = len(shoe.Cards) / len(shoe.Decks)
+		}
+		shoe.Cards = shoe.Recipe.shuffle(t.Rand, pickUpShoe(*shoe), deck)
+		shuffled(t)
+	default:
+		cards.ShuffleCards(t.Rand, shoe.Cards)
+		shuffled(t)
+	}
+}
+
+// reserve is how many cards the next round keeps back, CardsPerSeat for the
+// dealer and each hand the players are dealt.
+func reserve(t *Table) int {
+	hands := len(t.State.Players)
+	if t.Mode == Switch {
+		// every player is dealt two hands
+		hands *= 2
+	}
+	return CardsPerSeat * (hands + 1)
+}
+
+// shuffled cuts and burns a newly shuffled shoe and starts the count over.
+func shuffled(t *Table) {
+	CutShoe(t)
+	t.State.Shoe.Index = 0
+	t.State.Shoe.Discards = nil
+	BurnCard(t)
+	ResetCount(t)
+}
+
+// RefillShoe shuffles the discard tray back in when the shoe runs out in the
+// middle of a round, as a dealer would, and brings the cut card out so the
+// whole shoe is shuffled once the round is over. A round that has dealt every
+// card of the shoe goes on with a fresh deck.
+func RefillShoe(t *Table) {
+	shoe := &t.State.Shoe
+	if shoe.Index < len(shoe.Cards) {
+		return
+	}
+
+	// the tray holds the earlier rounds' cards, the rest are still in play
+	played := len(shoe.Discards)
+	if played > shoe.Index {
+		played = 0
+	}
+	inPlay := append([]cards.Card{}, shoe.Cards[played:shoe.Index]...)
+	tray := append([]cards.Card{}, shoe.Discards[:played]...)
+	if len(tray) == 0 {
+		tray = CreateDeck(t).Cards
+	}
+	cards.ShuffleCards(t.Rand, tray)
+
+	shoe.Cards = append(inPlay, tray...)
+	shoe.Index = len(inPlay)
+	shoe.Cut = shoe.Index
+	shoe.Discards = nil
+	ResetCount(t)
+}
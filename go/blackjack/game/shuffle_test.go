@@ -0,0 +1,143 @@
+package game
+
+import (
+	"blackjack/cards"
+	"blackjack/player"
+	"reflect"
+	"testing"
+)
+
+func TestParsePenetration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Penetration
+		err  bool
+	}{
+		{in: "", want: Penetration{}},
+		{in: "75%", want: Penetration{Percent: 75}},
+		{in: " 4.5 ", want: Penetration{Decks: 4.5}},
+		{in: "95%", err: true},
+		{in: "0", err: true},
+		{in: "x", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParsePenetration(test.in)
+		if (err != nil) != test.err {
+			t.Fatalf("%q: unexpected error %v", test.in, err)
+		}
+		if !test.err && got != test.want {
+			t.Fatalf("%q: expected %+v got %+v", test.in, test.want, got)
+		}
+	}
+}
+
+func TestCutShoeByPenetration(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 6)
+
+	table.State.Shoe.Penetration = Penetration{Percent: 75}
+	CutShoe(table)
+	if table.State.Shoe.Cut != 234 {
+		t.Fatalf("expected 75%% of 312 cards got %d", table.State.Shoe.Cut)
+	}
+
+	table.State.Shoe.Penetration = Penetration{Decks: 4.5}
+	CutShoe(table)
+	if table.State.Shoe.Cut != 234 {
+		t.Fatalf("expected 4.5 decks got %d", table.State.Shoe.Cut)
+	}
+
+	table.State.Shoe.Penetration = Penetration{Decks: 6}
+	CutShoe(table)
+	if table.State.Shoe.Cut != 280 {
+		t.Fatalf("expected the cut no deeper than 90%% got %d", table.State.Shoe.Cut)
+	}
+}
+
+func TestShuffleKeepsCardsForEverySeat(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 1)
+	table.State.Players = make([]player.Player, 5)
+	table.State.Shoe.Cut = 50
+	table.State.Shoe.Index = 20
+
+	// five players and the dealer keep 36 cards back, more than the 32 left
+	ShuffleShoeIfNeeded(table)
+	if table.State.Shoe.Index != 1 {
+		t.Fatalf("expected a shuffle before the cut card got index %d", table.State.Shoe.Index)
+	}
+
+	table.State.Players = table.State.Players[:1]
+	table.State.Shoe.Cut = 50
+	table.State.Shoe.Index = 20
+	ShuffleShoeIfNeeded(table)
+	if table.State.Shoe.Index != 20 {
+		t.Fatalf("expected one player to be dealt on got index %d", table.State.Shoe.Index)
+	}
+}
+
+func TestRefillShoe(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 1)
+	shoe := &table.State.Shoe
+
+	// 40 cards from earlier rounds on the tray, 12 out on the table
+	Discard(table, shoe.Cards[:40]...)
+	shoe.Index = 52
+	inPlay := append([]cards.Card{}, shoe.Cards[40:]...)
+	RefillShoe(table)
+
+	if len(shoe.Cards) != 52 || shoe.Index != 12 || shoe.Cut != 12 || len(shoe.Discards) != 0 {
+		t.Fatalf("expected the tray shuffled in behind the cards in play got %+v", *shoe)
+	}
+	if !reflect.DeepEqual(shoe.Cards[:12], inPlay) {
+		t.Fatalf("expected the cards in play kept ahead of the index")
+	}
+
+	// nothing happens while there are cards left
+	RefillShoe(table)
+	if shoe.Index != 12 {
+		t.Fatalf("expected the shoe left alone got index %d", shoe.Index)
+	}
+}
+
+func TestContinuousShufflerShufflesEveryRound(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 1)
+	table.State.Shoe.Shuffler = ContinuousShuffler
+
+	ShuffleShoeIfNeeded(table)
+	if table.State.Shoe.Index != 0 {
+		t.Fatalf("a fresh shoe should not be shuffled")
+	}
+
+	table.State.Shoe.Index = 5
+	table.State.Count = 3
+	ShuffleShoeIfNeeded(table)
+	if table.State.Shoe.Index != 0 || table.State.Shoe.Cut != 52 || table.State.Count != 0 {
+		t.Fatalf("expected the played cards back in the machine got %+v", table.State.Shoe)
+	}
+}
+
+func TestAutoShufflerDealsUnplayedCardsFirst(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 1)
+	table.State.Shoe.Shuffler = AutoShuffler
+	table.State.Shoe.Penetration = Penetration{Percent: 50}
+	CutShoe(table)
+
+	table.State.Shoe.Index = 30
+	unplayed := append([]cards.Card{}, table.State.Shoe.Cards[30:]...)
+	ShuffleShoeIfNeeded(table)
+
+	if len(table.State.Shoe.Cards) != 52 || table.State.Shoe.Cut != 26 {
+		t.Fatalf("unexpected shoe after the shuffle %+v", table.State.Shoe)
+	}
+	if !reflect.DeepEqual(table.State.Shoe.Cards[:len(unplayed)], unplayed) {
+		t.Fatalf("expected the unplayed cards first, in order")
+	}
+	if table.State.Shoe.Index != 1 {
+		t.Fatalf("expected a burnt card got index %d", table.State.Shoe.Index)
+	}
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"blackjack/cards"
+	"blackjack/utils"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// StackedCard is a card a stacked shoe deals in its place, of any suit when
+// only its value was given.
+type StackedCard struct {
+	Card    cards.Card
+	AnySuit bool
+}
+
+// Stack is a prearranged run of cards dealt first from a new shoe. The rest
+// of the shoe's own cards follow it, or random cards when Random is set.
+type Stack struct {
+	Cards  []StackedCard
+	Random bool
+}
+
+// randomTail is the word that ends a stack to have random cards follow it.
+const randomTail = "random"
+
+var suiteLetters = map[rune]cards.CardSuite{
+	'♠': cards.Spades, 'S': cards.Spades,
+	'♥': cards.Hearts, 'H': cards.Hearts,
+	'♦': cards.Diamonds, 'D': cards.Diamonds,
+	'♣': cards.Clubs, 'C': cards.Clubs,
+}
+
+// LoadStack reads a stack from the file spec names, or else from spec itself.
+func LoadStack(spec string) (Stack, error) {
+	if data, err := os.ReadFile(spec); err == nil {
+		return ParseStack(string(data))
+	}
+	return ParseStack(spec)
+}
+
+// ParseStack reads cards such as A♠, 10h or ♦K, or a bare value such as 8,
+// separated by spaces, commas or lines, in the order they are dealt. A #
+// starts a comment to the end of the line and a last word random has random
+// cards follow them.
+func ParseStack(stackString string) (Stack, error) {
+	stack := Stack{}
+	words := []string{}
+	for _, line := range strings.Split(stackString, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		words = append(words, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})...)
+	}
+
+	for i, word := range words {
+		if strings.EqualFold(word, randomTail) && i == len(words)-1 {
+			stack.Random = true
+			continue
+		}
+
+		card, err := parseStackedCard(word)
+		if err != nil {
+			return Stack{}, err
+		}
+		stack.Cards = append(stack.Cards, card)
+	}
+
+	if len(stack.Cards) == 0 {
+		return Stack{}, fmt.Errorf("stack %q has no cards, it should list cards such as A♠ 10h 8 or be a file listing them", stackString)
+	}
+	return stack, nil
+}
+
+func parseStackedCard(word string) (StackedCard, error) {
+	runes := []rune(strings.ToUpper(word))
+	stacked := StackedCard{AnySuit: true}
+	if suite, ok := suiteLetters[runes[0]]; ok {
+		stacked.Card.Suite, stacked.AnySuit, runes = suite, false, runes[1:]
+	} else if suite, ok := suiteLetters[runes[len(runes)-1]]; ok {
+		stacked.Card.Suite, stacked.AnySuit, runes = suite, false, runes[:len(runes)-1]
+	}
+
+	valueString := string(runes)
+	if valueString == "T" {
+		valueString = cards.CardValueToString[cards.Ten]
+	}
+	for value, name := range cards.CardValueToString {
+		if value != cards.One && name == valueString {
+			stacked.Card = cards.CreateCard(stacked.Card.Suite, value)
+			return stacked, nil
+		}
+	}
+	return StackedCard{}, fmt.Errorf("stacked card %q should be a value 2 to 10, J, Q, K or A, with or without a suit ♠♥♦♣ or SHDC", word)
+}
+
+// StackShoe deals stack first from the shoe, taking each card out of the
+// shoe's own, then the rest of them or random cards, keeping the shoe's size.
+// The cut card is put behind the stack where it can be, and nothing is burnt.
+func StackShoe(t *Table, stack Stack) {
+	shoe := &t.State.Shoe
+	rest := append([]cards.Card{}, shoe.Cards...)
+	stacked := make([]cards.Card, 0, len(shoe.Cards))
+
+	for _, stackedCard := range stack.Cards {
+		card := stackedCard.Card
+		taken := len(rest) - 1 // a card the shoe has run out of takes the place of its last
+		for i, restCard := range rest {
+			if restCard.Value == card.Value && (stackedCard.AnySuit || restCard.Suite == card.Suite) {
+				card, taken = restCard, i
+				break
+			}
+		}
+		if taken >= 0 {
+			rest = append(rest[:taken], rest[taken+1:]...)
+		}
+		stacked = append(stacked, card)
+	}
+
+	if stack.Random {
+		values := []cards.CardValue{}
+		for _, value := range cards.CardValues {
+			if InDeck(t.Mode, value) {
+				values = append(values, value)
+			}
+		}
+		for len(stacked) < len(shoe.Cards) {
+			suite := cards.Suites[t.Rand.Intn(len(cards.Suites))]
+			stacked = append(stacked, cards.CreateCard(suite, values[t.Rand.Intn(len(values))]))
+		}
+	} else {
+		stacked = append(stacked, rest...)
+	}
+
+	shoe.Cards = stacked
+	shoe.Index = 0
+	shoe.Discards = nil
+	shoe.Cut = utils.Min(utils.Max(shoe.Cut, len(stack.Cards)), len(shoe.Cards)*MaxPenetration/100)
+	ResetCount(t)
+}
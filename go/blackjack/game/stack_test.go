@@ -0,0 +1,100 @@
+package game
+
+import (
+	"blackjack/cards"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseStack(t *testing.T) {
+	stack, err := ParseStack("A♠, 10h ♦K\n# the dealer's cards\nqc 8 t random")
+	if err != nil {
+		t.Fatalf("ParseStack returned error: %v", err)
+	}
+
+	want := []StackedCard{
+		{Card: cards.CreateCard(cards.Spades, cards.Ace)},
+		{Card: cards.CreateCard(cards.Hearts, cards.Ten)},
+		{Card: cards.CreateCard(cards.Diamonds, cards.King)},
+		{Card: cards.CreateCard(cards.Clubs, cards.Queen)},
+		{Card: cards.CreateCard(cards.Hearts, cards.Eight), AnySuit: true},
+		{Card: cards.CreateCard(cards.Hearts, cards.Ten), AnySuit: true},
+	}
+	if !reflect.DeepEqual(stack.Cards, want) || !stack.Random {
+		t.Fatalf("unexpected stack %+v", stack)
+	}
+
+	for _, bad := range []string{"", "# nothing", "A♠ 1h", "random A♠", "♠"} {
+		if _, err := ParseStack(bad); err == nil {
+			t.Fatalf("expected %q not to parse", bad)
+		}
+	}
+}
+
+func TestLoadStackFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shoe.txt")
+	if err := os.WriteFile(path, []byte("8s 8h\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stack, err := LoadStack(path)
+	if err != nil || len(stack.Cards) != 2 || stack.Cards[1].Card.Suite != cards.Hearts {
+		t.Fatalf("expected the file's two eights got %+v, %v", stack, err)
+	}
+}
+
+func TestStackShoe(t *testing.T) {
+	table := NewTable(Blackjack, 1)
+	CreateShoe(table, 1)
+	table.State.Shoe.Cut = 10
+	before := counted(table.State.Shoe.Cards)
+
+	stack, _ := ParseStack("8 8 A♥ 8 8")
+	StackShoe(table, stack)
+	shoe := table.State.Shoe
+
+	if shoe.Index != 0 || shoe.Cut != 10 || !reflect.DeepEqual(counted(shoe.Cards), before) {
+		t.Fatalf("expected the shoe's own cards unburnt and cut at 10 got index %d cut %d", shoe.Index, shoe.Cut)
+	}
+	if shoe.Cards[2] != cards.CreateCard(cards.Hearts, cards.Ace) {
+		t.Fatalf("expected the stacked ace third got %v", shoe.Cards[2])
+	}
+	suits := map[cards.CardSuite]bool{}
+	for _, i := range []int{0, 1, 3, 4} {
+		if shoe.Cards[i].Value != cards.Eight {
+			t.Fatalf("expected an eight at %d got %v", i, shoe.Cards[i])
+		}
+		suits[shoe.Cards[i].Suite] = true
+	}
+	if len(suits) != 4 {
+		t.Fatalf("expected the deck's four eights got %v", shoe.Cards[:5])
+	}
+
+	// a fifth eight takes the place of another card
+	CreateShoe(table, 1)
+	stack, _ = ParseStack("8 8 8 8 8♠")
+	StackShoe(table, stack)
+	if len(table.State.Shoe.Cards) != 52 || table.State.Shoe.Cards[4] != cards.CreateCard(cards.Spades, cards.Eight) {
+		t.Fatalf("expected a shoe of 52 with a fifth eight got %d cards", len(table.State.Shoe.Cards))
+	}
+}
+
+func TestStackShoeThenRandom(t *testing.T) {
+	table := NewTable(Spanish21, 1)
+	CreateShoe(table, 2)
+	table.State.Shoe.Cut = 95
+
+	stack, _ := ParseStack("A♠ K♦ random")
+	StackShoe(table, stack)
+	shoe := table.State.Shoe
+	if len(shoe.Cards) != 96 || shoe.Cut != 86 {
+		t.Fatalf("expected 96 cards cut at 90%% got %d cut %d", len(shoe.Cards), shoe.Cut)
+	}
+	for _, card := range shoe.Cards[2:] {
+		if card.Value == cards.Ten || card.Value == cards.One {
+			t.Fatalf("expected random cards from a Spanish 21 deck got %v", card)
+		}
+	}
+}
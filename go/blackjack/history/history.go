@@ -0,0 +1,314 @@
+package history
+
+import (
+	"blackjack/cards"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Kinds of Event.
+const (
+	Wager         = "wager"          // Amount placed on the seat's Hand, the first unless Blackjack Switch deals two
+	Sidebet       = "sidebet"        // Amount placed on the seat's Sidebet
+	SidebetPayout = "sidebet-payout" // Amount the Sidebet paid
+	Deal          = "deal"           // Card dealt face up to the hand
+	Hole          = "hole"           // Card dealt face down to the dealer
+	Reveal        = "reveal"         // the dealer turns the hole card over
+	Action        = "action"         // the player's Action, with any Amount it added to the wager
+	Net           = "net"            // Amount the seat, or the house for the dealer's seat, won over the round
+)
+
+// DealerSeat is the dealer's seat in an Event.
+const DealerSeat = -1
+
+// Event is one thing that happened in a round. Seats count from 0 in the
+// order players are dealt to, and Hand is which of the seat's hands, split
+// hands being added after the hand they were split from.
+type Event struct {
+	Kind    string `json:"kind"`
+	Seat    int    `json:"seat"`
+	Hand    int    `json:"hand"`
+	Card    string `json:"card,omitempty"`
+	Action  string `json:"action,omitempty"`
+	Sidebet string `json:"sidebet,omitempty"`
+	Amount  int    `json:"amount,omitempty"`
+}
+
+// Round is one line of a hand history file: where the round was dealt from
+// and under which table rules, minimum and sidebets, every seat's stack, the
+// house's total and the progressive meters before it and everything that
+// happened, in order. The table rules are kept as the dealer wrote them, since
+// history sits below the rules package, and the sidebets as the -sidebets
+// flag takes them.
+type Round struct {
+	Round      int             `json:"round"`
+	Seed       int64           `json:"seed"`
+	Game       string          `json:"game"`
+	Decks      int             `json:"decks,omitempty"`
+	TableRules json.RawMessage `json:"table-rules,omitempty"`
+	Minimum    int             `json:"minimum,omitempty"`
+	Sidebets   string          `json:"sidebets,omitempty"`
+	ShoeIndex  int             `json:"shoe-index"`
+	House      int             `json:"house"`
+	Meters     map[string]int  `json:"meters,omitempty"`
+	Stacks     []int           `json:"stacks"`
+	Events     []Event         `json:"events"`
+}
+
+// CardString writes card, face up, the way cards.ToCard reads it back.
+func CardString(card cards.Card) string {
+	card.Masked = false
+	return cards.CardToString(card, false, false, false)
+}
+
+// Append adds round as a line to the hand history file at path.
+func Append(path string, round Round) error {
+	line, err := json.Marshal(round)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Read reads every round of a hand history.
+func Read(r io.Reader) ([]Round, error) {
+	rounds := make([]Round, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		round := Round{}
+		if err := json.Unmarshal(scanner.Bytes(), &round); err != nil {
+			return nil, fmt.Errorf("hand history line %d: %w", line, err)
+		}
+		rounds = append(rounds, round)
+	}
+
+	return rounds, scanner.Err()
+}
+
+func ReadFile(path string) ([]Round, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Find returns the last round numbered number, since a replayed session can
+// log the same round numbers again.
+func Find(rounds []Round, number int) (Round, bool) {
+	for i := len(rounds) - 1; i >= 0; i-- {
+		if rounds[i].Round == number {
+			return rounds[i], true
+		}
+	}
+	return Round{}, false
+}
+
+// View is the table as it stood after one event of a round. Hole is the
+// dealer's face down card, shown once it is revealed.
+type View struct {
+	Event    Event
+	Dealer   []cards.Card
+	Hole     int
+	Revealed bool
+	Seats    [][][]cards.Card
+	Wagers   [][]int
+	Nets     []int
+	House    int
+}
+
+// Replay steps through round, returning the table after each of its events.
+func Replay(round Round) []View {
+	views := make([]View, 0, len(round.Events))
+	view := View{Hole: -1, Seats: make([][][]cards.Card, len(round.Stacks)), Wagers: make([][]int, len(round.Stacks)), Nets: make([]int, len(round.Stacks))}
+
+	for _, event := range round.Events {
+		view = step(view, event)
+		views = append(views, view)
+	}
+
+	return views
+}
+
+// step applies event to a copy of view.
+func step(view View, event Event) View {
+	next := View{Event: event, Hole: view.Hole, Revealed: view.Revealed, House: view.House}
+	next.Dealer = append([]cards.Card{}, view.Dealer...)
+	next.Nets = append([]int{}, view.Nets...)
+	next.Seats = make([][][]cards.Card, len(view.Seats))
+	next.Wagers = make([][]int, len(view.Wagers))
+	for seat := range view.Seats {
+		for _, hand := range view.Seats[seat] {
+			next.Seats[seat] = append(next.Seats[seat], append([]cards.Card{}, hand...))
+		}
+		next.Wagers[seat] = append([]int{}, view.Wagers[seat]...)
+	}
+
+	if event.Seat < DealerSeat || event.Seat >= len(next.Seats) || event.Seat == DealerSeat && event.Kind != Deal && event.Kind != Hole && event.Kind != Reveal && event.Kind != Net {
+		// a seat the stacks did not list, there is nothing to show it in
+		return next
+	}
+
+	switch event.Kind {
+	case Wager:
+		if event.Hand == 0 {
+			next.Seats[event.Seat], next.Wagers[event.Seat] = nil, nil
+		}
+		for len(next.Seats[event.Seat]) <= event.Hand {
+			next.Seats[event.Seat] = append(next.Seats[event.Seat], []cards.Card{})
+			next.Wagers[event.Seat] = append(next.Wagers[event.Seat], 0)
+		}
+		next.Wagers[event.Seat][event.Hand] = event.Amount
+	case Deal, Hole:
+		card := cards.ToCard(event.Card)
+		if event.Seat == DealerSeat {
+			if event.Kind == Hole {
+				next.Hole = len(next.Dealer)
+			}
+			next.Dealer = append(next.Dealer, card)
+		} else if event.Hand < len(next.Seats[event.Seat]) {
+			next.Seats[event.Seat][event.Hand] = append(next.Seats[event.Seat][event.Hand], card)
+		}
+	case Reveal:
+		next.Revealed = true
+	case Action:
+		if event.Seat != DealerSeat && event.Hand < len(next.Seats[event.Seat]) {
+			switch event.Action {
+			case "d":
+				next.Wagers[event.Seat][event.Hand] += event.Amount
+			case "p":
+				hand := next.Seats[event.Seat][event.Hand]
+				if len(hand) == 2 {
+					next.Seats[event.Seat][event.Hand] = hand[:1]
+					next.Seats[event.Seat] = append(next.Seats[event.Seat], []cards.Card{hand[1]})
+					next.Wagers[event.Seat] = append(next.Wagers[event.Seat], event.Amount)
+				}
+			case "t":
+				// Blackjack Switch swaps the second cards of the seat's two hands
+				hands := next.Seats[event.Seat]
+				if len(hands) >= 2 && len(hands[0]) >= 2 && len(hands[1]) >= 2 {
+					hands[0][1], hands[1][1] = hands[1][1], hands[0][1]
+				}
+			}
+		}
+	case Net:
+		if event.Seat == DealerSeat {
+			next.House = event.Amount
+		} else {
+			next.Nets[event.Seat] = event.Amount
+		}
+	}
+
+	return next
+}
+
+// Describe says what event did, e.g. "seat 1 hand 1 hits".
+func Describe(event Event) string {
+	who := fmt.Sprintf("seat %d", event.Seat+1)
+	if event.Seat == DealerSeat && event.Kind == Net {
+		who = "house"
+	} else if event.Seat == DealerSeat {
+		who = "dealer"
+	}
+
+	switch event.Kind {
+	case Wager:
+		return fmt.Sprintf("%s wagers %d", who, event.Amount)
+	case Sidebet:
+		return fmt.Sprintf("%s plays %s for %d", who, sidebetName(event), event.Amount)
+	case SidebetPayout:
+		return fmt.Sprintf("%s %s pays %d", who, sidebetName(event), event.Amount)
+	case Deal:
+		if event.Seat == DealerSeat {
+			return fmt.Sprintf("dealer draws %s", event.Card)
+		}
+		return fmt.Sprintf("%s hand %d is dealt %s", who, event.Hand+1, event.Card)
+	case Hole:
+		return "dealer takes a hole card"
+	case Reveal:
+		return "dealer reveals the hole card"
+	case Action:
+		if name, ok := actionNames[event.Action]; ok {
+			return fmt.Sprintf("%s hand %d %s", who, event.Hand+1, name)
+		}
+		return fmt.Sprintf("%s hand %d plays %q", who, event.Hand+1, event.Action)
+	case Net:
+		return fmt.Sprintf("%s nets %+d", who, event.Amount)
+	}
+
+	return fmt.Sprintf("%s %s", who, event.Kind)
+}
+
+// sidebetName names the sidebet of event, "the sidebet" when it has no name.
+func sidebetName(event Event) string {
+	if event.Sidebet == "" {
+		return "the sidebet"
+	}
+	return event.Sidebet
+}
+
+var actionNames = map[string]string{
+	"d": "doubles down",
+	"e": "takes even money",
+	"h": "hits",
+	"i": "takes insurance",
+	"n": "declines",
+	"p": "splits",
+	"s": "stands",
+	"t": "switches",
+	"u": "surrenders",
+}
+
+func (v View) String() string {
+	var b strings.Builder
+
+	b.WriteString("  dealer:")
+	for i, card := range v.Dealer {
+		if i == v.Hole && !v.Revealed {
+			b.WriteString(" ***")
+		} else {
+			b.WriteString(" " + CardString(card))
+		}
+	}
+	b.WriteString("\n")
+
+	for seat, hands := range v.Seats {
+		for hand, handCards := range hands {
+			fmt.Fprintf(&b, "  seat %d hand %d (%d):", seat+1, hand+1, v.Wagers[seat][hand])
+			for _, card := range handCards {
+				b.WriteString(" " + CardString(card))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// Print steps through round, writing each event and the table after it.
+func Print(w io.Writer, round Round) {
+	fmt.Fprintf(w, "Round %d of %s, seed %d, from shoe card %d\n", round.Round, round.Game, round.Seed, round.ShoeIndex)
+	for _, view := range Replay(round) {
+		fmt.Fprintf(w, "%s\n%s", Describe(view.Event), view)
+	}
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	for i := 1; i <= 3; i++ {
+		round := Round{Round: i, Seed: 7, Game: "Blackjack", Stacks: []int{100}, Events: []Event{{Kind: Wager, Amount: 10 * i}}}
+		if err := Append(path, round); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	rounds, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	if len(rounds) != 3 {
+		t.Fatalf("got %d rounds want 3", len(rounds))
+	}
+
+	round, ok := Find(rounds, 2)
+	if !ok || round.Events[0].Amount != 20 {
+		t.Fatalf("round 2 read as %+v", round)
+	}
+	if _, ok := Find(rounds, 4); ok {
+		t.Fatalf("found a round that was never logged")
+	}
+
+	if _, err := Read(strings.NewReader("{\"round\":1}\nnot json\n")); err == nil {
+		t.Fatalf("a bad line should not read")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	round := Round{
+		Round:  1,
+		Game:   "Blackjack",
+		Stacks: []int{100},
+		Events: []Event{
+			{Kind: Wager, Seat: 0, Amount: 10},
+			{Kind: Deal, Seat: 0, Card: "♠8"},
+			{Kind: Deal, Seat: DealerSeat, Card: "♥6"},
+			{Kind: Deal, Seat: 0, Card: "♦8"},
+			{Kind: Hole, Seat: DealerSeat, Card: "♣K"},
+			{Kind: Action, Seat: 0, Action: "p", Amount: 10},
+			{Kind: Deal, Seat: 0, Card: "♠3"},
+			{Kind: Deal, Seat: 0, Hand: 1, Card: "♥2"},
+			{Kind: Action, Seat: 0, Action: "d", Amount: 10},
+			{Kind: Reveal, Seat: DealerSeat},
+			{Kind: Net, Seat: 0, Amount: 30},
+		},
+	}
+
+	views := Replay(round)
+	if len(views) != len(round.Events) {
+		t.Fatalf("got %d views want %d", len(views), len(round.Events))
+	}
+
+	if view := views[4]; view.Hole != 1 || view.Revealed {
+		t.Fatalf("hole card should be dealt face down, got %+v", view)
+	}
+	if split := views[5]; len(split.Seats[0]) != 2 || len(split.Seats[0][0]) != 1 || len(split.Seats[0][1]) != 1 {
+		t.Fatalf("split should leave two one card hands, got %v", split.Seats[0])
+	}
+
+	last := views[len(views)-1]
+	if got := last.Wagers[0]; got[0] != 20 || got[1] != 10 {
+		t.Fatalf("got wagers %v want [20 10]", got)
+	}
+	if len(last.Seats[0][0]) != 2 || len(last.Seats[0][1]) != 2 {
+		t.Fatalf("got hands %v", last.Seats[0])
+	}
+	if !last.Revealed || last.Nets[0] != 30 {
+		t.Fatalf("got %+v", last)
+	}
+
+	// the views before the split are untouched by it
+	if len(views[3].Seats[0]) != 1 || len(views[3].Seats[0][0]) != 2 {
+		t.Fatalf("replay changed an earlier view: %v", views[3].Seats[0])
+	}
+
+	var b bytes.Buffer
+	Print(&b, round)
+	if !strings.Contains(b.String(), "dealer: ♥6 ***") || !strings.Contains(b.String(), "seat 1 hand 1 splits") {
+		t.Fatalf("printed\n%s", b.String())
+	}
+}
+
+func TestReplaySwitch(t *testing.T) {
+	round := Round{
+		Round:  1,
+		Game:   "BlackjackSwitch",
+		Stacks: []int{100},
+		Events: []Event{
+			{Kind: Wager, Seat: 0, Amount: 10},
+			{Kind: Wager, Seat: 0, Hand: 1, Amount: 10},
+			{Kind: Deal, Seat: 0, Card: "♠10"},
+			{Kind: Deal, Seat: 0, Hand: 1, Card: "♥10"},
+			{Kind: Deal, Seat: DealerSeat, Card: "♥6"},
+			{Kind: Deal, Seat: 0, Card: "♦6"},
+			{Kind: Deal, Seat: 0, Hand: 1, Card: "♣A"},
+			{Kind: Hole, Seat: DealerSeat, Card: "♣K"},
+			{Kind: Action, Seat: 0, Action: "t"},
+		},
+	}
+
+	views := Replay(round)
+	if wagers := views[1].Wagers[0]; len(wagers) != 2 || wagers[0] != 10 || wagers[1] != 10 {
+		t.Fatalf("expected two wagers of 10 got %v", wagers)
+	}
+
+	last := views[len(views)-1]
+	if len(last.Seats[0]) != 2 || CardString(last.Seats[0][0][1]) != "♣A" || CardString(last.Seats[0][1][1]) != "♦6" {
+		t.Fatalf("expected the second cards switched got %v", last.Seats[0])
+	}
+	if !strings.Contains(Describe(last.Event), "switches") {
+		t.Fatalf("got %q", Describe(last.Event))
+	}
+}
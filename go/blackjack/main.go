@@ -8,35 +8,29 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"io"
 	"log"
-	"math/rand"
 	"os"
 	"runtime"
-	"sync"
-	"time"
 
-	"blackjack/cards"
+	"blackjack/analyze"
 	"blackjack/constants"
 	"blackjack/dealer"
 	"blackjack/flags"
 	"blackjack/game"
-	"blackjack/player"
-	"blackjack/rules"
+	"blackjack/history"
 	"blackjack/sidebets"
+	"blackjack/sim"
 	"blackjack/ui"
 	"blackjack/ui/terminal"
-	"blackjack/utils"
 )
 
-var onlyOnce sync.Once
 var console ui.IO
+var table *game.Table
 var cfg flags.Config
 
 func init() {
-	onlyOnce.Do(func() {
-		rand.New(rand.NewSource(time.Now().UnixNano())) // only run once
-	})
 	if runtime.GOOS != "js" {
 		f, err := os.OpenFile("log.out", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 		if err != nil {
@@ -64,6 +58,30 @@ func initBlackjack() {
 	flag.Parse()
 	cfg = flags.FromFlags()
 
+	if flag.Arg(0) == "sim" {
+		// flags may follow the command too, e.g. blackjack sim -rounds 100000
+		flag.CommandLine.Parse(flag.Args()[1:])
+		cfg = flags.FromFlags()
+		if !flags.IsSet("rounds") {
+			cfg.Rounds = sim.DefaultRounds
+		}
+
+		sim.PrintResults(sim.Run(cfg, cfg.Game))
+		os.Exit(0)
+	}
+
+	if flag.Arg(0) == "history" {
+		os.Exit(printHistory(flag.Args()[1:]))
+	}
+
+	if flag.Arg(0) == "replay" {
+		os.Exit(replayHistory(flag.Args()[1:]))
+	}
+
+	if flag.Arg(0) == "analyze" {
+		os.Exit(analyzeSidebets(flag.Args()[1:]))
+	}
+
 	if len(flag.Args()) > 0 {
 		log.Println("I am sorry, I didn't understand that.  Try -h for help?")
 		if runtime.GOOS != "js" {
@@ -72,100 +90,124 @@ func initBlackjack() {
 		return
 	}
 
+	table = dealer.OpenTable(cfg, cfg.Game, "state.out")
+	log.Printf("Seed: %d\n", table.State.Seed)
+
 	var err error
-	console, err = terminal.New(cfg)
+	console, err = terminal.New(cfg, table)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*15000000))
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*5000000))
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*2500000))
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*100000))
+	if cfg.Autoplay {
+		dealer.AutoplayPlayers(table, cfg)
+	}
+}
 
-	err = game.LoadBlackjackStateYaml(cfg.Clean)
+// readHistory reads the hand history named by a subcommand's arguments, the
+// -history file unless a path is given, and the -round it asks for, 0 when
+// it asks for none.
+func readHistory(name string, args []string) ([]history.Round, int, error) {
+	historyFlags := flag.NewFlagSet(name, flag.ExitOnError)
+	round := historyFlags.Int("round", 0, "the round to "+name+", 0 for the default")
+	historyFlags.Parse(args)
+
+	path := cfg.HistoryPath
+	if historyFlags.NArg() > 0 {
+		path = historyFlags.Arg(0)
+	}
 
-	if err != nil {
-		game.State = game.BlackjackState{
-			House:            cfg.HouseStart,
-			Wins:             0,
-			Losses:           0,
-			Pushes:           0,
-			DealerBlackjacks: 0,
-			DealerBusts:      0,
-			PlayerBlackjacks: 0,
-			PlayerBusts:      0,
-			BustCards:        []cards.Card{},
-			BustCounts:       make(map[cards.CardValue]int),
-			SidebetWinnings:  0,
-			SidebetLosings:   0,
-			Players:          make([]player.Player, 0),
-			Dealer:           player.Player{Dealer: true},
-			Rounds:           0,
+	rounds, err := history.ReadFile(path)
+	if err == nil && len(rounds) == 0 {
+		err = fmt.Errorf("%s has no rounds", path)
+	}
+	if err == nil && *round > 0 {
+		if _, ok := history.Find(rounds, *round); !ok {
+			err = fmt.Errorf("%s has no round %d", path, *round)
 		}
+	}
 
-		for _, value := range cards.CardValues {
-			game.State.BustCounts[value] = 0
-		}
+	return rounds, *round, err
+}
+
+// printHistory steps through a round of a hand history, by default the last
+// round of the -history file, e.g. blackjack history -round 12 history.jsonl
+func printHistory(args []string) int {
+	rounds, round, err := readHistory("history", args)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
 
-		game.CreateShoe(cfg.NumOfDecks)
-		// loadShoe()
-		dealer.BurnCard()
-		game.CutShoe()
+	found := rounds[len(rounds)-1]
+	if round > 0 {
+		found, _ = history.Find(rounds, round)
 	}
 
-	if game.State.Players == nil || len(game.State.Players) == 0 {
-		for i := 0; i < cfg.NumOfPlayers; i++ {
-			game.State.Players = append(game.State.Players, player.CreatePlayer(cfg.PlayerStartStack, cfg.MinWager))
+	history.Print(os.Stdout, found)
+	return 0
+}
+
+// replayHistory deals the rounds of a hand history again, all of them unless
+// a -round is given, and checks they pay out as they were logged, e.g.
+// blackjack replay -round 12 history.jsonl
+func replayHistory(args []string) int {
+	rounds, round, err := readHistory("replay", args)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	if round > 0 {
+		found, _ := history.Find(rounds, round)
+		rounds = []history.Round{found}
+	}
+
+	failed := 0
+	for _, found := range rounds {
+		if err := dealer.ReplayRound(found, cfg); err != nil {
+			log.Println(err)
+			failed++
 		}
 	}
 
-	if !game.State.Dealer.Dealer {
-		game.State.Dealer = player.Player{Dealer: true}
+	fmt.Printf("Replayed %d rounds, %d did not match\n", len(rounds), failed)
+	if failed > 0 {
+		return 1
 	}
+	return 0
+}
 
-	if cfg.Autoplay {
-		for i := 0; i < len(game.State.Players); i++ {
-			cardPlayer := &game.State.Players[i]
-
-			cardPlayer.PlaceWager = func() int {
-				if cardPlayer.LastHandPushed {
-					// if we pushed, let it ride
-					return cardPlayer.LastWager
-				}
-
-				if cardPlayer.LastHandWon {
-					// if we won, lets try to capitalize
-					if cardPlayer.WinStreak > 6 {
-						// go for the gusto!
-						return cardPlayer.Stack
-					} else if cardPlayer.WinStreak > 3 {
-						return utils.Min(cardPlayer.Stack, (2^cardPlayer.WinStreak%3)*cfg.MinWager)
-					} else {
-						return cfg.MinWager
-					}
-				}
-
-				return cfg.MinWager
-			}
-
-			cardPlayer.DoAction = func() (rune, error) {
-				defer func() {
-					if r := recover(); r != nil {
-						err := r.(error)
-						if err != nil {
-							log.Printf("We panicked!!! Why?!?!?")
-						}
-					}
-				}()
-
-				activeHand := player.ActiveHand(cardPlayer)
-				dealerFaceUpCard := cards.CardToValue(game.State.Dealer.Hands[0].Cards[0], true)
-
-				return rules.GetAutoPlayPlayerAction(activeHand, dealerFaceUpCard)
-			}
+// analyzeSidebets prints the exact house edge of the -sidebets, or of every
+// sidebet when none are given, on a fresh shoe of -decks decks of -game's
+// deck, e.g. blackjack -decks 8 analyze -game spanish21
+func analyzeSidebets(args []string) int {
+	analyzeFlags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	gameName := analyzeFlags.String("game", cfg.Game.String(), "the game whose deck the shoe is made of")
+	analyzeFlags.Parse(args)
+
+	mode, err := game.ParseGame(*gameName)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	bets := make([]sidebets.Sidebet, 0)
+	if flags.IsSet("sidebets") {
+		bets, err = sidebets.Parse(cfg.Sidebets, mode)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+	} else {
+		for _, name := range sidebets.SidebetNames() {
+			bets = append(bets, sidebets.Sidebets[name])
 		}
 	}
+
+	analyses := analyze.Sidebets(bets, mode, cfg.NumOfDecks, cfg.TableRules, cfg.MinWager/2)
+	analyze.PrintAnalyses(analyses, mode, cfg.NumOfDecks)
+	return 0
 }
 
 func main() {
@@ -173,12 +215,16 @@ func main() {
 
 	initBlackjack()
 
-	if closer, ok := console.(interface{ Close() error }); ok {
-		defer closer.Close()
+	for ok := true; ok; ok = char != 'q' {
+		char, _ = dealer.DealRound(table, console, cfg)
 	}
 
-	for ok := true; ok; ok = char != 'q' {
-		char, _ = dealer.DealRound(console, cfg)
+	if cfg.Autoplay {
+		terminal.PrintStats(table, cfg.TrifectaStax)
+	}
+
+	if closer, ok := console.(interface{ Close() error }); ok {
+		closer.Close()
 	}
 
 	if runtime.GOOS != "js" {
@@ -3,38 +3,49 @@ package player
 import (
 	"blackjack/cards"
 	"blackjack/utils"
+	"math/rand"
 	"sort"
 )
 
 type Hand struct {
-	Active           bool         `yaml:"active"`
-	Busted           bool         `yaml:"busted"`
-	Cards            []cards.Card `yaml:"cards"`
-	DoubleDown       bool         `yaml:"double-down"`
-	EvenMoney        bool         `yaml:"even-money"`
-	Insured          bool         `yaml:"insured"`
-	Player           *Player      `yaml:"-"`
-	Split            bool         `yaml:"split"`
-	InsuranceWager   int          `yaml:"insurance-wager"`
-	Stand            bool         `yaml:"stand"`
-	TrifectaWager    int          `yaml:"trifecta-wager"`
-	TrifectaWinnings int          `yaml:"trifecta-winnings"`
-	Wager            int          `yaml:"wager"`
-	Winner           bool         `yaml:"winner"`
+	Active          bool           `yaml:"active"`
+	Busted          bool           `yaml:"busted"`
+	Cards           []cards.Card   `yaml:"cards"`
+	DoubleDown      bool           `yaml:"double-down"`
+	EvenMoney       bool           `yaml:"even-money"`
+	Insured         bool           `yaml:"insured"`
+	Player          *Player        `yaml:"-"`
+	Split           bool           `yaml:"split"`
+	InsuranceWager  int            `yaml:"insurance-wager"`
+	Stand           bool           `yaml:"stand"`
+	Sidebets        map[string]int `yaml:"sidebets"`
+	SidebetWinnings map[string]int `yaml:"sidebet-winnings"`
+	Surrendered     bool           `yaml:"surrendered"`
+	Switchable      bool           `yaml:"switchable"`
+	Wager           int            `yaml:"wager"`
+	FreeWager       int            `yaml:"free-wager"` // the part of Wager the house put up, which is never lost
+	Winner          bool           `yaml:"winner"`
+	Peeked          bool           `yaml:"peeked"`   // the dealer's hand has been checked for blackjack
+	Dealt           int            `yaml:"dealt"`    // which of the player's dealt hands this is, or was split from
+	Bought          int            `yaml:"bought"`   // Pontoon buys, each putting up the dealt stake again
+	Twisted         bool           `yaml:"twisted"`  // a Pontoon hand has twisted, and may buy no more
+	Switched        bool           `yaml:"switched"` // its second card came from the other hand, so 21 on two cards is no blackjack
 }
 
 type Player struct {
-	Hands            []Hand               `yaml:"hands"`
-	Dealer           bool                 `yaml:"dealer"`
-	Stack            int                  `yaml:"stack"`
-	DoAction         func() (rune, error) `yaml:"-"`
-	PlaceWager       func() int           `yaml:"-"`
-	WillPlayTrifecta func(stack int) bool `yaml:"-"`
-	LastHandWon      bool                 `yaml:"last-hand-won"`
-	LastHandPushed   bool                 `yaml:"last-hand-pushed"`
-	LastWager        int                  `yaml:"last-wager"`
-	WinStreak        int                  `yaml:"win-streak"`
-	Winnings         int                  `yaml:"winnings"`
+	Hands           []Hand                            `yaml:"hands"`
+	Dealer          bool                              `yaml:"dealer"`
+	Stack           int                               `yaml:"stack"`
+	DoAction        func() (rune, error)              `yaml:"-"`
+	PlaceWager      func() int                        `yaml:"-"`
+	WillPlaySidebet func(name string, stack int) bool `yaml:"-"`
+	PlaceSidebet    func(name string) int             `yaml:"-"`
+	LastHandWon     bool                              `yaml:"last-hand-won"`
+	LastHandPushed  bool                              `yaml:"last-hand-pushed"`
+	LastWager       int                               `yaml:"last-wager"` // the last round's stake across every hand dealt
+	LastNet         int                               `yaml:"last-net"`   // what the last round's wagers won or lost, sidebets aside
+	WinStreak       int                               `yaml:"win-streak"`
+	Winnings        int                               `yaml:"winnings"`
 }
 
 func ActiveHand(player *Player) *Hand {
@@ -52,16 +63,16 @@ func CreateHand() Hand {
 	return Hand{Cards: make([]cards.Card, 0)}
 }
 
-func CreatePlayer(playerStartStack int, minWager int) Player {
+func CreatePlayer(rng *rand.Rand, playerStartStack int, minWager int) Player {
 	player := Player{}
 	player.Dealer = false
 	player.Hands = make([]Hand, 0)
 	if playerStartStack > 0 {
 		player.Stack = playerStartStack
 	} else {
-		player.Stack = utils.RollDice() * 5 * minWager
+		player.Stack = utils.RollDice(rng) * 5 * minWager
 	}
-	player.WillPlayTrifecta = func(stack int) bool {
+	player.WillPlaySidebet = func(name string, stack int) bool {
 		return true
 	}
 	return player
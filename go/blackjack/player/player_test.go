@@ -1,6 +1,9 @@
 package player
 
-import "testing"
+import (
+	"math/rand"
+	"testing"
+)
 
 func TestActiveHand(t *testing.T) {
 	h1 := Hand{Active: true}
@@ -21,7 +24,7 @@ func TestActiveHand(t *testing.T) {
 }
 
 func TestCreatePlayer(t *testing.T) {
-	p := CreatePlayer(100, 1)
+	p := CreatePlayer(rand.New(rand.NewSource(1)), 100, 1)
 	if p.Dealer {
 		t.Fatalf("created player should not be dealer")
 	}
@@ -31,7 +34,7 @@ func TestCreatePlayer(t *testing.T) {
 	if len(p.Hands) != 0 {
 		t.Fatalf("new player should have no hands")
 	}
-	if p.WillPlayTrifecta == nil {
-		t.Fatalf("expected WillPlayTrifecta to be non-nil")
+	if p.WillPlaySidebet == nil {
+		t.Fatalf("expected WillPlaySidebet to be non-nil")
 	}
 }
@@ -0,0 +1,386 @@
+package progressive
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Meter is a progressive jackpot, fed a share of sidebet wagers until it is
+// hit. Amounts are in cents.
+type Meter struct {
+	Name      string  `yaml:"name"`
+	Amount    int     `yaml:"amount"`
+	Seed      int     `yaml:"seed"`      // what a new meter starts at
+	Reset     int     `yaml:"reset"`     // what the meter starts again from once hit
+	Increment float64 `yaml:"increment"` // the share of each wager fed to the meter
+	Hits      []Hit   `yaml:"hits"`
+}
+
+// Hit is one time a meter paid out.
+type Hit struct {
+	Game   string    `yaml:"game"`
+	Round  int       `yaml:"round"`
+	Seat   int       `yaml:"seat"`
+	Amount int       `yaml:"amount"`
+	Time   time.Time `yaml:"time"`
+}
+
+// Setting changes how the named meter starts, starts again and is fed.
+// Fields left out keep the sidebet's own.
+type Setting struct {
+	Name      string   `yaml:"name"`
+	Seed      *int     `yaml:"seed"`
+	Reset     *int     `yaml:"reset"`
+	Increment *float64 `yaml:"increment"`
+}
+
+// lockRetry is how long a bank waits for another program to let go of its
+// file, lockTimeout how long in all before it goes ahead without the lock,
+// and staleLock how old a lock is before it was left by a program that died.
+const (
+	lockRetry   = 5 * time.Millisecond
+	lockTimeout = 5 * time.Second
+	staleLock   = 30 * time.Second
+)
+
+// Bank is a set of meters. Any number of tables can feed and hit the same
+// Bank, and one opened from a file is saved back to it whenever it changes.
+// Every change is made to the file as it is then, under a lock, so programs
+// sharing the file feed and hit the same meters.
+type Bank struct {
+	Path     string
+	mu       sync.Mutex
+	meters   []Meter
+	settings map[string]Setting
+}
+
+// banks are the banks opened so far, by path.
+var banks = make(map[string]*Bank)
+var banksMu sync.Mutex
+
+// NewBank is a bank of no meters that is never saved.
+func NewBank() *Bank {
+	return &Bank{meters: make([]Meter, 0)}
+}
+
+// Open is the bank saved at path, or a new one that will be when there is
+// nothing there yet. Every table that opens the same path shares one Bank.
+// An empty path is a new bank that is never saved.
+func Open(path string) (*Bank, error) {
+	if path == "" {
+		return NewBank(), nil
+	}
+
+	banksMu.Lock()
+	defer banksMu.Unlock()
+
+	if bank, ok := banks[path]; ok {
+		return bank, nil
+	}
+
+	bank := NewBank()
+	bank.Path = path
+	if err := bank.load(); err != nil {
+		return nil, err
+	}
+
+	banks[path] = bank
+	return bank, nil
+}
+
+// LoadSettings reads a list of Settings from the YAML file at path, none
+// when path is empty.
+func LoadSettings(path string) ([]Setting, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]Setting, 0)
+	if err := yaml.UnmarshalStrict(bytes, &settings); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// Configure changes the meters settings name, now and whenever they are
+// added. A meter keeps its amount, and a new Seed or Reset counts from the
+// meter's next start.
+func (b *Bank) Configure(settings ...Setting) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.settings == nil {
+		b.settings = make(map[string]Setting)
+	}
+	for _, setting := range settings {
+		b.settings[setting.Name] = setting
+	}
+
+	b.change(func() bool {
+		changed := false
+		for i := range b.meters {
+			if setting, ok := b.settings[b.meters[i].Name]; ok {
+				b.meters[i] = setting.apply(b.meters[i])
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// Add adds each meter the bank does not have yet, with any settings it was
+// configured with, starting it at its Seed. Meters the bank already has keep
+// their amount and settings.
+func (b *Bank) Add(meters ...Meter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.change(func() bool {
+		added := false
+		for _, meter := range meters {
+			if b.find(meter.Name) == nil {
+				meter = b.settings[meter.Name].apply(meter)
+				meter.Amount = meter.Seed
+				meter.Hits = nil
+				b.meters = append(b.meters, meter)
+				added = true
+			}
+		}
+		return added
+	})
+}
+
+// Meters is a copy of the bank's meters in the order they were added.
+func (b *Bank) Meters() []Meter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reload()
+	meters := make([]Meter, 0, len(b.meters))
+	for _, meter := range b.meters {
+		meter.Hits = append([]Hit(nil), meter.Hits...)
+		meters = append(meters, meter)
+	}
+	return meters
+}
+
+// Amount is what the named meter would pay, 0 when there is no such meter.
+func (b *Bank) Amount(name string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reload()
+	if meter := b.find(name); meter != nil {
+		return meter.Amount
+	}
+	return 0
+}
+
+// Set puts the named meter at amount, adding it when the bank does not have
+// it.
+func (b *Bank) Set(name string, amount int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.change(func() bool {
+		meter := b.find(name)
+		if meter == nil {
+			b.meters = append(b.meters, Meter{Name: name})
+			meter = &b.meters[len(b.meters)-1]
+		}
+		meter.Amount = amount
+		return true
+	})
+}
+
+// Feed adds the named meter's share of wager, in dollars, to it.
+func (b *Bank) Feed(name string, wager int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.change(func() bool {
+		meter := b.find(name)
+		if meter == nil {
+			return false
+		}
+		meter.Amount += int(meter.Increment * float64(wager) * 100)
+		return true
+	})
+}
+
+// Hit pays out the named meter, records who hit it and starts it again from
+// its Reset, returning what it paid.
+func (b *Bank) Hit(name string, hit Hit) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	paid := 0
+	b.change(func() bool {
+		meter := b.find(name)
+		if meter == nil {
+			return false
+		}
+
+		hit.Amount = meter.Amount
+		if hit.Time.IsZero() {
+			hit.Time = time.Now()
+		}
+		meter.Hits = append(meter.Hits, hit)
+		meter.Amount = meter.Reset
+		paid = hit.Amount
+		return true
+	})
+
+	return paid
+}
+
+func (b *Bank) find(name string) *Meter {
+	for i := range b.meters {
+		if b.meters[i].Name == name {
+			return &b.meters[i]
+		}
+	}
+	return nil
+}
+
+// apply is meter with the setting's fields in place of its own.
+func (setting Setting) apply(meter Meter) Meter {
+	if setting.Seed != nil {
+		meter.Seed = *setting.Seed
+	}
+	if setting.Reset != nil {
+		meter.Reset = *setting.Reset
+	}
+	if setting.Increment != nil {
+		meter.Increment = *setting.Increment
+	}
+	return meter
+}
+
+// change makes change to the meters as the bank's file has them, holding its
+// lock, and saves them back when change reports it changed something. The
+// caller holds b.mu.
+func (b *Bank) change(change func() bool) {
+	if b.Path == "" {
+		change()
+		return
+	}
+
+	unlock, err := lockFile(b.Path)
+	if err != nil {
+		log.Printf("Could not lock the progressives in %s: %v\n", b.Path, err)
+	} else {
+		defer unlock()
+	}
+
+	b.reload()
+	if change() {
+		b.save()
+	}
+}
+
+// lockFile takes the lock on the file at path, waiting for any other program
+// holding it, and returns what lets it go.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLock {
+			// left behind by a program that never let go of it
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is still locked", path)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// load reads the meters from the bank's file, keeping the ones it has when
+// there is nothing there yet. The caller holds b.mu.
+func (b *Bank) load() error {
+	bytes, err := os.ReadFile(b.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	meters := make([]Meter, 0)
+	if err := yaml.UnmarshalStrict(bytes, &meters); err != nil {
+		return err
+	}
+	b.meters = meters
+	return nil
+}
+
+// reload catches the meters up with the bank's file, when it has one, which
+// other programs may have changed. The caller holds b.mu.
+func (b *Bank) reload() {
+	if b.Path == "" {
+		return
+	}
+	if err := b.load(); err != nil {
+		log.Printf("Could not read the progressives in %s: %v\n", b.Path, err)
+	}
+}
+
+// save writes the meters to the bank's file, when it has one, replacing it
+// whole so nothing reads it half written. The caller holds b.mu.
+func (b *Bank) save() {
+	if b.Path == "" {
+		return
+	}
+
+	bytes, err := yaml.Marshal(b.meters)
+	if err == nil {
+		err = writeFile(b.Path, bytes)
+	}
+	if err != nil {
+		log.Printf("Could not save the progressives to %s: %v\n", b.Path, err)
+	}
+}
+
+// writeFile writes bytes to a new file beside path and moves it over path.
+func writeFile(path string, bytes []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(bytes); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
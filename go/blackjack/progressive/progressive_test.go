@@ -0,0 +1,125 @@
+package progressive
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestBank(t *testing.T) {
+	bank := NewBank()
+	bank.Add(Meter{Name: "Grand", Seed: 1000, Reset: 500, Increment: .5})
+	bank.Add(Meter{Name: "Grand", Seed: 9999})
+	if got := bank.Amount("Grand"); got != 1000 {
+		t.Fatalf("a new meter should start at its seed, got %d", got)
+	}
+
+	bank.Feed("Grand", 10)
+	if got := bank.Amount("Grand"); got != 1500 {
+		t.Fatalf("feeding 10 at half should add 500 cents, got %d", got)
+	}
+
+	if paid := bank.Hit("Grand", Hit{Game: "Blackjack", Round: 3, Seat: 1}); paid != 1500 {
+		t.Fatalf("the hit should pay the whole meter, got %d", paid)
+	}
+	meters := bank.Meters()
+	if meters[0].Amount != 500 || len(meters[0].Hits) != 1 || meters[0].Hits[0].Amount != 1500 || meters[0].Hits[0].Time.IsZero() {
+		t.Fatalf("the meter should reset and record the hit, got %+v", meters[0])
+	}
+
+	if paid := bank.Hit("Missing", Hit{}); paid != 0 {
+		t.Fatalf("a meter the bank does not have should pay nothing, got %d", paid)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progressives.yaml")
+
+	first, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	second, _ := Open(path)
+	if first != second {
+		t.Fatalf("tables opening the same path should share a bank")
+	}
+
+	first.Add(Meter{Name: "Minor", Seed: 100, Reset: 100, Increment: .1})
+	second.Feed("Minor", 50)
+	first.Hit("Minor", Hit{Round: 2})
+	second.Feed("Minor", 50)
+
+	// as if the program were started again
+	delete(banks, path)
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	meters := reopened.Meters()
+	if len(meters) != 1 || meters[0].Amount != 600 || meters[0].Increment != .1 || len(meters[0].Hits) != 1 || meters[0].Hits[0].Amount != 600 {
+		t.Fatalf("the meters should be saved, got %+v", meters)
+	}
+}
+
+func TestBanksSharingAFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progressives.yaml")
+
+	// two programs, each with its own bank on the same file
+	first, second := NewBank(), NewBank()
+	first.Path, second.Path = path, path
+
+	first.Add(Meter{Name: "Minor", Seed: 100, Reset: 100, Increment: .1})
+	second.Add(Meter{Name: "Minor", Seed: 999, Reset: 999, Increment: .9})
+
+	var wg sync.WaitGroup
+	for _, bank := range []*Bank{first, second} {
+		wg.Add(1)
+		go func(bank *Bank) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				bank.Feed("Minor", 10)
+			}
+		}(bank)
+	}
+	wg.Wait()
+
+	if got := second.Amount("Minor"); got != 10100 {
+		t.Fatalf("every feed from both banks should count, got %d", got)
+	}
+	if paid := second.Hit("Minor", Hit{}); paid != 10100 {
+		t.Fatalf("the hit should pay what both banks fed, got %d", paid)
+	}
+	if got := first.Meters(); got[0].Amount != 100 || len(got[0].Hits) != 1 {
+		t.Fatalf("the other bank should see the hit, got %+v", got)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meters.yaml")
+	if err := os.WriteFile(path, []byte("- name: Grand\n  reset: 700\n- name: Minor\n  seed: 50\n  increment: 0.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("LoadSettings returned error: %v", err)
+	}
+
+	bank := NewBank()
+	bank.Add(Meter{Name: "Grand", Seed: 1000, Reset: 500, Increment: .5})
+	bank.Feed("Grand", 10)
+	bank.Configure(settings...)
+	bank.Add(Meter{Name: "Minor", Seed: 100, Reset: 100, Increment: .1})
+
+	meters := bank.Meters()
+	if meters[0].Amount != 1500 || meters[0].Seed != 1000 || meters[0].Reset != 700 || meters[0].Increment != .5 {
+		t.Fatalf("a configured meter should keep its amount and the settings it was not given, got %+v", meters[0])
+	}
+	if meters[1].Amount != 50 || meters[1].Seed != 50 || meters[1].Reset != 100 || meters[1].Increment != .2 {
+		t.Fatalf("a meter added after Configure should start at its new seed, got %+v", meters[1])
+	}
+
+	if _, err := LoadSettings(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("a missing settings file should be an error")
+	}
+}
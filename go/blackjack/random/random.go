@@ -7,19 +7,19 @@ import (
 	"math/rand"
 )
 
-func RandomCard() cards.Card {
-	value := cards.CardValues[rand.Intn(len(cards.CardValues))]
+func RandomCard(rng *rand.Rand) cards.Card {
+	value := cards.CardValues[rng.Intn(len(cards.CardValues))]
 	if value == cards.One {
 		value = cards.Ace
 	}
-	return cards.CreateCard(cards.Suites[rand.Intn(len(cards.Suites))], value)
+	return cards.CreateCard(cards.Suites[rng.Intn(len(cards.Suites))], value)
 }
 
-func RandomCardSlice(length int) []cards.Card {
+func RandomCardSlice(rng *rand.Rand, length int) []cards.Card {
 	cards := make([]cards.Card, 0)
 
 	for i := 0; i < length; i++ {
-		cards = append(cards, RandomCard())
+		cards = append(cards, RandomCard(rng))
 	}
 
 	return cards
@@ -0,0 +1,47 @@
+package rules
+
+import "blackjack/game"
+
+// Action is a play in a game's own words, and the key a player presses for it.
+type Action struct {
+	Name string
+	Key  rune
+}
+
+// actions names the plays, by the rune they are played with, at a blackjack
+// table.
+var actions = map[rune]Action{
+	'h': {Name: "hit", Key: 'h'},
+	's': {Name: "stand", Key: 's'},
+	'd': {Name: "double down", Key: 'd'},
+	'p': {Name: "split", Key: 'p'},
+	'u': {Name: "surrender", Key: 'u'},
+}
+
+// gameActions are the plays a game calls something else.
+var gameActions = map[game.Game]map[rune]Action{
+	game.Pontoon: {
+		'h': {Name: "twist", Key: 't'},
+		's': {Name: "stick", Key: 's'},
+		'd': {Name: "buy", Key: 'b'},
+	},
+}
+
+// ActionFor is how mode names action and the key that plays it.
+func ActionFor(mode game.Game, action rune) Action {
+	if named, ok := gameActions[mode][action]; ok {
+		return named
+	}
+	return actions[action]
+}
+
+// ActionForKey is the play key makes in mode, key itself when it is not one
+// of mode's own.
+func ActionForKey(mode game.Game, key rune) rune {
+	for action, named := range gameActions[mode] {
+		if named.Key == key {
+			return action
+		}
+	}
+	return key
+}
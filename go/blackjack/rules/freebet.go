@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// IsFreeDouble is whether the house puts up the double on hand, as Free Bet
+// does on any two card hard 9, 10 or 11.
+func IsFreeDouble(t *game.Table, hand player.Hand) bool {
+	if t.Mode != game.FreeBet || len(hand.Cards) != 2 {
+		return false
+	}
+
+	total := 0
+	for _, card := range hand.Cards {
+		if cards.IsAce(card) {
+			return false
+		}
+		total += cards.CardToValue(card, true)
+	}
+	return total >= 9 && total <= 11
+}
+
+// IsFreeSplit is whether the house puts up the new hand's wager when hand is
+// split, as Free Bet does on every pair but tens.
+func IsFreeSplit(t *game.Table, hand player.Hand) bool {
+	if t.Mode != game.FreeBet || len(hand.Cards) != 2 {
+		return false
+	}
+
+	return cards.CardToValue(hand.Cards[0], true) == cards.CardToValue(hand.Cards[1], true) && cards.CardToValue(hand.Cards[0], true) != 10
+}
+
+// HardTotal is hand's total counting every ace as 1.
+func HardTotal(hand player.Hand) int {
+	total := 0
+	for _, card := range hand.Cards {
+		total += cards.CardToValue(card, true)
+	}
+	return total
+}
+
+// DealerPushes22 is whether a Free Bet or Blackjack Switch dealer has
+// finished on 22, which pushes every hand that has not busted.
+func DealerPushes22(t *game.Table) bool {
+	if !Pushes22(t.Mode) || len(t.State.Dealer.Hands) == 0 {
+		return false
+	}
+	return HardTotal(t.State.Dealer.Hands[0]) == 22
+}
+
+// Pushes22 is whether mode's dealer pushes on 22 rather than busting.
+func Pushes22(mode game.Game) bool {
+	return mode == game.FreeBet || mode == game.Switch
+}
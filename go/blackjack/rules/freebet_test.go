@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+func TestFreeDoublesAndSplits(t *testing.T) {
+	freeBet := game.NewTable(game.FreeBet, 1)
+	tests := []struct {
+		hand   []string
+		double bool
+		split  bool
+	}{
+		{[]string{"♠5", "♥4"}, true, false},
+		{[]string{"♠5", "♥5"}, true, true},
+		{[]string{"♠6", "♥5"}, true, false},
+		{[]string{"♠A", "♥9"}, false, false},
+		{[]string{"♠8", "♥8"}, false, true},
+		{[]string{"♠A", "♥A"}, false, true},
+		{[]string{"♠K", "♥10"}, false, false},
+		{[]string{"♠4", "♥4", "♣2"}, false, false},
+	}
+
+	for _, test := range tests {
+		hand := player.ToHand(test.hand)
+		if got := IsFreeDouble(freeBet, hand); got != test.double {
+			t.Errorf("%v free double %t want %t", test.hand, got, test.double)
+		}
+		if got := IsFreeSplit(freeBet, hand); got != test.split {
+			t.Errorf("%v free split %t want %t", test.hand, got, test.split)
+		}
+		if IsFreeDouble(table, hand) || IsFreeSplit(table, hand) {
+			t.Errorf("%v should not be free in blackjack", test.hand)
+		}
+	}
+
+	broke := player.Player{Stack: 0}
+	hand := player.ToHand([]string{"♠8", "♥8"})
+	hand.Player = &broke
+	hand.Wager = 10
+	if !CanSplit(freeBet, hand, DefaultTableRules()) {
+		t.Fatalf("a free split should not need the stack to cover it")
+	}
+}
@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// DealerToPlay is whether the players are still acting, before the dealer
+// has turned over, or without a hole card been dealt, a second card.
+func DealerToPlay(t *game.Table) bool {
+	if len(t.State.Dealer.Hands) == 0 {
+		return false
+	}
+
+	dealerHand := t.State.Dealer.Hands[0]
+	switch {
+	case len(dealerHand.Cards) == 1:
+		// no hole card yet, as under European no hole card rules
+		return true
+	case len(dealerHand.Cards) < 2:
+		return false
+	case t.Mode == game.DoubleExposure:
+		// both cards are dealt face up, so the dealer plays once it is marked done
+		return dealerHand.Active
+	}
+	return dealerHand.Cards[1].Masked
+}
+
+// DealerWinsTies is whether hands that tie the dealer lose, as they do in
+// Double Exposure and Pontoon.
+func DealerWinsTies(t *game.Table) bool {
+	return TiesLose(t.Mode)
+}
+
+// TiesLose is whether mode's dealer wins ties.
+func TiesLose(mode game.Game) bool {
+	return mode == game.DoubleExposure || mode == game.Pontoon
+}
+
+// IsOriginalHand is whether the hand at index among a player's hands was
+// dealt, rather than split off another. Blackjack Switch deals two.
+func IsOriginalHand(t *game.Table, index int) bool {
+	if t.Mode == game.Switch {
+		return index < 2
+	}
+	return index < 1
+}
+
+// OriginalBetsOnlyLoss is what hand loses to a dealer blackjack found only
+// after the players acted: under original bets only just the wager it was
+// dealt with, nothing for a hand split off another, or else all of it.
+func OriginalBetsOnlyLoss(t *game.Table, hand player.Hand, index int, tableRules TableRules) int {
+	stake := hand.Wager - hand.FreeWager
+	if !tableRules.NoHoleCard || !tableRules.OriginalBetsOnly {
+		return stake
+	}
+
+	if !IsOriginalHand(t, index) {
+		return 0
+	}
+	if hand.DoubleDown && stake > hand.Wager/2 {
+		return hand.Wager / 2
+	}
+	if hand.Bought > 0 {
+		return BuyStake(t, hand)
+	}
+	return stake
+}
+
+// DealerCardsNote says how the dealer's cards are dealt when it is not with
+// the usual hidden hole card, empty otherwise.
+func DealerCardsNote(t *game.Table, tableRules TableRules) string {
+	switch {
+	case t.Mode == game.DoubleExposure:
+		return "both cards face up, dealer wins ties"
+	case tableRules.NoHoleCard && tableRules.OriginalBetsOnly:
+		return "no hole card, original bets only"
+	case tableRules.NoHoleCard:
+		return "no hole card"
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// PontoonPayout is what a pontoon, Pontoon's blackjack, and a five card trick
+// pay.
+var PontoonPayout = Payout{Win: 2, Bet: 1}
+
+// FiveCardTrick is how many cards make a Pontoon hand complete.
+const FiveCardTrick = 5
+
+// PontoonStick is the least a Pontoon player may stick on.
+const PontoonStick = 15
+
+// IsFiveCardTrick is a Pontoon hand of five cards that has not bust, which
+// beats anything but the dealer's pontoon.
+func IsFiveCardTrick(t *game.Table, hand player.Hand) bool {
+	return t.Mode == game.Pontoon && len(hand.Cards) == FiveCardTrick && HardTotal(hand) <= 21
+}
+
+// MustTwist is a Pontoon player's hand too low to stick on that can still
+// twist.
+func MustTwist(t *game.Table, hand player.Hand, tableRules TableRules) bool {
+	if t.Mode != game.Pontoon || hand.Player == nil || IsDealer(*hand.Player) {
+		return false
+	}
+	return bestTotal(hand) < PontoonStick && CanHit(t, &hand, tableRules)
+}
+
+// BuyStake is what a buy or double on hand puts up: its wager, or in Pontoon
+// the stake it was dealt with, again for every buy.
+func BuyStake(t *game.Table, hand player.Hand) int {
+	if t.Mode == game.Pontoon {
+		return hand.Wager / (hand.Bought + 1)
+	}
+	return hand.Wager
+}
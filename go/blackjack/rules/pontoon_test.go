@@ -0,0 +1,39 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+func TestPontoonStick(t *testing.T) {
+	pontoon := game.NewTable(game.Pontoon, 1)
+	dealerHand := player.ToHand([]string{"♠7", "♥K"})
+	dealerHand.Player = &pontoon.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	pontoon.State.Dealer.Hands = []player.Hand{dealerHand}
+	tableRules := DefaultTableRules()
+
+	if ForGame(game.Pontoon, tableRules).InsuranceAllowed {
+		t.Fatalf("Pontoon should not offer insurance")
+	}
+
+	cardPlayer := player.Player{Stack: 100}
+	tests := []struct {
+		hand  []string
+		stick bool
+	}{
+		{[]string{"♠8", "♥6"}, false},
+		{[]string{"♠8", "♥7"}, true},
+		{[]string{"♠A", "♥4"}, true},
+		{[]string{"♠2", "♥3", "♣2", "♦3", "♠2"}, true},
+	}
+	for _, test := range tests {
+		hand := player.ToHand(test.hand)
+		hand.Player = &cardPlayer
+		hand.Wager = 10
+		if got := CanStand(pontoon, hand, tableRules); got != test.stick {
+			t.Errorf("%v stick %t want %t", test.hand, got, test.stick)
+		}
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"errors"
 )
 
-func CanDoubleDown(hand *player.Hand) bool {
-	if hand.Player.Stack < hand.Wager {
+func CanDoubleDown(t *game.Table, hand *player.Hand, tableRules TableRules) bool {
+	if hand.Player.Stack < BuyStake(t, *hand) && !IsFreeDouble(t, *hand) {
 		return false
 	}
 
@@ -24,11 +24,11 @@ func CanDoubleDown(hand *player.Hand) bool {
 		return false
 	}
 
-	if len(game.State.Dealer.Hands) == 1 && len(game.State.Dealer.Hands[0].Cards) > 1 && !game.State.Dealer.Hands[0].Cards[1].Masked {
+	if len(t.State.Dealer.Hands) == 1 && !DealerToPlay(t) {
 		return false
 	}
 
-	if len(hand.Cards) != 2 {
+	if len(hand.Cards) != 2 && !DoublesAnyCards(t, *hand) {
 		return false
 	}
 
@@ -36,6 +36,10 @@ func CanDoubleDown(hand *player.Hand) bool {
 		return false
 	}
 
+	if hand.Split && !tableRules.DoubleAfterSplit {
+		return false
+	}
+
 	if IsBlackjack(*hand) {
 		return false
 
@@ -44,6 +48,10 @@ func CanDoubleDown(hand *player.Hand) bool {
 	softValue := player.HandValue(hand, true)
 	hardValue := player.HandValue(hand, false)
 
+	if tableRules.DoubleNineToEleven {
+		return hardValue >= 9 && hardValue <= 11
+	}
+
 	if softValue < 21 {
 		return hardValue != 21
 	}
@@ -51,7 +59,16 @@ func CanDoubleDown(hand *player.Hand) bool {
 	return false
 }
 
-func CanEvenMoney(hand *player.Hand) bool {
+func CanEvenMoney(t *game.Table, hand *player.Hand, tableRules TableRules) bool {
+	if !ForGame(t.Mode, tableRules).InsuranceAllowed {
+		return false
+	}
+
+	if BlackjackAlwaysWins(t) {
+		// the blackjack is paid in full before the dealer peeks
+		return false
+	}
+
 	if hand.Split {
 		return false
 	}
@@ -64,11 +81,11 @@ func CanEvenMoney(hand *player.Hand) bool {
 		return false
 	}
 
-	if !cards.IsAce(game.State.Dealer.Hands[0].Cards[0]) {
+	if !cards.IsAce(t.State.Dealer.Hands[0].Cards[0]) {
 		return false
 	}
 
-	if !game.State.Dealer.Hands[0].Cards[1].Masked {
+	if !DealerToPlay(t) {
 		return false
 	}
 
@@ -76,16 +93,29 @@ func CanEvenMoney(hand *player.Hand) bool {
 		return false
 	}
 
-	if !IsBlackjack(*hand) {
+	if t.State.Dealer.Hands[0].Peeked {
+		// even money is only offered with insurance, before the dealer peeks
 		return false
 	}
 
-	hardValue := player.HandValue(hand, false)
-	return hardValue != 21
+	return IsBlackjack(*hand)
 }
 
-func CanInsurance(hand *player.Hand) bool {
-	if game.State.Dealer.Hands[0].Cards[0].Value == cards.Ace && !hand.Split {
+func CanInsurance(t *game.Table, hand *player.Hand, tableRules TableRules) bool {
+	if !ForGame(t.Mode, tableRules).InsuranceAllowed {
+		return false
+	}
+
+	if t.State.Dealer.Hands[0].Peeked {
+		// insurance is only sold before the dealer checks for blackjack
+		return false
+	}
+
+	if hand.Player != nil && hand.Player.Stack < hand.Wager/2 {
+		return false
+	}
+
+	if t.State.Dealer.Hands[0].Cards[0].Value == cards.Ace && !hand.Split {
 		if hand.EvenMoney {
 			return false
 		} else {
@@ -96,7 +126,7 @@ func CanInsurance(hand *player.Hand) bool {
 	}
 }
 
-func CanHit(hand *player.Hand) bool {
+func CanHit(t *game.Table, hand *player.Hand, tableRules TableRules) bool {
 	if hand == nil {
 		// todo how is this possible? !@#$@#%@$#%@#$!@
 		return false
@@ -107,11 +137,11 @@ func CanHit(hand *player.Hand) bool {
 	}
 
 	if !IsDealer(*hand.Player) {
-		if CanEvenMoney(hand) {
+		if CanEvenMoney(t, hand, tableRules) {
 			return false
 		}
 
-		if IsBlackjack(game.State.Dealer.Hands[0]) {
+		if IsBlackjack(t.State.Dealer.Hands[0]) {
 			return false
 		}
 
@@ -123,20 +153,30 @@ func CanHit(hand *player.Hand) bool {
 			return false
 		}
 
-		if hand.Cards[0].Value == cards.Ace && hand.Split {
+		if hand.Cards[0].Value == cards.Ace && hand.Split && !tableRules.HitSplitAces {
 			return false
 		}
 
 		if IsBlackjack(*hand) {
 			return false
 		}
+
+		if t.Mode == game.Pontoon && len(hand.Cards) >= FiveCardTrick {
+			// a five card trick is complete
+			return false
+		}
 	}
 
 	softValue := player.HandValue(hand, true)
 	hardValue := player.HandValue(hand, false)
 
 	if IsDealer(*hand.Player) {
-		// stands on hard and soft 17s
+		if tableRules.DealerHitsSoft17 && hardValue == 17 && softValue != hardValue {
+			// hits soft 17s
+			return true
+		}
+
+		// stands on hard 17s
 		return hardValue <= 16
 	} else {
 		if hardValue == 21 {
@@ -146,25 +186,23 @@ func CanHit(hand *player.Hand) bool {
 	}
 }
 
-func CanPlay(playerToTest player.Player, minWager int) bool {
+// CanPlay is whether playerToTest still has a hand to act on. A short stack
+// only rules out the actions that cost more, e.g. doubles and splits.
+func CanPlay(t *game.Table, playerToTest player.Player, tableRules TableRules) bool {
 	activeHand := player.ActiveHand(&playerToTest)
 
 	if activeHand == nil {
 		return false
 	}
 
-	if playerToTest.Stack < minWager {
-		return false
-	}
-
-	if IsBlackjack(game.State.Dealer.Hands[0]) {
+	if IsBlackjack(t.State.Dealer.Hands[0]) {
 		return false
 	}
 
 	if IsDealer(playerToTest) {
-		return CanHit(&playerToTest.Hands[0])
+		return CanHit(t, &playerToTest.Hands[0], tableRules)
 	} else {
-		if !game.State.Dealer.Hands[0].Cards[1].Masked {
+		if !DealerToPlay(t) {
 			return false
 		}
 
@@ -174,7 +212,7 @@ func CanPlay(playerToTest player.Player, minWager int) bool {
 				hand.Player = &playerToTest
 			}
 
-			if CanHit(&hand) || CanEvenMoney(&hand) || CanSplit(hand) || CanStand(hand) || CanInsurance(&hand) {
+			if CanHit(t, &hand, tableRules) || CanEvenMoney(t, &hand, tableRules) || CanSplit(t, hand, tableRules) || CanStand(t, hand, tableRules) || CanInsurance(t, &hand, tableRules) {
 				return true
 			}
 		}
@@ -183,8 +221,43 @@ func CanPlay(playerToTest player.Player, minWager int) bool {
 	}
 }
 
-func CanSplit(hand player.Hand) bool {
-	if hand.Player.Stack < hand.Wager {
+func CanSurrender(t *game.Table, hand *player.Hand, tableRules TableRules) bool {
+	if CanRescue(t, hand) {
+		return true
+	}
+
+	tableRules = ForGame(t.Mode, tableRules)
+	if tableRules.Surrender == NoSurrender {
+		return false
+	}
+
+	if hand.Player == nil || IsDealer(*hand.Player) {
+		return false
+	}
+
+	if hand.Split || hand.Stand || hand.DoubleDown || hand.EvenMoney || hand.Surrendered {
+		return false
+	}
+
+	if len(hand.Cards) != 2 && t.Mode != game.SuperFun21 {
+		// Super Fun 21 surrenders any number of cards
+		return false
+	}
+
+	if !DealerToPlay(t) {
+		return false
+	}
+
+	if tableRules.Surrender == LateSurrender && IsBlackjack(t.State.Dealer.Hands[0]) {
+		// late surrender is only offered once the dealer has checked for blackjack
+		return false
+	}
+
+	return !IsBlackjack(*hand)
+}
+
+func CanSplit(t *game.Table, hand player.Hand, tableRules TableRules) bool {
+	if hand.Player.Stack < hand.Wager && !IsFreeSplit(t, hand) {
 		return false
 	}
 
@@ -196,7 +269,11 @@ func CanSplit(hand player.Hand) bool {
 		return false
 	}
 
-	if cards.IsAce(hand.Cards[0]) && hand.Split {
+	if cards.IsAce(hand.Cards[0]) && hand.Split && !tableRules.ResplitAces {
+		return false
+	}
+
+	if ReachedMaxSplitHands(splitHands(hand), tableRules) {
 		return false
 	}
 
@@ -206,12 +283,24 @@ func CanSplit(hand player.Hand) bool {
 	return pipsAreEqual || areAces
 }
 
-func CanStand(hand player.Hand) bool {
+// splitHands counts the hands that hand's dealt hand has been split into.
+// Each of Blackjack Switch's two dealt hands is split on its own.
+func splitHands(hand player.Hand) int {
+	count := 0
+	for _, playerHand := range hand.Player.Hands {
+		if playerHand.Dealt == hand.Dealt {
+			count += 1
+		}
+	}
+	return count
+}
+
+func CanStand(t *game.Table, hand player.Hand, tableRules TableRules) bool {
 	if hand.Stand {
 		return false
 	}
 
-	if CanEvenMoney(&hand) {
+	if CanEvenMoney(t, &hand, tableRules) {
 		return true
 	}
 
@@ -231,6 +320,10 @@ func CanStand(hand player.Hand) bool {
 		}
 	}
 
+	if MustTwist(t, hand, tableRules) {
+		return false
+	}
+
 	softValue := player.HandValue(&hand, true)
 	hardValue := player.HandValue(&hand, false)
 	if softValue < 21 {
@@ -248,195 +341,70 @@ func CanStand(hand player.Hand) bool {
 	}
 }
 
-func GetAutoPlayPlayerAction(activeHand *player.Hand, dealerFaceUpCard int) (rune, error) {
-	// todo
+// GetAutoPlayPlayerAction plays activeHand by strategyTable, falling back to
+// hitting or standing when the table's double or surrender is not allowed.
+func GetAutoPlayPlayerAction(t *game.Table, activeHand *player.Hand, dealerFaceUpCard int, tableRules TableRules, strategyTable *StrategyTable) (rune, error) {
 	if activeHand == nil || !activeHand.Active {
 		// we don't have an active hand try to stand
 		return 's', nil
 	}
 
-	softValue := player.HandValue(activeHand, true)
-	hardValue := player.HandValue(activeHand, false)
-
-	if !CanSplit(*activeHand) && cards.IsAce(activeHand.Cards[0]) && cards.IsAce(activeHand.Cards[1]) {
-		// todo this is really a defect, we should not be prompting the user for an action at all since they cannot re-split aces
-		return 's', nil
-	}
-
-	if CanSplit(*activeHand) {
-		if cards.IsAce(activeHand.Cards[0]) {
-			return 'p', nil
-		}
-
-		if hardValue == 20 {
-			return 's', nil
-		}
-
-		if hardValue == 18 {
-			if dealerFaceUpCard >= 8 {
-				return 's', nil
-			} else {
-				return 'p', nil
-			}
-		}
-
-		if hardValue == 16 || hardValue == 14 {
-			if dealerFaceUpCard >= 8 {
-				return 'h', nil
-			} else {
-				return 'p', nil
-			}
-		}
-
-		if hardValue == 8 || hardValue == 10 {
-			if dealerFaceUpCard >= 2 && dealerFaceUpCard <= 7 {
-				return 'd', nil
-			} else {
-				return 'h', nil
-			}
-		}
-
-		if dealerFaceUpCard >= 2 && dealerFaceUpCard <= 6 {
-			return 'p', nil
-		} else {
-			return 'h', nil
-		}
-	}
-
-	if hardValue <= 8 {
-		return 'h', nil
+	if strategyTable == nil {
+		return 'q', errors.New("I don't have a strategy table... What should I do?")
 	}
 
-	if hardValue == 9 {
-		if dealerFaceUpCard >= 3 && dealerFaceUpCard <= 6 {
-			if CanDoubleDown(activeHand) {
-				return 'd', nil
-			} else {
-				return 'h', nil
-			}
-		}
-
-		return 'h', nil
+	// free doubles and splits cost nothing, so they are always taken, and a
+	// pair of fives doubled rather than split
+	if IsFreeDouble(t, *activeHand) && CanDoubleDown(t, activeHand, tableRules) {
+		return 'd', nil
 	}
-
-	if hardValue == 10 {
-		if dealerFaceUpCard >= 2 && dealerFaceUpCard <= 9 {
-			if CanDoubleDown(activeHand) {
-				return 'd', nil
-			} else {
-				return 'h', nil
-			}
-		}
-		return 'h', nil
+	if IsFreeSplit(t, *activeHand) && CanSplit(t, *activeHand, tableRules) {
+		return 'p', nil
 	}
 
-	if hardValue == 11 {
-		if CanDoubleDown(activeHand) {
-			return 'd', nil
-		} else {
-			return 'h', nil
-		}
+	decision := StrategyDecision(strategyTable, activeHand, dealerFaceUpCard, CanSplit(t, *activeHand, tableRules))
+	if decision.Action == 'p' {
+		return 'p', nil
 	}
 
-	if hardValue >= 12 && hardValue <= 16 {
-		if dealerFaceUpCard >= 2 && dealerFaceUpCard <= 6 {
-			return 's', nil
-		} else {
-			return 'h', nil
+	if activeHand.DoubleDown {
+		// a Spanish 21 double is rescued where the chart would surrender
+		if decision.Action == 'u' && CanSurrender(t, activeHand, tableRules) {
+			return 'u', nil
 		}
+		return 's', nil
 	}
 
-	if hardValue >= 17 {
+	if !CanHit(t, activeHand, tableRules) {
+		// e.g. a split ace that may not be hit, asking for a card would never end the hand
 		return 's', nil
 	}
 
-	if softValue != hardValue {
-		if softValue <= 8 {
-			if dealerFaceUpCard <= 6 {
-				if CanDoubleDown(activeHand) {
-					return 'd', nil
-				} else {
-					return 'h', nil
-				}
-			} else {
-				if softValue >= 8 {
-					return 's', nil
-				} else {
-					return 'h', nil
-				}
-			}
+	action := decision.Action
+	switch decision.Action {
+	case 'd':
+		if !CanDoubleDown(t, activeHand, tableRules) {
+			action = decision.Fallback
+		}
+	case 'u':
+		if !CanSurrender(t, activeHand, tableRules) {
+			action = decision.Fallback
 		}
 	}
 
-	// if canInsurance(activeHand) {
-	// // always say no to insurance
-	// return 'n', nil
-	// }
-
-	// if canEvenMoney(activeHand) {
-	// // always take even money
-	// return 'e', nil
-	// }
-
-	// if canDoubleDown(activeHand) {
-	// if (dealerFaceUpCard >= 3 && dealerFaceUpCard <= 6) || (softValue > dealerFaceUpCard && softValue <= 11) {
-	// // assume these are bust cards for the dealer
-	// if softValue <= 11 && softValue >= 8 {
-	// return 'd', nil
-	// }
-	// } else if softValue == 11 {
-	// // always double down on 11
-	// return 'd', nil
-	// }
-	// }
-
-	// if canStand(*activeHand) {
-	// if hardValue >= 17 && dealerFaceUpCard <= 7 {
-	// return 's', nil
-	// }
-	// }
-
-	// // if we get to this point and canSplit, why not? more money on the table
-	// if canSplit(*activeHand) {
-	// if hardValue <= 16 && hardValue >= 12 {
-	// return 'p', nil
-	// }
-	// }
-
-	// // if we can hit at this point, hit...
-	// if canHit(activeHand) {
-	// if hardValue >= 17 {
-	// // we don't want to hit a 17 or more
-	// return 's', nil
-	// }
-
-	// log.Printf("Hitting...")
-	// if (dealerFaceUpCard >= 3 && dealerFaceUpCard <= 5 && hardValue <= 14) || dealerFaceUpCard >= 10 {
-	// // if hardValue == 14 || hardValue == 15 || hardValue == 16 {
-	// // hit a 14,15,16? really?
-	// // return 's', nil
-	// // }
-	// // do we need some rudimentary card counting?
-	// return 'h', nil
-	// }
-
-	// if hardValue < 11 {
-	// if canDoubleDown(activeHand) {
-	// // sure, why not? more money on the table?
-	// return 'd', nil
-	// }
-	// }
-
-	// return 'h', nil
-	// } else {
-	// return 's', nil
-	// }
-
-	// otherwise, we didn't understand
-	return 'q', errors.New("I didn't understand... What should I do?")
+	if action == 's' && MustTwist(t, *activeHand, tableRules) {
+		// a Pontoon hand under 15 may not stick
+		return 'h', nil
+	}
+	return action, nil
 }
 
 func IsBlackjack(hand player.Hand) bool {
+	if hand.Switched {
+		// a Blackjack Switch 21 made by switching is only 21
+		return false
+	}
+
 	if !hand.Split {
 		if len(hand.Cards) == 2 {
 			if player.HandValue(&hand, false) == 21 {
@@ -451,7 +419,7 @@ func IsBlackjack(hand player.Hand) bool {
 					masked := hand.Cards[1].Masked
 					hand.Cards[1].Masked = false
 
-					if cards.CardToValue(hand.Cards[0], false)+cards.CardToValue(game.State.Dealer.Hands[0].Cards[1], false) == 21 {
+					if cards.CardToValue(hand.Cards[0], false)+cards.CardToValue(hand.Cards[1], false) == 21 {
 						hand.Cards[1].Masked = masked
 						return true
 					} else {
@@ -549,10 +517,18 @@ func IsStraightAcesLow(hand player.Hand) bool {
 
 	sortedHand := player.SortHand(hand, false)
 
+	// aces count one here, below the two
+	pips := func(card cards.Card) int {
+		if cards.IsAce(card) {
+			return 1
+		}
+		return cards.CardToPips(card)
+	}
+
 	isStraight := true
 	for i := 0; i < len(sortedHand.Cards); i++ {
 		if i > 0 {
-			isStraight = isStraight && cards.CardToPips(sortedHand.Cards[i-1])+1 == cards.CardToPips(sortedHand.Cards[i])
+			isStraight = isStraight && pips(sortedHand.Cards[i-1])+1 == pips(sortedHand.Cards[i])
 		}
 		if !isStraight {
 			break
@@ -16,6 +16,7 @@ import (
 )
 
 var shortSampleSize = 1000
+var table = game.NewTable(game.Blackjack, 0)
 var cfg = flags.FromFlags()
 var useGlyphs = &cfg.UseGlyphs
 var colorTerminal = false
@@ -23,8 +24,8 @@ var playerStartStack = &cfg.PlayerStartStack
 var minWager = &cfg.MinWager
 
 func init() {
-	game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-	game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(2), Player: &game.State.Dealer})
+	table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+	table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &table.State.Dealer})
 }
 
 func CardsAreOrdered(hand player.Hand, acesLow bool) bool {
@@ -61,50 +62,50 @@ func suitesAreEqual(hand player.Hand) bool {
 
 func TestCanDoubleDown(t *testing.T) {
 	testHand := func(hand player.Hand) {
-		game.State.Dealer.Hands[0].Cards[1].Masked = false
-		if rules.CanDoubleDown(&hand) {
-			t.Fatalf(`rules.CanDoubleDown(%s) = %t [fail], want match for %t (second dealer cards.Card is not masked)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), false)
+		table.State.Dealer.Hands[0].Cards[1].Masked = false
+		if rules.CanDoubleDown(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanDoubleDown(table, %s) = %t [fail], want match for %t (second dealer cards.Card is not masked)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), false)
 		} else {
-			t.Logf(`rules.CanDoubleDown(%s) = %t [pass] (second dealer cards.Card is not masked)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand))
+			t.Logf(`rules.CanDoubleDown(table, %s) = %t [pass] (second dealer cards.Card is not masked)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules))
 		}
-		game.State.Dealer.Hands[0].Cards[1].Masked = true
+		table.State.Dealer.Hands[0].Cards[1].Masked = true
 
-		if rules.CanDoubleDown(&hand) && rules.IsBlackjack(hand) {
-			t.Fatalf(`rules.CanDoubleDown(%s) = %t [fail], want match for %t (rules.IsBlackjack = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), rules.IsBlackjack(hand), rules.IsBlackjack(hand))
+		if rules.CanDoubleDown(table, &hand, cfg.TableRules) && rules.IsBlackjack(hand) {
+			t.Fatalf(`rules.CanDoubleDown(table, %s) = %t [fail], want match for %t (rules.IsBlackjack = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), rules.IsBlackjack(hand), rules.IsBlackjack(hand))
 		} else {
-			t.Logf(`rules.CanDoubleDown(%s) = %t [pass] (rules.IsBlackjack = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), rules.IsBlackjack(hand))
+			t.Logf(`rules.CanDoubleDown(table, %s) = %t [pass] (rules.IsBlackjack = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), rules.IsBlackjack(hand))
 		}
 
 		hand.Stand = true
-		if rules.CanDoubleDown(&hand) {
-			t.Fatalf(`rules.CanDoubleDown(%s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), false, hand.Stand)
+		if rules.CanDoubleDown(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanDoubleDown(table, %s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), false, hand.Stand)
 		} else {
-			t.Logf(`rules.CanDoubleDown(%s) = %t [pass] (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), hand.Stand)
+			t.Logf(`rules.CanDoubleDown(table, %s) = %t [pass] (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), hand.Stand)
 		}
 		hand.Stand = false
 
 		hand.EvenMoney = true
-		if rules.CanDoubleDown(&hand) {
-			t.Fatalf(`rules.CanDoubleDown(%s) = %t [fail], want match for %t (EvenMoney = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), false, hand.EvenMoney)
+		if rules.CanDoubleDown(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanDoubleDown(table, %s) = %t [fail], want match for %t (EvenMoney = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), false, hand.EvenMoney)
 		} else {
-			t.Logf(`rules.CanDoubleDown(%s) = %t [pass] (EvenMoney = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), hand.EvenMoney)
+			t.Logf(`rules.CanDoubleDown(table, %s) = %t [pass] (EvenMoney = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), hand.EvenMoney)
 		}
 		hand.EvenMoney = false
 
 		hand.Split = true
 		softValue := player.HandValue(&hand, true)
-		if !rules.CanDoubleDown(&hand) && (softValue >= 8 && softValue <= 11) && !cards.IsAce(hand.Cards[0]) {
-			t.Fatalf(`rules.CanDoubleDown(%s) = %t [fail], want match for %t (Split = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), true, hand.Split)
+		if !rules.CanDoubleDown(table, &hand, cfg.TableRules) && (softValue >= 8 && softValue <= 11) && !cards.IsAce(hand.Cards[0]) {
+			t.Fatalf(`rules.CanDoubleDown(table, %s) = %t [fail], want match for %t (Split = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), true, hand.Split)
 		} else {
-			t.Logf(`rules.CanDoubleDown(%s) = %t [pass] (Split = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), hand.Split)
+			t.Logf(`rules.CanDoubleDown(table, %s) = %t [pass] (Split = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), hand.Split)
 		}
 		hand.Split = false
 
 		hand.DoubleDown = true
-		if rules.CanDoubleDown(&hand) {
-			t.Fatalf(`rules.CanDoubleDown(%s) = %t [fail], want match for %t (DoubleDown = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), false, hand.DoubleDown)
+		if rules.CanDoubleDown(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanDoubleDown(table, %s) = %t [fail], want match for %t (DoubleDown = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), false, hand.DoubleDown)
 		} else {
-			t.Logf(`rules.CanDoubleDown(%s) = %t [pass] (DoubleDown = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), hand.DoubleDown)
+			t.Logf(`rules.CanDoubleDown(table, %s) = %t [pass] (DoubleDown = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), hand.DoubleDown)
 		}
 		hand.DoubleDown = false
 	}
@@ -113,11 +114,11 @@ func TestCanDoubleDown(t *testing.T) {
 		// in short mode, just try a sample
 		for i := 0; i < shortSampleSize; i++ {
 
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-			game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(2), Player: &game.State.Dealer})
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &table.State.Dealer})
 
-			cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
-			hand := player.Hand{Cards: random.RandomCardSlice(2), Player: &cardPlayer}
+			cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &cardPlayer}
 
 			testHand(hand)
 		}
@@ -125,17 +126,17 @@ func TestCanDoubleDown(t *testing.T) {
 		// in long mode, run for all possible combinations
 		cards.ForAllCards(func(theCard cards.Card) {
 			// for every combination of first dealer cards.Card
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-			game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: make([]cards.Card, 0), Player: &game.State.Dealer})
-			game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, theCard)
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: make([]cards.Card, 0), Player: &table.State.Dealer})
+			table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, theCard)
 
 			cards.ForAllCards(func(theCard cards.Card) {
 				// for every combination of second dealer cards.Card (Masked = true)
 				theCard.Masked = true
-				if len(game.State.Dealer.Hands[0].Cards) == 2 {
-					game.State.Dealer.Hands[0].Cards[1] = theCard
+				if len(table.State.Dealer.Hands[0].Cards) == 2 {
+					table.State.Dealer.Hands[0].Cards[1] = theCard
 				} else {
-					game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, theCard)
+					table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, theCard)
 				}
 
 				cards.ForAllCards(func(theCard cards.Card) {
@@ -143,10 +144,10 @@ func TestCanDoubleDown(t *testing.T) {
 					hand := player.Hand{Cards: make([]cards.Card, 0), Player: &player.Player{}}
 					hand.Cards = append(hand.Cards, theCard)
 
-					if rules.CanDoubleDown(&hand) {
-						t.Fatalf(`rules.CanDoubleDown(%s) = %t [fail], want match for %t (len(Cards) < 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand), false)
+					if rules.CanDoubleDown(table, &hand, cfg.TableRules) {
+						t.Fatalf(`rules.CanDoubleDown(table, %s) = %t [fail], want match for %t (len(Cards) < 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules), false)
 					} else {
-						t.Logf(`rules.CanDoubleDown(%s) = %t [pass] (len(Cards) < 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(&hand))
+						t.Logf(`rules.CanDoubleDown(table, %s) = %t [pass] (len(Cards) < 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanDoubleDown(table, &hand, cfg.TableRules))
 					}
 
 					cards.ForAllCards(func(theCard cards.Card) {
@@ -167,41 +168,41 @@ func TestCanDoubleDown(t *testing.T) {
 
 func TestCanEvenMoney(t *testing.T) {
 	testHand := func(hand player.Hand) {
-		if rules.CanEvenMoney(&hand) && len(hand.Cards) != 2 {
-			t.Fatalf(`rules.CanEvenMoney(%s) = %t [fail], want match for %t (len(Cards) != 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand), false)
+		if rules.CanEvenMoney(table, &hand, cfg.TableRules) && len(hand.Cards) != 2 {
+			t.Fatalf(`rules.CanEvenMoney(table, %s) = %t [fail], want match for %t (len(Cards) != 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules), false)
 		} else {
-			t.Logf(`rules.CanEvenMoney(%s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand))
+			t.Logf(`rules.CanEvenMoney(table, %s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules))
 		}
 
-		if rules.CanEvenMoney(&hand) && !cards.IsAce(game.State.Dealer.Hands[0].Cards[0]) {
-			t.Fatalf(`rules.CanEvenMoney(%s) = %t [fail], want match for %t (first dealer cards.Card is not an ace)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand), false)
+		if rules.CanEvenMoney(table, &hand, cfg.TableRules) && !cards.IsAce(table.State.Dealer.Hands[0].Cards[0]) {
+			t.Fatalf(`rules.CanEvenMoney(table, %s) = %t [fail], want match for %t (first dealer cards.Card is not an ace)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules), false)
 		}
 
-		game.State.Dealer.Hands[0].Cards[1].Masked = false
-		if rules.CanEvenMoney(&hand) {
-			t.Fatalf(`rules.CanEvenMoney(%s) = %t [fail], want match for %t (second dealer cards.Card is not masked)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand), false)
+		table.State.Dealer.Hands[0].Cards[1].Masked = false
+		if rules.CanEvenMoney(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanEvenMoney(table, %s) = %t [fail], want match for %t (second dealer cards.Card is not masked)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules), false)
 		}
-		game.State.Dealer.Hands[0].Cards[1].Masked = true
+		table.State.Dealer.Hands[0].Cards[1].Masked = true
 
-		if rules.CanEvenMoney(&hand) && !rules.IsBlackjack(hand) {
-			t.Fatalf(`rules.CanEvenMoney(%s) = %t [fail], want match for %t (rules.IsBlackjack = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand), rules.IsBlackjack(hand), rules.IsBlackjack(hand))
+		if rules.CanEvenMoney(table, &hand, cfg.TableRules) && !rules.IsBlackjack(hand) {
+			t.Fatalf(`rules.CanEvenMoney(table, %s) = %t [fail], want match for %t (rules.IsBlackjack = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules), rules.IsBlackjack(hand), rules.IsBlackjack(hand))
 		}
 
 		hand.Stand = true
-		if rules.CanEvenMoney(&hand) {
-			t.Fatalf(`rules.CanEvenMoney(%s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand), false, hand.Stand)
+		if rules.CanEvenMoney(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanEvenMoney(table, %s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules), false, hand.Stand)
 		}
 		hand.Stand = false
 
 		hand.EvenMoney = true
-		if rules.CanEvenMoney(&hand) {
-			t.Fatalf(`rules.CanEvenMoney(%s) = %t [fail], want match for %t (EvenMoney = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand), false, hand.EvenMoney)
+		if rules.CanEvenMoney(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanEvenMoney(table, %s) = %t [fail], want match for %t (EvenMoney = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules), false, hand.EvenMoney)
 		}
 		hand.EvenMoney = false
 
 		hand.Split = true
-		if rules.CanEvenMoney(&hand) {
-			t.Fatalf(`rules.CanEvenMoney(%s) = %t [fail], want match for %t (Split = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(&hand), false, hand.Split)
+		if rules.CanEvenMoney(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanEvenMoney(table, %s) = %t [fail], want match for %t (Split = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanEvenMoney(table, &hand, cfg.TableRules), false, hand.Split)
 		}
 		hand.Split = false
 	}
@@ -209,31 +210,31 @@ func TestCanEvenMoney(t *testing.T) {
 	if testing.Short() {
 		for i := 0; i < shortSampleSize; i++ {
 
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-			game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(2), Player: &game.State.Dealer})
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &table.State.Dealer})
 
-			cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
-			die1 := utils.RollDice() / 4
+			cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
+			die1 := utils.RollDice(table.Rand) / 4
 
-			hand := player.Hand{Cards: random.RandomCardSlice(die1), Player: &cardPlayer}
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, die1), Player: &cardPlayer}
 
 			testHand(hand)
 		}
 	} else {
 		cards.ForAllCards(func(dealerCard1 cards.Card) {
 			// for every combination of first dealer cards.Card
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
 
 			cards.ForAllCards(func(dealerCard2 cards.Card) {
-				game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &game.State.Dealer})
-				game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard1)
+				table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &table.State.Dealer})
+				table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard1)
 
 				// for every combination of second dealer cards.Card (Masked = true)
 				dealerCard2.Masked = true
-				if len(game.State.Dealer.Hands[0].Cards) == 2 {
-					game.State.Dealer.Hands[0].Cards[1] = dealerCard2
+				if len(table.State.Dealer.Hands[0].Cards) == 2 {
+					table.State.Dealer.Hands[0].Cards[1] = dealerCard2
 				} else {
-					game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard2)
+					table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard2)
 				}
 
 				cards.ForAllCards(func(theCard1 cards.Card) {
@@ -262,76 +263,76 @@ func TestCanEvenMoney(t *testing.T) {
 
 func TestCanHit(t *testing.T) {
 	testHand := func(hand player.Hand) {
-		if rules.CanHit(&hand) && rules.CanEvenMoney(&hand) {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (rules.CanEvenMoney == %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand), rules.CanEvenMoney(&hand), true)
+		if rules.CanHit(table, &hand, cfg.TableRules) && rules.CanEvenMoney(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (rules.CanEvenMoney == %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules), rules.CanEvenMoney(table, &hand, cfg.TableRules), true)
 		}
 
-		if rules.CanHit(&hand) && rules.IsBlackjack(game.State.Dealer.Hands[0]) {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (dealer has blackjack!)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand), false)
+		if rules.CanHit(table, &hand, cfg.TableRules) && rules.IsBlackjack(table.State.Dealer.Hands[0]) {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (dealer has blackjack!)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules), false)
 		}
 
-		if rules.CanHit(&hand) && rules.IsBlackjack(hand) {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (player has blackjack!)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand), false)
+		if rules.CanHit(table, &hand, cfg.TableRules) && rules.IsBlackjack(hand) {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (player has blackjack!)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules), false)
 		}
 
 		hand.Split = true
-		if rules.CanHit(&hand) && (hand.Cards[0].Value == cards.Ace && hand.Split) {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (we split an ace!)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand), false)
+		if rules.CanHit(table, &hand, cfg.TableRules) && (hand.Cards[0].Value == cards.Ace && hand.Split) {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (we split an ace!)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules), false)
 		}
 		hand.Split = false
 
 		softValue := player.HandValue(&hand, true)
-		if !rules.CanHit(&hand) && softValue < 21 && len(hand.Cards) >= 2 && !rules.IsBlackjack(hand) && !rules.CanEvenMoney(&hand) && !rules.IsBlackjack(game.State.Dealer.Hands[0]) {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (softValue < 21 and player != Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand), true)
+		if !rules.CanHit(table, &hand, cfg.TableRules) && softValue < 21 && len(hand.Cards) >= 2 && !rules.IsBlackjack(hand) && !rules.CanEvenMoney(table, &hand, cfg.TableRules) && !rules.IsBlackjack(table.State.Dealer.Hands[0]) {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (softValue < 21 and player != Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules), true)
 		}
 
-		hardValue := player.HandValue(&game.State.Dealer.Hands[0], false)
-		if rules.CanHit(&game.State.Dealer.Hands[0]) && hardValue >= 17 {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (softValue < 21 and player == Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&game.State.Dealer.Hands[0]), false)
+		hardValue := player.HandValue(&table.State.Dealer.Hands[0], false)
+		if rules.CanHit(table, &table.State.Dealer.Hands[0], cfg.TableRules) && hardValue >= 17 {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (softValue < 21 and player == Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &table.State.Dealer.Hands[0], cfg.TableRules), false)
 		}
 
 		hand.DoubleDown = true
-		if rules.CanHit(&hand) {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (DoubleDown = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand), false, hand.DoubleDown)
+		if rules.CanHit(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (DoubleDown = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules), false, hand.DoubleDown)
 		}
 		hand.DoubleDown = false
 
 		hand.Stand = true
-		if rules.CanHit(&hand) {
-			t.Fatalf(`rules.CanHit(%s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand), false, hand.Stand)
+		if rules.CanHit(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanHit(table, %s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules), false, hand.Stand)
 		}
 		hand.Stand = false
 
-		t.Logf(`rules.CanHit(%s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(&hand))
+		t.Logf(`rules.CanHit(table, %s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanHit(table, &hand, cfg.TableRules))
 	}
 
 	if testing.Short() {
 		for i := 0; i < shortSampleSize; i++ {
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-			game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(2), Player: &game.State.Dealer})
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &table.State.Dealer})
 
-			cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
-			die1 := utils.RollDice()
+			cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
+			die1 := utils.RollDice(table.Rand)
 
-			hand := player.Hand{Cards: random.RandomCardSlice(die1), Player: &cardPlayer}
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, die1), Player: &cardPlayer}
 
 			testHand(hand)
 		}
 	} else {
 		cards.ForAllCards(func(dealerCard1 cards.Card) {
 			// for every combination of first dealer cards.Card
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
 
 			cards.ForAllCards(func(dealerCard2 cards.Card) {
-				game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &game.State.Dealer})
-				game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard1)
+				table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &table.State.Dealer})
+				table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard1)
 
 				// for every combination of second dealer cards.Card (Masked = true)
 				dealerCard2.Masked = true
-				if len(game.State.Dealer.Hands[0].Cards) == 2 {
-					game.State.Dealer.Hands[0].Cards[1] = dealerCard2
+				if len(table.State.Dealer.Hands[0].Cards) == 2 {
+					table.State.Dealer.Hands[0].Cards[1] = dealerCard2
 				} else {
-					game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard2)
+					table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard2)
 				}
 
 				cards.ForAllCards(func(theCard1 cards.Card) {
@@ -368,54 +369,54 @@ func TestCanHit(t *testing.T) {
 func TestCanSplit(t *testing.T) {
 	testHand := func(hand player.Hand) {
 		hand.Stand = true
-		if rules.CanSplit(hand) {
-			t.Fatalf(`rules.CanSplit(%s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(hand), false, hand.Stand)
+		if rules.CanSplit(table, hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanSplit(table, %s) = %t [fail], want match for %t (Stand = %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(table, hand, cfg.TableRules), false, hand.Stand)
 		}
 		hand.Stand = false
 
-		if rules.CanSplit(hand) && len(hand.Cards) != 2 {
-			t.Fatalf(`rules.CanSplit(%s) = %t [fail], want match for %t (len(Cards) != 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(hand), false)
+		if rules.CanSplit(table, hand, cfg.TableRules) && len(hand.Cards) != 2 {
+			t.Fatalf(`rules.CanSplit(table, %s) = %t [fail], want match for %t (len(Cards) != 2)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(table, hand, cfg.TableRules), false)
 		}
 
 		if len(hand.Cards) == 2 {
 			pipsAreEqual := cards.CardToPips(hand.Cards[0]) == cards.CardToPips(hand.Cards[1])
 			areAces := cards.IsAce(hand.Cards[0]) && cards.IsAce(hand.Cards[1])
 
-			if rules.CanSplit(hand) && !(pipsAreEqual || areAces) {
-				t.Fatalf(`rules.CanSplit(%s) = %t [fail], want match for %t (pips must be equal)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(hand), pipsAreEqual || areAces)
+			if rules.CanSplit(table, hand, cfg.TableRules) && !(pipsAreEqual || areAces) {
+				t.Fatalf(`rules.CanSplit(table, %s) = %t [fail], want match for %t (pips must be equal)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(table, hand, cfg.TableRules), pipsAreEqual || areAces)
 			}
 		}
 
-		t.Logf(`rules.CanSplit(%s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(hand))
+		t.Logf(`rules.CanSplit(table, %s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanSplit(table, hand, cfg.TableRules))
 	}
 
 	if testing.Short() {
 		for i := 0; i < shortSampleSize; i++ {
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-			game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(2), Player: &game.State.Dealer})
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &table.State.Dealer})
 
-			cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
-			die1 := utils.RollDice() / 2
+			cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
+			die1 := utils.RollDice(table.Rand) / 2
 
-			hand := player.Hand{Cards: random.RandomCardSlice(die1), Player: &cardPlayer}
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, die1), Player: &cardPlayer}
 
 			testHand(hand)
 		}
 	} else {
 		cards.ForAllCards(func(dealerCard1 cards.Card) {
 			// for every combination of first dealer cards.Card
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
 
 			cards.ForAllCards(func(dealerCard2 cards.Card) {
-				game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &game.State.Dealer})
-				game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard1)
+				table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &table.State.Dealer})
+				table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard1)
 
 				// for every combination of second dealer cards.Card (Masked = true)
 				dealerCard2.Masked = true
-				if len(game.State.Dealer.Hands[0].Cards) == 2 {
-					game.State.Dealer.Hands[0].Cards[1] = dealerCard2
+				if len(table.State.Dealer.Hands[0].Cards) == 2 {
+					table.State.Dealer.Hands[0].Cards[1] = dealerCard2
 				} else {
-					game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard2)
+					table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard2)
 				}
 
 				cards.ForAllCards(func(theCard1 cards.Card) {
@@ -444,52 +445,52 @@ func TestCanSplit(t *testing.T) {
 
 func TestCanStand(t *testing.T) {
 	testHand := func(hand player.Hand) {
-		if !rules.CanStand(hand) && rules.CanEvenMoney(&hand) {
-			t.Fatalf(`rules.CanStand(%s) = %t [fail], want match for %t (rules.CanEvenMoney == %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(hand), rules.CanEvenMoney(&hand), true)
+		if !rules.CanStand(table, hand, cfg.TableRules) && rules.CanEvenMoney(table, &hand, cfg.TableRules) {
+			t.Fatalf(`rules.CanStand(table, %s) = %t [fail], want match for %t (rules.CanEvenMoney == %t)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(table, hand, cfg.TableRules), rules.CanEvenMoney(table, &hand, cfg.TableRules), true)
 		}
 
 		softValue := player.HandValue(&hand, true)
 
-		if !rules.CanStand(hand) && softValue < 21 {
-			t.Fatalf(`rules.CanStand(%s) = %t [fail], want match for %t (softValue < 21 and player != Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(hand), true)
+		if !rules.CanStand(table, hand, cfg.TableRules) && softValue < 21 {
+			t.Fatalf(`rules.CanStand(table, %s) = %t [fail], want match for %t (softValue < 21 and player != Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(table, hand, cfg.TableRules), true)
 		}
 
 		hand.Player.Dealer = true
-		if !rules.CanStand(hand) && (softValue >= 17 && softValue < 21) {
-			t.Fatalf(`rules.CanStand(%s) = %t [fail], want match for %t (softValue >= 17 and player == Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(hand), true)
+		if !rules.CanStand(table, hand, cfg.TableRules) && (softValue >= 17 && softValue < 21) {
+			t.Fatalf(`rules.CanStand(table, %s) = %t [fail], want match for %t (softValue >= 17 and player == Dealer)`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(table, hand, cfg.TableRules), true)
 		}
 		hand.Player.Dealer = false
 
-		t.Logf(`rules.CanStand(%s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(hand))
+		t.Logf(`rules.CanStand(table, %s) = %t [pass]`, player.HandToString(hand, *useGlyphs, colorTerminal), rules.CanStand(table, hand, cfg.TableRules))
 	}
 
 	if testing.Short() {
 		for i := 0; i < shortSampleSize; i++ {
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-			game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(2), Player: &game.State.Dealer})
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &table.State.Dealer})
 
-			cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
-			die1 := utils.RollDice() / 2
+			cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
+			die1 := utils.RollDice(table.Rand) / 2
 
-			hand := player.Hand{Cards: random.RandomCardSlice(die1), Player: &cardPlayer}
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, die1), Player: &cardPlayer}
 
 			testHand(hand)
 		}
 	} else {
 		cards.ForAllCards(func(dealerCard1 cards.Card) {
 			// for every combination of first dealer cards.Card
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
 
 			cards.ForAllCards(func(dealerCard2 cards.Card) {
-				game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &game.State.Dealer})
-				game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard1)
+				table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &table.State.Dealer})
+				table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard1)
 
 				// for every combination of second dealer cards.Card (Masked = true)
 				dealerCard2.Masked = true
-				if len(game.State.Dealer.Hands[0].Cards) == 2 {
-					game.State.Dealer.Hands[0].Cards[1] = dealerCard2
+				if len(table.State.Dealer.Hands[0].Cards) == 2 {
+					table.State.Dealer.Hands[0].Cards[1] = dealerCard2
 				} else {
-					game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard2)
+					table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard2)
 				}
 
 				cards.ForAllCards(func(theCard1 cards.Card) {
@@ -525,7 +526,7 @@ func TestCanStand(t *testing.T) {
 
 func TestCreatePlayer(t *testing.T) {
 	for i := 0; i < 100; i++ {
-		cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
+		cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
 
 		if cardPlayer.Dealer {
 			t.Fatalf(`cardPlayer.Dealer = %t [fail], want match for %t`, cardPlayer.Dealer, false)
@@ -549,7 +550,7 @@ func TestCreatePlayer(t *testing.T) {
 
 func TestDrawHand(t *testing.T) {
 	testHand := func(hand player.Hand) {
-		terminal.DrawHand(game.State.Dealer.Hands[0], colorTerminal)
+		terminal.DrawHand(table.State.Dealer.Hands[0], colorTerminal)
 
 		t.Logf(`terminal.DrawHand(%s) [exercised] (Dealer = true)`, player.HandToString(hand, *useGlyphs, colorTerminal))
 
@@ -566,37 +567,37 @@ func TestDrawHand(t *testing.T) {
 
 	if testing.Short() {
 		for i := 0; i < shortSampleSize; i++ {
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
-			game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(2), Player: &game.State.Dealer})
-			game.State.Dealer.Hands[0].Cards[1].Masked = true
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: random.RandomCardSlice(table.Rand, 2), Player: &table.State.Dealer})
+			table.State.Dealer.Hands[0].Cards[1].Masked = true
 
-			testHand(game.State.Dealer.Hands[0])
+			testHand(table.State.Dealer.Hands[0])
 
-			cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
-			die1 := utils.RollDice()
+			cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
+			die1 := utils.RollDice(table.Rand)
 			if die1 == 6 {
 				die1 = 5
 			}
 
-			hand := player.Hand{Cards: random.RandomCardSlice(die1), Player: &cardPlayer}
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, die1), Player: &cardPlayer}
 
 			testHand(hand)
 		}
 	} else {
 		cards.ForAllCards(func(dealerCard1 cards.Card) {
 			// for every combination of first dealer cards.Card
-			game.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
+			table.State.Dealer = player.Player{Dealer: true, Hands: make([]player.Hand, 0)}
 
 			cards.ForAllCards(func(dealerCard2 cards.Card) {
-				game.State.Dealer.Hands = append(game.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &game.State.Dealer})
-				game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard1)
+				table.State.Dealer.Hands = append(table.State.Dealer.Hands, player.Hand{Cards: []cards.Card{dealerCard1, dealerCard2}, Player: &table.State.Dealer})
+				table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard1)
 
 				// for every combination of second dealer cards.Card (Masked = true)
 				dealerCard2.Masked = true
-				if len(game.State.Dealer.Hands[0].Cards) == 2 {
-					game.State.Dealer.Hands[0].Cards[1] = dealerCard2
+				if len(table.State.Dealer.Hands[0].Cards) == 2 {
+					table.State.Dealer.Hands[0].Cards[1] = dealerCard2
 				} else {
-					game.State.Dealer.Hands[0].Cards = append(game.State.Dealer.Hands[0].Cards, dealerCard2)
+					table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, dealerCard2)
 				}
 
 				cards.ForAllCards(func(theCard1 cards.Card) {
@@ -652,16 +653,16 @@ func TestIsFlush(t *testing.T) {
 		}
 	}
 
-	cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
+	cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
 
 	if testing.Short() {
 		for i := 0; i < shortSampleSize; i++ {
-			die1 := utils.RollDice() / 2
+			die1 := utils.RollDice(table.Rand) / 2
 			if die1 == 6 {
 				die1 = 5
 			}
 
-			hand := player.Hand{Cards: random.RandomCardSlice(die1), Player: &cardPlayer}
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, die1), Player: &cardPlayer}
 
 			testHand(hand)
 		}
@@ -708,16 +709,16 @@ func TestIsStraight(t *testing.T) {
 		}
 	}
 
-	cardPlayer := player.CreatePlayer(*playerStartStack, *minWager)
+	cardPlayer := player.CreatePlayer(table.Rand, *playerStartStack, *minWager)
 
 	if testing.Short() {
 		for i := 0; i < shortSampleSize; i++ {
-			die1 := utils.RollDice() / 2
+			die1 := utils.RollDice(table.Rand) / 2
 			if die1 == 6 {
 				die1 = 5
 			}
 
-			hand := player.Hand{Cards: random.RandomCardSlice(die1), Player: &cardPlayer}
+			hand := player.Hand{Cards: random.RandomCardSlice(table.Rand, die1), Player: &cardPlayer}
 
 			testHand(hand)
 		}
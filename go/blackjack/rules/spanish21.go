@@ -0,0 +1,119 @@
+package rules
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// Spanish 21 bonuses, paid on a winning 21 in place of even money. None are
+// paid on doubled hands.
+var (
+	FiveCard21Payout  = Payout{Win: 3, Bet: 2}
+	SixCard21Payout   = Payout{Win: 2, Bet: 1}
+	SevenCard21Payout = Payout{Win: 3, Bet: 1}
+	// 6-7-8 or 7-7-7 in mixed suits, one suit or all spades
+	MixedBonusPayout  = Payout{Win: 3, Bet: 2}
+	SuitedBonusPayout = Payout{Win: 2, Bet: 1}
+	SpadedBonusPayout = Payout{Win: 3, Bet: 1}
+)
+
+// The super bonus pays a flat amount on suited 7-7-7 against a dealer 7,
+// SuperBonusLarge once the wager is at least SuperBonusWager.
+const (
+	SuperBonus      = 1000
+	SuperBonusLarge = 5000
+	SuperBonusWager = 25
+)
+
+// IsSixSevenEight is a three card 6-7-8 in any order.
+func IsSixSevenEight(hand player.Hand) bool {
+	if len(hand.Cards) != 3 {
+		return false
+	}
+
+	seen := make(map[cards.CardValue]bool)
+	for _, card := range hand.Cards {
+		seen[card.Value] = true
+	}
+	return seen[cards.Six] && seen[cards.Seven] && seen[cards.Eight]
+}
+
+// IsSevenSevenSeven is a three card 7-7-7.
+func IsSevenSevenSeven(hand player.Hand) bool {
+	if len(hand.Cards) != 3 {
+		return false
+	}
+
+	for _, card := range hand.Cards {
+		if card.Value != cards.Seven {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSpades is a hand of nothing but spades.
+func IsSpades(hand player.Hand) bool {
+	return IsFlush(hand) && hand.Cards[0].Suite == cards.Spades
+}
+
+// Spanish21Winnings is what a winning 21 earns in Spanish 21 on top of the
+// returned wager: its bonus, or even money when it has none.
+func Spanish21Winnings(t *game.Table, hand player.Hand) int {
+	if hand.DoubleDown {
+		return hand.Wager
+	}
+
+	if IsSevenSevenSeven(hand) || IsSixSevenEight(hand) {
+		if IsSevenSevenSeven(hand) && IsFlush(hand) && !hand.Split && dealerUpCardIs(t, cards.Seven) {
+			if hand.Wager >= SuperBonusWager {
+				return SuperBonusLarge
+			}
+			return SuperBonus
+		}
+
+		switch {
+		case IsSpades(hand):
+			return SpadedBonusPayout.Winnings(hand.Wager)
+		case IsFlush(hand):
+			return SuitedBonusPayout.Winnings(hand.Wager)
+		default:
+			return MixedBonusPayout.Winnings(hand.Wager)
+		}
+	}
+
+	switch {
+	case len(hand.Cards) >= 7:
+		return SevenCard21Payout.Winnings(hand.Wager)
+	case len(hand.Cards) == 6:
+		return SixCard21Payout.Winnings(hand.Wager)
+	case len(hand.Cards) == 5:
+		return FiveCard21Payout.Winnings(hand.Wager)
+	}
+
+	return hand.Wager
+}
+
+func dealerUpCardIs(t *game.Table, value cards.CardValue) bool {
+	return len(t.State.Dealer.Hands) > 0 && len(t.State.Dealer.Hands[0].Cards) > 0 && t.State.Dealer.Hands[0].Cards[0].Value == value
+}
+
+// CanRescue is whether a Spanish 21 or Super Fun 21 player may take back the
+// double on hand and forfeit the original wager, which is offered until they
+// stand on it.
+func CanRescue(t *game.Table, hand *player.Hand) bool {
+	if t.Mode != game.Spanish21 && t.Mode != game.SuperFun21 || !hand.DoubleDown {
+		return false
+	}
+
+	if hand.Player == nil || IsDealer(*hand.Player) {
+		return false
+	}
+
+	if hand.Stand || hand.Surrendered || hand.Busted {
+		return false
+	}
+
+	return DealerToPlay(t)
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+func spanish21Table(upCard string) *game.Table {
+	spanish := game.NewTable(game.Spanish21, 1)
+	dealerHand := player.ToHand([]string{upCard, "♥9"})
+	dealerHand.Player = &spanish.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	spanish.State.Dealer.Hands = []player.Hand{dealerHand}
+	return spanish
+}
+
+func TestSpanish21Winnings(t *testing.T) {
+	spanish := spanish21Table("♠9")
+	tests := []struct {
+		hand []string
+		want int
+	}{
+		{[]string{"♠9", "♥A", "♣A"}, 10},
+		{[]string{"♠2", "♥3", "♣4", "♦5", "♠7"}, 15},
+		{[]string{"♠2", "♥3", "♣4", "♦5", "♠2", "♥5"}, 20},
+		{[]string{"♠2", "♥3", "♣4", "♦A", "♠2", "♥5", "♣4"}, 30},
+		{[]string{"♠6", "♥7", "♣8"}, 15},
+		{[]string{"♥7", "♥8", "♥6"}, 20},
+		{[]string{"♠7", "♠7", "♠7"}, 30},
+	}
+
+	for _, test := range tests {
+		hand := player.ToHand(test.hand)
+		hand.Wager = 10
+		if got := Spanish21Winnings(spanish, hand); got != test.want {
+			t.Errorf("%v won %d want %d", test.hand, got, test.want)
+		}
+	}
+
+	doubled := player.ToHand([]string{"♠6", "♥7", "♣8"})
+	doubled.Wager = 20
+	doubled.DoubleDown = true
+	if got := Spanish21Winnings(spanish, doubled); got != 20 {
+		t.Errorf("a doubled 6-7-8 should pay even money, won %d", got)
+	}
+}
+
+func TestSpanish21SuperBonus(t *testing.T) {
+	spanish := spanish21Table("♦7")
+	hand := player.ToHand([]string{"♣7", "♣7", "♣7"})
+	hand.Wager = 10
+	if got := Spanish21Winnings(spanish, hand); got != SuperBonus {
+		t.Fatalf("suited 7-7-7 against a 7 won %d want %d", got, SuperBonus)
+	}
+
+	hand.Wager = SuperBonusWager
+	if got := Spanish21Winnings(spanish, hand); got != SuperBonusLarge {
+		t.Fatalf("suited 7-7-7 against a 7 won %d want %d", got, SuperBonusLarge)
+	}
+
+	hand.Split = true
+	if got := Spanish21Winnings(spanish, hand); got != SuitedBonusPayout.Winnings(hand.Wager) {
+		t.Fatalf("a split 7-7-7 should only pay the suited bonus, won %d", got)
+	}
+}
+
+func TestSpanish21DoublesAndRescues(t *testing.T) {
+	spanish := spanish21Table("♠10")
+	cardPlayer := player.Player{Stack: 100}
+	tableRules := DefaultTableRules()
+
+	hand := player.ToHand([]string{"♠2", "♥3", "♣4"})
+	hand.Player = &cardPlayer
+	hand.Wager = 10
+	if !CanDoubleDown(spanish, &hand, tableRules) {
+		t.Fatalf("Spanish 21 should double on three cards")
+	}
+	if CanDoubleDown(table, &hand, tableRules) {
+		t.Fatalf("blackjack should only double on two cards")
+	}
+
+	if !CanSurrender(spanish, &player.Hand{Cards: hand.Cards[:2], Player: &cardPlayer}, tableRules) {
+		t.Fatalf("Spanish 21 should offer late surrender")
+	}
+
+	hand.DoubleDown = true
+	hand.Wager = 20
+	if !CanRescue(spanish, &hand) || !CanSurrender(spanish, &hand, tableRules) {
+		t.Fatalf("a Spanish 21 double should be rescuable")
+	}
+
+	hand.Stand = true
+	if CanRescue(spanish, &hand) {
+		t.Fatalf("a double that stood should not be rescuable")
+	}
+}
@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"blackjack/cards"
+	"blackjack/player"
+)
+
+// Decision is one cell of a strategy table: the best play, and the best of
+// hitting or standing for when that play is a double or surrender the hand
+// can no longer make.
+type Decision struct {
+	Action   rune `yaml:"action"`
+	Fallback rune `yaml:"fallback"`
+}
+
+// StrategyTable is a basic strategy chart for one shoe and set of table rules.
+// Every chart is indexed by the player's hand and then the dealer's upcard,
+// counting an ace as 1 and any ten-valued card as 10.
+type StrategyTable struct {
+	Hard                 [22][11]Decision     `yaml:"hard"`  // by hard total
+	Soft                 [22][11]Decision     `yaml:"soft"`  // by soft total, an ace counting 11
+	Pairs                [11][11]Decision     `yaml:"pairs"` // by the paired card
+	CompositionDependent bool                 `yaml:"composition-dependent"`
+	TwoCards             [11][11][11]Decision `yaml:"two-cards"` // by both cards, used for unsplit two card hands when CompositionDependent
+}
+
+var standDecision = Decision{Action: 's', Fallback: 's'}
+
+// StrategyDecision looks hand up in strategyTable. A pair is only played as a
+// pair when canSplit, otherwise it is played by its total.
+func StrategyDecision(strategyTable *StrategyTable, hand *player.Hand, dealerFaceUpCard int, canSplit bool) Decision {
+	if dealerFaceUpCard < 1 || dealerFaceUpCard > 10 {
+		return standDecision
+	}
+
+	total := 0
+	hasAce := false
+	for i := 0; i < len(hand.Cards); i++ {
+		total += cards.CardToValue(hand.Cards[i], true)
+		hasAce = hasAce || cards.IsAce(hand.Cards[i])
+	}
+
+	if len(hand.Cards) == 2 {
+		first := cards.CardToValue(hand.Cards[0], true)
+		second := cards.CardToValue(hand.Cards[1], true)
+
+		if canSplit && first == second && strategyTable.Pairs[first][dealerFaceUpCard].Action == 'p' {
+			return strategyTable.Pairs[first][dealerFaceUpCard]
+		}
+
+		if strategyTable.CompositionDependent && !hand.Split {
+			return strategyTable.TwoCards[first][second][dealerFaceUpCard]
+		}
+	}
+
+	if total > 21 {
+		return standDecision
+	}
+
+	if hasAce && total+10 <= 21 {
+		return strategyTable.Soft[total+10][dealerFaceUpCard]
+	}
+
+	return strategyTable.Hard[total][dealerFaceUpCard]
+}
@@ -0,0 +1,54 @@
+package rules
+
+import (
+	"blackjack/player"
+	"testing"
+)
+
+func TestStrategyDecision(t *testing.T) {
+	strategyTable := &StrategyTable{}
+	strategyTable.Pairs[8][10] = Decision{Action: 'p', Fallback: 'h'}
+	strategyTable.Hard[16][10] = Decision{Action: 'u', Fallback: 'h'}
+	strategyTable.Soft[18][10] = Decision{Action: 'h', Fallback: 'h'}
+
+	eights := player.ToHand([]string{"♠8", "♥8"})
+	if decision := StrategyDecision(strategyTable, &eights, 10, true); decision.Action != 'p' {
+		t.Fatalf("8,8 v 10 = %c want p", decision.Action)
+	}
+	if decision := StrategyDecision(strategyTable, &eights, 10, false); decision.Action != 'u' {
+		t.Fatalf("8,8 v 10 that cannot split = %c want u", decision.Action)
+	}
+
+	softEighteen := player.ToHand([]string{"♠A", "♥7"})
+	if decision := StrategyDecision(strategyTable, &softEighteen, 10, false); decision.Action != 'h' {
+		t.Fatalf("A,7 v 10 = %c want h", decision.Action)
+	}
+}
+
+func TestGetAutoPlayPlayerActionFallsBack(t *testing.T) {
+	strategyTable := &StrategyTable{}
+	strategyTable.Hard[11][5] = Decision{Action: 'd', Fallback: 'h'}
+
+	cardPlayer := player.Player{Stack: 100}
+	hand := player.ToHand([]string{"♠2", "♥4", "♣5"})
+	hand.Active = true
+	hand.Player = &cardPlayer
+
+	table.State.Dealer = player.Player{Dealer: true}
+	dealerHand := player.ToHand([]string{"♠5", "♥10"})
+	dealerHand.Cards[1].Masked = true
+	dealerHand.Player = &table.State.Dealer
+	table.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	action, err := GetAutoPlayPlayerAction(table, &hand, 5, DefaultTableRules(), strategyTable)
+	if err != nil {
+		t.Fatalf("GetAutoPlayPlayerAction returned error: %v", err)
+	}
+	if action != 'h' {
+		t.Fatalf("three card 11 cannot double, got %c want h", action)
+	}
+
+	if _, err := GetAutoPlayPlayerAction(table, &hand, 5, DefaultTableRules(), nil); err == nil {
+		t.Fatalf("expected an error without a strategy table")
+	}
+}
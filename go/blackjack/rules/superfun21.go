@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// DiamondBlackjackPayout is what a Super Fun 21 blackjack pays in diamonds,
+// where any other pays even money.
+var DiamondBlackjackPayout = Payout{Win: 2, Bet: 1}
+
+// FiveCard21Bonus is what a Super Fun 21 hand of five or more cards pays on
+// 21, where one of 20 wins even money.
+var FiveCard21Bonus = Payout{Win: 2, Bet: 1}
+
+// BlackjackAlwaysWins is whether a player's blackjack beats the dealer's, as
+// it does in Spanish 21 and Super Fun 21.
+func BlackjackAlwaysWins(t *game.Table) bool {
+	return t.Mode == game.Spanish21 || t.Mode == game.SuperFun21
+}
+
+// BlackjackWinnings is what hand's blackjack wins on top of its wager.
+func BlackjackWinnings(t *game.Table, hand player.Hand, tableRules TableRules) int {
+	if t.Mode == game.SuperFun21 && hand.Cards[0].Suite == cards.Diamonds && hand.Cards[1].Suite == cards.Diamonds {
+		return DiamondBlackjackPayout.Winnings(hand.Wager)
+	}
+	return ForGame(t.Mode, tableRules).BlackjackPayout.Winnings(hand.Wager)
+}
+
+// FiveCardWinnings is what hand wins whatever the dealer finishes on, and
+// whether it does: a Pontoon five card trick, or a Super Fun 21 hand of five
+// or more cards on 20 or 21.
+func FiveCardWinnings(t *game.Table, hand player.Hand) (int, bool) {
+	switch t.Mode {
+	case game.Pontoon:
+		if IsFiveCardTrick(t, hand) {
+			return PontoonPayout.Winnings(hand.Wager), true
+		}
+	case game.SuperFun21:
+		if len(hand.Cards) < 5 {
+			return 0, false
+		}
+		switch bestTotal(hand) {
+		case 21:
+			return FiveCard21Bonus.Winnings(hand.Wager), true
+		case 20:
+			return hand.Wager, true
+		}
+	}
+	return 0, false
+}
+
+// DoublesAnyCards is whether hand may double on more than two cards, as any
+// hand can in Spanish 21 and Super Fun 21, and a Pontoon hand can buy until
+// it has five or has twisted.
+func DoublesAnyCards(t *game.Table, hand player.Hand) bool {
+	switch t.Mode {
+	case game.Spanish21, game.SuperFun21:
+		return true
+	case game.Pontoon:
+		return len(hand.Cards) < FiveCardTrick && !hand.Twisted
+	}
+	return false
+}
+
+// bestTotal is hand's total with one ace counting 11 when it does not bust.
+func bestTotal(hand player.Hand) int {
+	total := HardTotal(hand)
+	for _, card := range hand.Cards {
+		if cards.IsAce(card) && total+10 <= 21 {
+			return total + 10
+		}
+	}
+	return total
+}
@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+func TestBlackjackWinnings(t *testing.T) {
+	superFun := game.NewTable(game.SuperFun21, 1)
+	tableRules := DefaultTableRules()
+
+	tests := []struct {
+		table *game.Table
+		hand  []string
+		want  int
+	}{
+		{superFun, []string{"♦A", "♦K"}, 20},
+		{superFun, []string{"♦A", "♥K"}, 10},
+		{game.NewTable(game.Pontoon, 1), []string{"♦A", "♥K"}, 20},
+		{table, []string{"♦A", "♥K"}, 15},
+	}
+
+	for _, test := range tests {
+		hand := player.ToHand(test.hand)
+		hand.Wager = 10
+		if got := BlackjackWinnings(test.table, hand, tableRules); got != test.want {
+			t.Errorf("%s %v won %d want %d", test.table.Mode, test.hand, got, test.want)
+		}
+	}
+}
+
+func TestFiveCardWinnings(t *testing.T) {
+	superFun := game.NewTable(game.SuperFun21, 1)
+	pontoon := game.NewTable(game.Pontoon, 1)
+
+	tests := []struct {
+		table *game.Table
+		hand  []string
+		want  int
+		wins  bool
+	}{
+		{superFun, []string{"♠2", "♥3", "♣4", "♦5", "♠7"}, 20, true},
+		{superFun, []string{"♠2", "♥3", "♣4", "♦5", "♠6"}, 10, true},
+		{superFun, []string{"♠2", "♥3", "♣4", "♦5", "♠5"}, 0, false},
+		{superFun, []string{"♠A", "♥3", "♣4", "♦A", "♠A", "♥2"}, 0, false},
+		{superFun, []string{"♠A", "♥3", "♣4", "♦A", "♠A", "♥A"}, 20, true},
+		{pontoon, []string{"♠2", "♥3", "♣4", "♦5", "♠2"}, 20, true},
+		{pontoon, []string{"♠2", "♥3", "♣4", "♦5", "♠K"}, 0, false},
+		{table, []string{"♠2", "♥3", "♣4", "♦5", "♠7"}, 0, false},
+	}
+
+	for _, test := range tests {
+		hand := player.ToHand(test.hand)
+		hand.Wager = 10
+		if got, wins := FiveCardWinnings(test.table, hand); got != test.want || wins != test.wins {
+			t.Errorf("%s %v won %d (%t) want %d (%t)", test.table.Mode, test.hand, got, wins, test.want, test.wins)
+		}
+	}
+}
+
+func TestGameActions(t *testing.T) {
+	if got := ActionFor(game.Pontoon, 'd'); got.Name != "buy" || got.Key != 'b' {
+		t.Fatalf("Pontoon should buy with b, got %+v", got)
+	}
+	if got := ActionFor(game.Blackjack, 'h').Name; got != "hit" {
+		t.Fatalf("blackjack should hit, got %s", got)
+	}
+	if got := ActionForKey(game.Pontoon, 't'); got != 'h' {
+		t.Fatalf("twisting should hit, got %c", got)
+	}
+	if got := ActionForKey(game.Switch, 't'); got != 't' {
+		t.Fatalf("t should still switch in Blackjack Switch, got %c", got)
+	}
+}
@@ -0,0 +1,22 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// CanSwitch is whether a Blackjack Switch player may still swap the second
+// cards of their two hands, which is only offered once, straight after the
+// deal.
+func CanSwitch(t *game.Table, p *player.Player) bool {
+	if t.Mode != game.Switch || len(p.Hands) != 2 {
+		return false
+	}
+
+	for _, hand := range p.Hands {
+		if !hand.Switchable || len(hand.Cards) != 2 {
+			return false
+		}
+	}
+	return true
+}
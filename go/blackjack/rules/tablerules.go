@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"blackjack/game"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Payout is the ratio a winning wager is paid at, e.g. 3:2 for a blackjack.
+type Payout struct {
+	Win int `yaml:"win"`
+	Bet int `yaml:"bet"`
+}
+
+var EvenMoneyPayout = Payout{Win: 1, Bet: 1}
+var SixToFive = Payout{Win: 6, Bet: 5}
+var ThreeToTwo = Payout{Win: 3, Bet: 2}
+
+// Surrender is when, if ever, a table lets a player give up half their wager.
+type Surrender int8
+
+const (
+	NoSurrender    Surrender = iota
+	LateSurrender            // only after the dealer has checked for blackjack
+	EarlySurrender           // offered before the dealer checks for blackjack
+)
+
+var SurrenderToString = map[Surrender]string{
+	NoSurrender:    "none",
+	LateSurrender:  "late",
+	EarlySurrender: "early",
+}
+
+// TableRules describes the house rules of the table being played. Every
+// rules.Can* check and the dealer's payouts consult it instead of assuming one
+// particular casino.
+type TableRules struct {
+	DealerHitsSoft17   bool      `yaml:"dealer-hits-soft-17"`
+	DoubleAfterSplit   bool      `yaml:"double-after-split"`
+	DoubleNineToEleven bool      `yaml:"double-nine-to-eleven"` // when false a player may double on any two cards
+	MaxSplitHands      int       `yaml:"max-split-hands"`       // 0 means there is no limit
+	ResplitAces        bool      `yaml:"resplit-aces"`
+	HitSplitAces       bool      `yaml:"hit-split-aces"`
+	BlackjackPayout    Payout    `yaml:"blackjack-payout"`
+	InsuranceAllowed   bool      `yaml:"insurance-allowed"`
+	Surrender          Surrender `yaml:"surrender"`
+	NoHoleCard         bool      `yaml:"no-hole-card"`       // the dealer's second card is only dealt once the players have acted
+	OriginalBetsOnly   bool      `yaml:"original-bets-only"` // without a hole card, a dealer blackjack only takes the original wagers
+}
+
+// DefaultTableRules matches the table this game has always modeled: dealer
+// stands on all 17s, double after split, double on any two cards, no resplit
+// or hitting of split aces, 3:2 blackjacks, insurance offered, no surrender
+// and a hole card.
+func DefaultTableRules() TableRules {
+	return TableRules{
+		DealerHitsSoft17:   false,
+		DoubleAfterSplit:   true,
+		DoubleNineToEleven: false,
+		MaxSplitHands:      4,
+		ResplitAces:        false,
+		HitSplitAces:       false,
+		BlackjackPayout:    ThreeToTwo,
+		InsuranceAllowed:   true,
+		Surrender:          NoSurrender,
+		NoHoleCard:         false,
+		OriginalBetsOnly:   true,
+	}
+}
+
+// ForGame is tableRules as mode plays them: Spanish 21 and Super Fun 21
+// always offer at least late surrender, Blackjack Switch, Double Exposure and
+// Super Fun 21 pay blackjacks even money and Pontoon pays pontoons 2:1, and
+// there is no insurance in Pontoon or without a hidden hole card to insure
+// against.
+func ForGame(mode game.Game, tableRules TableRules) TableRules {
+	if tableRules.NoHoleCard || mode == game.DoubleExposure || mode == game.Pontoon {
+		tableRules.InsuranceAllowed = false
+	}
+
+	switch mode {
+	case game.Spanish21:
+		if tableRules.Surrender == NoSurrender {
+			tableRules.Surrender = LateSurrender
+		}
+	case game.SuperFun21:
+		if tableRules.Surrender == NoSurrender {
+			tableRules.Surrender = LateSurrender
+		}
+		tableRules.BlackjackPayout = EvenMoneyPayout
+	case game.Switch, game.DoubleExposure:
+		tableRules.BlackjackPayout = EvenMoneyPayout
+	case game.Pontoon:
+		tableRules.BlackjackPayout = PontoonPayout
+	}
+	return tableRules
+}
+
+func ParsePayout(payoutString string) (Payout, error) {
+	parts := strings.Split(payoutString, ":")
+	if len(parts) != 2 {
+		return Payout{}, fmt.Errorf("payout %q should look like 3:2", payoutString)
+	}
+
+	win, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Payout{}, err
+	}
+
+	bet, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return Payout{}, err
+	}
+
+	if win <= 0 || bet <= 0 {
+		return Payout{}, errors.New("payout must be positive")
+	}
+
+	return Payout{Win: win, Bet: bet}, nil
+}
+
+// Set and String let a Payout be used directly as a command line flag.
+func (p *Payout) Set(payoutString string) error {
+	payout, err := ParsePayout(payoutString)
+	if err != nil {
+		return err
+	}
+	*p = payout
+	return nil
+}
+
+func (p *Payout) String() string {
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", p.Win, p.Bet)
+}
+
+func ParseSurrender(surrenderString string) (Surrender, error) {
+	for surrender, name := range SurrenderToString {
+		if strings.EqualFold(name, strings.TrimSpace(surrenderString)) {
+			return surrender, nil
+		}
+	}
+	return NoSurrender, fmt.Errorf("surrender %q should be none, late or early", surrenderString)
+}
+
+func (s *Surrender) Set(surrenderString string) error {
+	surrender, err := ParseSurrender(surrenderString)
+	if err != nil {
+		return err
+	}
+	*s = surrender
+	return nil
+}
+
+func (s *Surrender) String() string {
+	if s == nil {
+		return ""
+	}
+	return SurrenderToString[*s]
+}
+
+// Winnings is what a winning wager earns on top of the returned wager.
+func (p Payout) Winnings(wager int) int {
+	if p.Bet == 0 {
+		return wager
+	}
+	return wager * p.Win / p.Bet
+}
+
+func ReachedMaxSplitHands(playerHands int, tableRules TableRules) bool {
+	return tableRules.MaxSplitHands > 0 && playerHands >= tableRules.MaxSplitHands
+}
@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+var table = game.NewTable(game.Blackjack, 1)
+
+func TestParsePayout(t *testing.T) {
+	payout, err := ParsePayout("6:5")
+	if err != nil {
+		t.Fatalf("ParsePayout(6:5) returned error: %v", err)
+	}
+	if payout != SixToFive {
+		t.Fatalf("ParsePayout(6:5)=%v want %v", payout, SixToFive)
+	}
+	if _, err := ParsePayout("3-2"); err == nil {
+		t.Fatalf("expected error for malformed payout")
+	}
+	if ThreeToTwo.Winnings(10) != 15 || SixToFive.Winnings(10) != 12 {
+		t.Fatalf("unexpected winnings 3:2=%d 6:5=%d", ThreeToTwo.Winnings(10), SixToFive.Winnings(10))
+	}
+}
+
+func TestDealerHitsSoft17(t *testing.T) {
+	dealer := player.Player{Dealer: true}
+	hand := player.ToHand([]string{"♠A", "♥6"})
+	hand.Player = &dealer
+
+	tableRules := DefaultTableRules()
+	if CanHit(table, &hand, tableRules) {
+		t.Fatalf("S17 dealer should stand on soft 17")
+	}
+
+	tableRules.DealerHitsSoft17 = true
+	if !CanHit(table, &hand, tableRules) {
+		t.Fatalf("H17 dealer should hit soft 17")
+	}
+
+	hard := player.ToHand([]string{"♠10", "♥7"})
+	hard.Player = &dealer
+	if CanHit(table, &hard, tableRules) {
+		t.Fatalf("H17 dealer should stand on hard 17")
+	}
+}
+
+func TestDoubleNineToEleven(t *testing.T) {
+	cardPlayer := player.Player{Stack: 100}
+	tableRules := DefaultTableRules()
+	tableRules.DoubleNineToEleven = true
+
+	ten := player.ToHand([]string{"♠6", "♥4"})
+	ten.Player = &cardPlayer
+	if !CanDoubleDown(table, &ten, tableRules) {
+		t.Fatalf("expected to double on 10")
+	}
+
+	soft := player.ToHand([]string{"♠A", "♥6"})
+	soft.Player = &cardPlayer
+	if CanDoubleDown(table, &soft, tableRules) {
+		t.Fatalf("expected not to double on soft 17 with 9-11 only")
+	}
+}
+
+func TestMaxSplitHands(t *testing.T) {
+	cardPlayer := player.Player{Stack: 100}
+	hand := player.ToHand([]string{"♠8", "♥8"})
+	hand.Player = &cardPlayer
+	cardPlayer.Hands = []player.Hand{hand, hand}
+
+	tableRules := DefaultTableRules()
+	tableRules.MaxSplitHands = 2
+	if CanSplit(table, hand, tableRules) {
+		t.Fatalf("expected split limit to be reached")
+	}
+
+	tableRules.MaxSplitHands = 0
+	if !CanSplit(table, hand, tableRules) {
+		t.Fatalf("expected no split limit")
+	}
+}
+
+func TestMaxSplitHandsSwitch(t *testing.T) {
+	switchTable := game.NewTable(game.Switch, 1)
+	cardPlayer := player.Player{Stack: 100}
+	first := player.ToHand([]string{"♠8", "♥8"})
+	first.Player = &cardPlayer
+	second := first
+	second.Dealt = 1
+	cardPlayer.Hands = []player.Hand{first, second}
+
+	tableRules := DefaultTableRules()
+	tableRules.MaxSplitHands = 2
+	if !CanSplit(switchTable, first, tableRules) || !CanSplit(switchTable, second, tableRules) {
+		t.Fatalf("expected each dealt hand to split once")
+	}
+
+	// the first hand splits to its limit, the second has not split yet
+	cardPlayer.Hands = append(cardPlayer.Hands, first)
+	if CanSplit(switchTable, first, tableRules) {
+		t.Fatalf("expected the first hand's split limit to be reached")
+	}
+	if !CanSplit(switchTable, second, tableRules) {
+		t.Fatalf("expected the second hand to still split")
+	}
+}
+
+func TestCanPlayShortStack(t *testing.T) {
+	blackjack := game.NewTable(game.Blackjack, 1)
+	dealerHand := player.ToHand([]string{"♠A", "♥9"})
+	dealerHand.Player = &blackjack.State.Dealer
+	dealerHand.Cards[1].Masked = true
+	blackjack.State.Dealer.Hands = []player.Hand{dealerHand}
+
+	cardPlayer := player.Player{Stack: 0}
+	hand := player.ToHand([]string{"♠8", "♥8"})
+	hand.Player = &cardPlayer
+	hand.Active = true
+	hand.Wager = 10
+	cardPlayer.Hands = []player.Hand{hand}
+
+	tableRules := DefaultTableRules()
+	if !CanPlay(blackjack, cardPlayer, tableRules) {
+		t.Fatalf("a player who bet their whole stack should still play their hand")
+	}
+	if CanSplit(blackjack, hand, tableRules) || CanDoubleDown(blackjack, &hand, tableRules) || CanInsurance(blackjack, &hand, tableRules) {
+		t.Fatalf("a player who bet their whole stack should not split, double or insure")
+	}
+}
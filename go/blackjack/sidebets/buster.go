@@ -0,0 +1,50 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// BusterBlackjack pays when the dealer busts, more the more cards the
+// dealer's busted hand holds, so it settles only once the dealer has drawn.
+type BusterBlackjack struct{}
+
+func (BusterBlackjack) Name() string { return "Buster Blackjack" }
+
+func (BusterBlackjack) Resolves() Timing { return AfterDealer }
+
+func (BusterBlackjack) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Dealer busts with 8 or more cards", Odds: 250},
+		{Hand: "Dealer busts with 7 cards", Odds: 50},
+		{Hand: "Dealer busts with 6 cards", Odds: 12},
+		{Hand: "Dealer busts with 5 cards", Odds: 4},
+		{Hand: "Dealer busts with 3 or 4 cards", Odds: 2},
+	}
+}
+
+func (BusterBlackjack) Evaluate(t *game.Table, hand player.Hand) []int {
+	dealerCards := t.State.Dealer.Hands[0].Cards
+
+	// the hard total, every ace counted as one
+	total := 0
+	for _, card := range dealerCards {
+		total += cards.CardToValue(cards.CreateCard(card.Suite, card.Value), true)
+	}
+	if total <= 21 {
+		return nil
+	}
+
+	switch count := len(dealerCards); {
+	case count >= 8:
+		return []int{0}
+	case count == 7:
+		return []int{1}
+	case count == 6:
+		return []int{2}
+	case count == 5:
+		return []int{3}
+	}
+	return []int{4}
+}
@@ -0,0 +1,31 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"testing"
+)
+
+func TestBusterBlackjack(t *testing.T) {
+	tests := []struct {
+		name   string
+		dealer []cards.CardValue
+		want   int
+	}{
+		{"three card bust", []cards.CardValue{cards.Ten, cards.Six, cards.King}, 4},
+		{"five card bust", []cards.CardValue{cards.Two, cards.Three, cards.Ace, cards.Ten, cards.Nine}, 3},
+		{"eight card bust", []cards.CardValue{cards.Ace, cards.Two, cards.Ace, cards.Two, cards.Ace, cards.Three, cards.Six, cards.Ten}, 0},
+		{"soft 21 with aces", []cards.CardValue{cards.Ace, cards.Five, cards.Ace, cards.Four}, -1},
+		{"stands on 17", []cards.CardValue{cards.Ten, cards.Seven}, -1},
+	}
+
+	for _, test := range tests {
+		table := setupDealer(cards.CreateCard(cards.Clubs, test.dealer[0]))
+		for _, value := range test.dealer[1:] {
+			table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, cards.CreateCard(cards.Spades, value))
+		}
+		hand := playerHand([]cards.Card{cards.CreateCard(cards.Hearts, cards.Ten), cards.CreateCard(cards.Hearts, cards.Eight)})
+
+		lines := BusterBlackjack{}.Evaluate(table, hand)
+		checkLine(t, test.name, lines, test.want)
+	}
+}
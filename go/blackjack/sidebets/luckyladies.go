@@ -0,0 +1,59 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+)
+
+// LuckyLadies pays when the first two cards make twenty, and most when they
+// are the queen of hearts twice. It settles as soon as the cards are dealt,
+// the top line looking at the face down hole card for a dealer blackjack.
+// Without a hole card the top line is not offered and a queen of hearts pair
+// pays its own line.
+type LuckyLadies struct{}
+
+func (LuckyLadies) Name() string { return "Lucky Ladies" }
+
+func (LuckyLadies) Resolves() Timing { return AfterDeal }
+
+func (LuckyLadies) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Queen of hearts pair with dealer blackjack", Odds: 1000},
+		{Hand: "Queen of hearts pair", Odds: 125},
+		{Hand: "Matched 20", Odds: 19},
+		{Hand: "Suited 20", Odds: 9},
+		{Hand: "Any 20", Odds: 4},
+	}
+}
+
+func (LuckyLadies) Evaluate(t *game.Table, hand player.Hand) []int {
+	if len(hand.Cards) < 2 || hand.Split {
+		return nil
+	}
+
+	first, second := hand.Cards[0], hand.Cards[1]
+	// fresh cards, so aces count 11 however the hand has been played since
+	total := cards.CardToValue(cards.CreateCard(first.Suite, first.Value), false) + cards.CardToValue(cards.CreateCard(second.Suite, second.Value), false)
+	if total != 20 {
+		return nil
+	}
+
+	queenOfHearts := cards.CreateCard(cards.Hearts, cards.Queen)
+	// the dealer's first two cards, however many were drawn after them
+	dealerHand := t.State.Dealer.Hands[0]
+	dealerHand.Cards = DealerCards(t)
+
+	switch {
+	case first.Suite != second.Suite:
+		return []int{4}
+	case first.Value != second.Value:
+		return []int{3}
+	case first.Value != queenOfHearts.Value || first.Suite != queenOfHearts.Suite:
+		return []int{2}
+	case len(dealerHand.Cards) == 2 && rules.IsBlackjack(dealerHand):
+		return []int{0}
+	}
+	return []int{1}
+}
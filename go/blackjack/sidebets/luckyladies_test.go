@@ -0,0 +1,34 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"testing"
+)
+
+func TestLuckyLadies(t *testing.T) {
+	queenOfHearts := cards.CreateCard(cards.Hearts, cards.Queen)
+	tests := []struct {
+		name  string
+		hole  cards.Card
+		cards []cards.Card
+		want  int
+	}{
+		{"queens of hearts with dealer blackjack", cards.CreateCard(cards.Spades, cards.King), []cards.Card{queenOfHearts, queenOfHearts}, 0},
+		{"queens of hearts", cards.CreateCard(cards.Spades, cards.Nine), []cards.Card{queenOfHearts, queenOfHearts}, 1},
+		{"matched 20", cards.CreateCard(cards.Spades, cards.King), []cards.Card{cards.CreateCard(cards.Clubs, cards.Jack), cards.CreateCard(cards.Clubs, cards.Jack)}, 2},
+		{"suited 20", cards.CreateCard(cards.Spades, cards.Nine), []cards.Card{cards.CreateCard(cards.Hearts, cards.Ace), cards.CreateCard(cards.Hearts, cards.Nine)}, 3},
+		{"any 20", cards.CreateCard(cards.Spades, cards.Nine), []cards.Card{queenOfHearts, cards.CreateCard(cards.Diamonds, cards.Queen)}, 4},
+		{"19", cards.CreateCard(cards.Spades, cards.Nine), []cards.Card{queenOfHearts, cards.CreateCard(cards.Hearts, cards.Nine)}, -1},
+		{"pair of aces", cards.CreateCard(cards.Spades, cards.Nine), []cards.Card{cards.CreateCard(cards.Hearts, cards.Ace), cards.CreateCard(cards.Hearts, cards.Ace)}, -1},
+	}
+
+	for _, test := range tests {
+		table := setupDealer(cards.CreateCard(cards.Clubs, cards.Ace))
+		hole := test.hole
+		hole.Masked = true
+		table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, hole)
+
+		lines := LuckyLadies{}.Evaluate(table, playerHand(test.cards))
+		checkLine(t, test.name, lines, test.want)
+	}
+}
@@ -0,0 +1,65 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+)
+
+// PerfectPairs pays when the first two cards are a pair. Its paytable lines
+// are a perfect pair of one suit, a colored pair of one color and a mixed
+// pair of one red and one black card, in that order, so each variant only
+// changes the odds.
+type PerfectPairs struct {
+	Variant string
+	Odds    [3]int
+}
+
+// perfectPairsHands name the lines of every Perfect Pairs paytable.
+var perfectPairsHands = [3]string{"Perfect pair", "Colored pair", "Mixed pair"}
+
+// The Perfect Pairs paytables tables offer.
+var (
+	PerfectPairsStandard = PerfectPairs{Variant: "", Odds: [3]int{25, 12, 6}}
+	PerfectPairs30       = PerfectPairs{Variant: "30", Odds: [3]int{30, 10, 5}}
+	PerfectPairs25       = PerfectPairs{Variant: "25", Odds: [3]int{25, 10, 5}}
+)
+
+func (b PerfectPairs) Name() string {
+	if b.Variant == "" {
+		return "Perfect Pairs"
+	}
+	return "Perfect Pairs " + b.Variant
+}
+
+func (PerfectPairs) Resolves() Timing { return AfterDeal }
+
+func (b PerfectPairs) Paytable() []Payout {
+	paytable := make([]Payout, 0, len(b.Odds))
+	for i, odds := range b.Odds {
+		paytable = append(paytable, Payout{Hand: perfectPairsHands[i], Odds: odds})
+	}
+	return paytable
+}
+
+func (PerfectPairs) Evaluate(t *game.Table, hand player.Hand) []int {
+	if len(hand.Cards) < 2 || hand.Split {
+		return nil
+	}
+
+	// only the first two cards count, however many the hand has by now
+	firstTwo := hand
+	firstTwo.Cards = hand.Cards[:2]
+	first, second := firstTwo.Cards[0], firstTwo.Cards[1]
+
+	switch {
+	case rules.IsPairHand(firstTwo, first.Value, true):
+		return []int{0}
+	case !rules.IsPairHand(firstTwo, first.Value, false):
+		return nil
+	case cards.IsRed(first) == cards.IsRed(second):
+		return []int{1}
+	}
+	return []int{2}
+}
@@ -0,0 +1,52 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+func TestPerfectPairs(t *testing.T) {
+	tests := []struct {
+		name  string
+		cards []cards.Card
+		want  int
+	}{
+		{"perfect pair", []cards.Card{cards.CreateCard(cards.Spades, cards.Queen), cards.CreateCard(cards.Spades, cards.Queen)}, 0},
+		{"colored pair", []cards.Card{cards.CreateCard(cards.Hearts, cards.Seven), cards.CreateCard(cards.Diamonds, cards.Seven)}, 1},
+		{"mixed pair", []cards.Card{cards.CreateCard(cards.Clubs, cards.Ace), cards.CreateCard(cards.Diamonds, cards.Ace)}, 2},
+		{"pair after hitting", []cards.Card{cards.CreateCard(cards.Clubs, cards.Two), cards.CreateCard(cards.Clubs, cards.Two), cards.CreateCard(cards.Hearts, cards.Five)}, 0},
+		{"ten values", []cards.Card{cards.CreateCard(cards.Clubs, cards.King), cards.CreateCard(cards.Clubs, cards.Queen)}, -1},
+		{"third card pairs", []cards.Card{cards.CreateCard(cards.Clubs, cards.Two), cards.CreateCard(cards.Clubs, cards.Three), cards.CreateCard(cards.Clubs, cards.Three)}, -1},
+	}
+
+	table := setupDealer(cards.CreateCard(cards.Hearts, cards.Nine))
+	for _, test := range tests {
+		lines := PerfectPairsStandard.Evaluate(table, playerHand(test.cards))
+		checkLine(t, test.name, lines, test.want)
+	}
+}
+
+func TestPerfectPairsPaytables(t *testing.T) {
+	table := setupDealer(cards.CreateCard(cards.Hearts, cards.Nine))
+	p := player.Player{}
+	hand := player.Hand{Cards: []cards.Card{cards.CreateCard(cards.Hearts, cards.Eight), cards.CreateCard(cards.Hearts, cards.Eight)}, Player: &p}
+
+	for bet, want := range map[PerfectPairs]int{
+		PerfectPairsStandard: 250,
+		PerfectPairs30:       300,
+		PerfectPairs25:       250,
+	} {
+		if got := Pay(table, bet, bet.Evaluate(table, hand), 10, 0); got != want {
+			t.Errorf("%s perfect pair on 10: got %d want %d", bet.Name(), got, want)
+		}
+	}
+
+	if got := Outcome(table, PerfectPairsStandard, hand); got != "Perfect pair" {
+		t.Errorf("got outcome %q want Perfect pair", got)
+	}
+	if _, err := Parse("perfectpairs-30", game.Blackjack); err != nil {
+		t.Fatalf("perfectpairs-30 should parse: %v", err)
+	}
+}
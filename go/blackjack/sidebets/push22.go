@@ -0,0 +1,28 @@
+package sidebets
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+)
+
+// Push22 pays when the dealer finishes on 22, the total Free Bet pushes
+// against, so it settles only once the dealer has drawn.
+type Push22 struct{}
+
+func (Push22) Name() string { return "Push 22" }
+
+func (Push22) Resolves() Timing { return AfterDealer }
+
+func (Push22) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Dealer 22", Odds: 11},
+	}
+}
+
+func (Push22) Evaluate(t *game.Table, hand player.Hand) []int {
+	if rules.HardTotal(t.State.Dealer.Hands[0]) != 22 {
+		return nil
+	}
+	return []int{0}
+}
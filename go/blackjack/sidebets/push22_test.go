@@ -0,0 +1,33 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/player"
+	"testing"
+)
+
+func TestPush22(t *testing.T) {
+	tests := []struct {
+		name   string
+		dealer []cards.CardValue
+		hits   bool
+	}{
+		{"three card 22", []cards.CardValue{cards.Ten, cards.Six, cards.Six}, true},
+		{"22 with an ace", []cards.CardValue{cards.Ace, cards.Five, cards.Six, cards.King}, true},
+		{"23", []cards.CardValue{cards.Ten, cards.Six, cards.Seven}, false},
+		{"21", []cards.CardValue{cards.Ace, cards.Five, cards.Five}, false},
+	}
+
+	for _, test := range tests {
+		table := setupDealer(cards.CreateCard(cards.Clubs, test.dealer[0]))
+		for _, value := range test.dealer[1:] {
+			table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, cards.CreateCard(cards.Spades, value))
+		}
+		p := player.Player{}
+		hand := player.Hand{Cards: []cards.Card{cards.CreateCard(cards.Hearts, cards.Ten), cards.CreateCard(cards.Hearts, cards.Eight)}, Player: &p}
+
+		if lines := (Push22{}).Evaluate(table, hand); (len(lines) == 1) != test.hits {
+			t.Errorf("%s: got lines %v", test.name, lines)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package sidebets
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/progressive"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Timing is when in a round a sidebet settles.
+type Timing int
+
+const (
+	AfterDeal   Timing = iota // once the first two cards are out, before anyone acts
+	AfterDealer               // once the dealer has finished drawing
+)
+
+// Payout is one line of a sidebet's paytable. A line pays Odds to 1 on the
+// wager, or Amount whatever the wager, or for a Progressive line the whole
+// of the table's progressive meter named Meter.
+type Payout struct {
+	Hand        string
+	Odds        int
+	Amount      int
+	Progressive bool
+	Meter       string
+}
+
+// Sidebet is a bet played beside a player's first hand.
+type Sidebet interface {
+	Name() string
+	Resolves() Timing
+	// Evaluate lists the Paytable lines hand wins, once for each time it
+	// wins them, and nothing when it loses.
+	Evaluate(t *game.Table, hand player.Hand) []int
+	Paytable() []Payout
+}
+
+// Progressive is a Sidebet with progressive lines. Meters are the meters
+// they pay, set up as a table starts them, and losing wagers feed each of
+// them its Increment.
+type Progressive interface {
+	Meters() []progressive.Meter
+}
+
+// SwitchHands is a Sidebet settled on the first two cards of both of the
+// hands a Blackjack Switch player is dealt, rather than on one hand.
+type SwitchHands interface {
+	SwitchHands()
+}
+
+// Sidebets are the sidebets a table can offer, by the name the -sidebets
+// flag takes.
+var Sidebets = map[string]Sidebet{
+	"jackattack":      JackAttack{},
+	"match":           MatchTheDealer{},
+	"trifecta":        Trifecta{},
+	"trifecta3":       Trifecta3{},
+	"stax":            TrifectaStax{},
+	"21+3":            TwentyOnePlusThreeClassic,
+	"21+3-flat":       TwentyOnePlusThreeFlat,
+	"21+3-tiered":     TwentyOnePlusThreeTiered,
+	"21+3-xtreme":     TwentyOnePlusThreeXtreme,
+	"perfectpairs":    PerfectPairsStandard,
+	"perfectpairs-30": PerfectPairs30,
+	"perfectpairs-25": PerfectPairs25,
+	"luckyladies":     LuckyLadies{},
+	"buster":          BusterBlackjack{},
+	"push22":          Push22{},
+	"supermatch":      SuperMatch{},
+}
+
+// gameSidebets are the sidebets each game offers unless told otherwise.
+var gameSidebets = map[game.Game][]string{
+	game.JackAttack:    {"jackattack"},
+	game.Spanish21:     {"match"},
+	game.FreeBet:       {"push22"},
+	game.Switch:        {"supermatch"},
+	game.Trifecta:      {"trifecta"},
+	game.Trifecta3:     {"trifecta3"},
+	game.TrifectaStaxx: {"stax"},
+}
+
+// SidebetNames lists the names of Sidebets in order.
+func SidebetNames() []string {
+	names := make([]string, 0, len(Sidebets))
+	for name := range Sidebets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ByName finds the sidebet whose Name is name.
+func ByName(name string) (Sidebet, bool) {
+	for _, bet := range Sidebets {
+		if bet.Name() == name {
+			return bet, true
+		}
+	}
+	return nil, false
+}
+
+// ForGame is the sidebets mode offers by default.
+func ForGame(mode game.Game) []Sidebet {
+	bets := make([]Sidebet, 0)
+	for _, name := range gameSidebets[mode] {
+		bets = append(bets, Sidebets[name])
+	}
+	return bets
+}
+
+// Parse reads spec, a comma separated list of Sidebets. An empty spec is the
+// game's own sidebets and "none" is none at all.
+func Parse(spec string, mode game.Game) ([]Sidebet, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return ForGame(mode), nil
+	}
+
+	bets := make([]Sidebet, 0)
+	if spec == "none" {
+		return bets, nil
+	}
+
+	for _, name := range strings.Split(spec, ",") {
+		bet, ok := Sidebets[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("sidebet %q should be one of %s", name, strings.Join(SidebetNames(), ", "))
+		}
+		bets = append(bets, bet)
+	}
+
+	return bets, nil
+}
+
+// Spec writes bets the way Parse reads them back, "none" for no bets.
+func Spec(bets []Sidebet) string {
+	names := make([]string, 0, len(bets))
+	for _, bet := range bets {
+		for name, sidebet := range Sidebets {
+			if sidebet.Name() == bet.Name() {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// Settle pays every bet that resolves at timing on each player's first hand,
+// returning winning wagers along with what they won, and takes the losing
+// ones.
+func Settle(t *game.Table, bets []Sidebet, timing Timing) {
+	AddMeters(t, bets)
+
+	for i := 0; i < len(t.State.Players); i++ {
+		currPlayer := &t.State.Players[i]
+		if len(currPlayer.Hands) == 0 {
+			continue
+		}
+		hand := &currPlayer.Hands[0]
+
+		for _, bet := range bets {
+			wager := hand.Sidebets[bet.Name()]
+			if wager == 0 || bet.Resolves() != timing {
+				continue
+			}
+
+			winnings := Pay(t, bet, bet.Evaluate(t, *hand), wager, i)
+			if winnings > 0 {
+				if hand.SidebetWinnings == nil {
+					hand.SidebetWinnings = make(map[string]int)
+				}
+				hand.SidebetWinnings[bet.Name()] = winnings
+				currPlayer.Stack += winnings + wager
+				t.State.SidebetWinnings += winnings
+			} else {
+				t.State.SidebetLosings += wager
+				if progressiveBet, ok := bet.(Progressive); ok {
+					for _, meter := range progressiveBet.Meters() {
+						t.Progressives.Feed(meter.Name, wager)
+					}
+				}
+			}
+		}
+	}
+
+	game.SaveBlackjackStateYaml(t)
+}
+
+// AddMeters gives the table's progressives the meters of any progressive
+// bets it does not have yet.
+func AddMeters(t *game.Table, bets []Sidebet) {
+	for _, bet := range bets {
+		if progressiveBet, ok := bet.(Progressive); ok {
+			t.Progressives.Add(progressiveBet.Meters()...)
+		}
+	}
+}
+
+// Pay is what lines of bet's paytable win on wager for the player in seat,
+// paying out the meters of any progressive lines.
+func Pay(t *game.Table, bet Sidebet, lines []int, wager int, seat int) int {
+	paytable := bet.Paytable()
+	winnings := 0
+
+	for _, line := range lines {
+		payout := paytable[line]
+		if payout.Progressive {
+			hit := progressive.Hit{Game: t.Mode.String(), Round: t.State.Rounds, Seat: seat}
+			winnings += t.Progressives.Hit(payout.Meter, hit) / 100
+		} else {
+			winnings += payout.Odds*wager + payout.Amount
+		}
+	}
+
+	return winnings
+}
+
+// Wagered lists the names of the sidebets wagered on hand in order.
+func Wagered(hand player.Hand) []string {
+	names := make([]string, 0, len(hand.Sidebets))
+	for name := range hand.Sidebets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Outcome names the lines hand won on bet, e.g. "Straight, Flush".
+func Outcome(t *game.Table, bet Sidebet, hand player.Hand) string {
+	paytable := bet.Paytable()
+	hands := make([]string, 0)
+	for _, line := range bet.Evaluate(t, hand) {
+		hands = append(hands, paytable[line].Hand)
+	}
+	return strings.Join(hands, ", ")
+}
@@ -4,35 +4,44 @@ import (
 	"blackjack/cards"
 	"blackjack/game"
 	"blackjack/player"
+	"blackjack/progressive"
 	"blackjack/rules"
 )
 
-var TrifectaProgressives []int
-
 const Spanish21MatchUnsuitedMultiplier = 3
 const Spanish21MatchSuitMultiplier = 12
 
-func DealerUpCard() cards.Card {
-	return game.State.Dealer.Hands[0].Cards[0]
+func DealerUpCard(t *game.Table) cards.Card {
+	return t.State.Dealer.Hands[0].Cards[0]
+}
+
+func DealerDownCard(t *game.Table) cards.Card {
+	return t.State.Dealer.Hands[0].Cards[1]
 }
 
-func DealerDownCard() cards.Card {
-	return game.State.Dealer.Hands[0].Cards[1]
+// DealerCards are the dealer's first two cards, or only the up card when no
+// hole card has been dealt.
+func DealerCards(t *game.Table) []cards.Card {
+	dealt := t.State.Dealer.Hands[0].Cards
+	if len(dealt) > 2 {
+		dealt = dealt[:2]
+	}
+	return append([]cards.Card{}, dealt...)
 }
 
-func HandToTrifectaHand(hand player.Hand) player.Hand {
+func HandToTrifectaHand(t *game.Table, hand player.Hand) player.Hand {
 	trifectaHand := player.CreateHand()
 	trifectaHand.Player = hand.Player
 
 	for i := 0; i < len(hand.Cards); i++ {
 		trifectaHand.Cards = append(trifectaHand.Cards, cards.CreateCard(hand.Cards[i].Suite, hand.Cards[i].Value))
 	}
-	trifectaHand.Cards = append(trifectaHand.Cards, cards.CreateCard(game.State.Dealer.Hands[0].Cards[0].Suite, game.State.Dealer.Hands[0].Cards[0].Value))
+	trifectaHand.Cards = append(trifectaHand.Cards, cards.CreateCard(t.State.Dealer.Hands[0].Cards[0].Suite, t.State.Dealer.Hands[0].Cards[0].Value))
 
 	return trifectaHand
 }
 
-func IsTrifectaFlush(hand player.Hand) bool {
+func IsTrifectaFlush(t *game.Table, hand player.Hand) bool {
 	if rules.IsDealer(*hand.Player) {
 		return false
 	}
@@ -41,7 +50,7 @@ func IsTrifectaFlush(hand player.Hand) bool {
 		return false
 	}
 
-	trifectaHand := HandToTrifectaHand(hand)
+	trifectaHand := HandToTrifectaHand(t, hand)
 
 	if len(trifectaHand.Cards) != 3 {
 		return false
@@ -50,7 +59,7 @@ func IsTrifectaFlush(hand player.Hand) bool {
 	return rules.IsFlush(trifectaHand)
 }
 
-func IsTrifectaJacksOrBetter(hand player.Hand) bool {
+func IsTrifectaJacksOrBetter(t *game.Table, hand player.Hand) bool {
 	if rules.IsDealer(*hand.Player) {
 		return false
 	}
@@ -59,16 +68,16 @@ func IsTrifectaJacksOrBetter(hand player.Hand) bool {
 		return false
 	}
 
-	trifectaHand := HandToTrifectaHand(hand)
+	trifectaHand := HandToTrifectaHand(t, hand)
 
 	if len(trifectaHand.Cards) != 3 {
 		return false
 	}
 
-	return IsTrifectaPair(hand, cards.Jack) || IsTrifectaPair(hand, cards.Queen) || IsTrifectaPair(hand, cards.King) || IsTrifectaPair(hand, cards.Ace) || IsTrifectaTrips(hand, false) || IsTrifectaStraight(hand) || IsTrifectaFlush(hand) || IsTrifectaStraightFlush(hand) || IsTrifectaRoyalFlush(hand)
+	return IsTrifectaPair(t, hand, cards.Jack) || IsTrifectaPair(t, hand, cards.Queen) || IsTrifectaPair(t, hand, cards.King) || IsTrifectaPair(t, hand, cards.Ace) || IsTrifectaTrips(t, hand, false) || IsTrifectaStraight(t, hand) || IsTrifectaFlush(t, hand) || IsTrifectaStraightFlush(t, hand) || IsTrifectaRoyalFlush(t, hand)
 }
 
-func IsTrifectaPair(hand player.Hand, value cards.CardValue) bool {
+func IsTrifectaPair(t *game.Table, hand player.Hand, value cards.CardValue) bool {
 	if rules.IsDealer(*hand.Player) {
 		return false
 	}
@@ -77,7 +86,7 @@ func IsTrifectaPair(hand player.Hand, value cards.CardValue) bool {
 		return false
 	}
 
-	trifectaHand := HandToTrifectaHand(hand)
+	trifectaHand := HandToTrifectaHand(t, hand)
 
 	if len(trifectaHand.Cards) != 3 {
 		return false
@@ -88,7 +97,7 @@ func IsTrifectaPair(hand player.Hand, value cards.CardValue) bool {
 		trifectaHand.Cards[1].Value == value && trifectaHand.Cards[2].Value == value
 }
 
-func IsTrifectaStraight(hand player.Hand) bool {
+func IsTrifectaStraight(t *game.Table, hand player.Hand) bool {
 	if rules.IsDealer(*hand.Player) {
 		return false
 	}
@@ -97,7 +106,7 @@ func IsTrifectaStraight(hand player.Hand) bool {
 		return false
 	}
 
-	trifectaHand := HandToTrifectaHand(hand)
+	trifectaHand := HandToTrifectaHand(t, hand)
 
 	if len(trifectaHand.Cards) != 3 {
 		return false
@@ -106,7 +115,7 @@ func IsTrifectaStraight(hand player.Hand) bool {
 	return rules.IsStraight(trifectaHand)
 }
 
-func IsTrifectaStraightFlush(hand player.Hand) bool {
+func IsTrifectaStraightFlush(t *game.Table, hand player.Hand) bool {
 	if rules.IsDealer(*hand.Player) {
 		return false
 	}
@@ -115,7 +124,7 @@ func IsTrifectaStraightFlush(hand player.Hand) bool {
 		return false
 	}
 
-	trifectaHand := HandToTrifectaHand(hand)
+	trifectaHand := HandToTrifectaHand(t, hand)
 
 	if len(trifectaHand.Cards) != 3 {
 		return false
@@ -124,7 +133,7 @@ func IsTrifectaStraightFlush(hand player.Hand) bool {
 	return rules.IsStraightFlush(trifectaHand)
 }
 
-func IsTrifectaRoyalFlush(hand player.Hand) bool {
+func IsTrifectaRoyalFlush(t *game.Table, hand player.Hand) bool {
 	if rules.IsDealer(*hand.Player) {
 		return false
 	}
@@ -133,7 +142,7 @@ func IsTrifectaRoyalFlush(hand player.Hand) bool {
 		return false
 	}
 
-	trifectaHand := HandToTrifectaHand(hand)
+	trifectaHand := HandToTrifectaHand(t, hand)
 
 	if len(trifectaHand.Cards) != 3 {
 		return false
@@ -142,8 +151,8 @@ func IsTrifectaRoyalFlush(hand player.Hand) bool {
 	return rules.IsRoyalFlush(trifectaHand)
 }
 
-func IsTrifectaTripAces(hand player.Hand, suited bool) bool {
-	if IsTrifectaTrips(hand, suited) {
+func IsTrifectaTripAces(t *game.Table, hand player.Hand, suited bool) bool {
+	if IsTrifectaTrips(t, hand, suited) {
 		if cards.IsAce(hand.Cards[0]) {
 			return true
 		} else {
@@ -154,8 +163,8 @@ func IsTrifectaTripAces(hand player.Hand, suited bool) bool {
 	}
 }
 
-func IsTrifectaTriplet(hand player.Hand, value cards.CardValue, suited bool) bool {
-	if IsTrifectaTrips(hand, suited) {
+func IsTrifectaTriplet(t *game.Table, hand player.Hand, value cards.CardValue, suited bool) bool {
+	if IsTrifectaTrips(t, hand, suited) {
 		if hand.Cards[0].Value == value {
 			return true
 		} else {
@@ -166,7 +175,7 @@ func IsTrifectaTriplet(hand player.Hand, value cards.CardValue, suited bool) boo
 	}
 }
 
-func IsTrifectaTrips(hand player.Hand, suited bool) bool {
+func IsTrifectaTrips(t *game.Table, hand player.Hand, suited bool) bool {
 	if rules.IsDealer(*hand.Player) {
 		return false
 	}
@@ -175,22 +184,22 @@ func IsTrifectaTrips(hand player.Hand, suited bool) bool {
 		return false
 	}
 
-	trifectaHand := HandToTrifectaHand(hand)
+	trifectaHand := HandToTrifectaHand(t, hand)
 
 	if len(trifectaHand.Cards) != 3 {
 		return false
 	}
 
 	value := trifectaHand.Cards[0].Value
+	isTrips := (trifectaHand.Cards[1].Value == value &&
+		trifectaHand.Cards[2].Value == value) || (cards.IsAce(trifectaHand.Cards[0]) && cards.IsAce(trifectaHand.Cards[1]) && cards.IsAce(trifectaHand.Cards[2]))
 	if suited {
 		suite := trifectaHand.Cards[0].Suite
-		return (trifectaHand.Cards[1].Value == value &&
-			trifectaHand.Cards[2].Value == value) || (cards.IsAce(trifectaHand.Cards[0]) && cards.IsAce(trifectaHand.Cards[1]) && cards.IsAce(trifectaHand.Cards[2])) &&
-			(trifectaHand.Cards[1].Suite == suite &&
-				trifectaHand.Cards[2].Suite == suite)
+		return isTrips &&
+			trifectaHand.Cards[1].Suite == suite &&
+			trifectaHand.Cards[2].Suite == suite
 	} else {
-		return (trifectaHand.Cards[1].Value == value &&
-			trifectaHand.Cards[2].Value == value) || (cards.IsAce(trifectaHand.Cards[0]) && cards.IsAce(trifectaHand.Cards[1]) && cards.IsAce(trifectaHand.Cards[2]))
+		return isTrips
 	}
 }
 
@@ -210,223 +219,195 @@ func IsTwentyHand(hand player.Hand) bool {
 	return hand.Cards[0].Value == cards.Ten && hand.Cards[1].Value == cards.Ten
 }
 
-func PayJackAttack() {
-	for i := 0; i < len(game.State.Players); i++ {
-		playerToTest := &game.State.Players[i]
-		hand := player.ActiveHand(playerToTest)
-		winnings := 0
-
-		if rules.IsPairHand(*hand, cards.Jack, true) && cards.IsOneEyedJack(hand.Cards[0]) && cards.IsOneEyedJack(hand.Cards[1]) {
-			winnings += 100 * hand.TrifectaWager
-		} else if rules.IsPairHand(*hand, cards.Jack, false) && cards.IsOneEyedJack(hand.Cards[0]) && cards.IsOneEyedJack(hand.Cards[1]) {
-			// any two one-eyed jacks = 50 to 1
-			winnings += 50 * hand.TrifectaWager
-		} else if rules.IsPairHand(*hand, cards.Jack, true) {
-			// any suited jacks = 25 to 1
-			winnings += 25 * hand.TrifectaWager
-		} else if rules.IsPairHand(*hand, cards.Jack, false) {
-			// any two jacks = 10 to 1
-			winnings += 10 * hand.TrifectaWager
-		} else if IsTwentyHand(*hand) {
-			// any twenty = 5 to 1
-			winnings += 5 * hand.TrifectaWager
-		}
+// JackAttack pays on a first two cards of jacks, or any twenty.
+type JackAttack struct{}
 
-		if winnings > 0 {
-			// you get your wager back?
-			playerToTest.Stack += winnings + hand.TrifectaWager
+func (JackAttack) Name() string { return "Jack Attack" }
 
-			// track our winnings
-			game.State.SidebetWinnings += winnings
-		} else {
-			game.State.SidebetLosings += hand.TrifectaWager
-		}
+func (JackAttack) Resolves() Timing { return AfterDeal }
+
+func (JackAttack) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Suited one-eyed jacks", Odds: 100},
+		{Hand: "One-eyed jacks", Odds: 50},
+		{Hand: "Suited jacks", Odds: 25},
+		{Hand: "Jacks", Odds: 10},
+		{Hand: "Twenty", Odds: 5},
 	}
+}
 
-	game.SaveBlackjackStateYaml()
+func (JackAttack) Evaluate(t *game.Table, hand player.Hand) []int {
+	oneEyed := len(hand.Cards) == 2 && cards.IsOneEyedJack(hand.Cards[0]) && cards.IsOneEyedJack(hand.Cards[1])
+
+	switch {
+	case rules.IsPairHand(hand, cards.Jack, true) && oneEyed:
+		return []int{0}
+	case rules.IsPairHand(hand, cards.Jack, false) && oneEyed:
+		return []int{1}
+	case rules.IsPairHand(hand, cards.Jack, true):
+		return []int{2}
+	case rules.IsPairHand(hand, cards.Jack, false):
+		return []int{3}
+	case IsTwentyHand(hand):
+		return []int{4}
+	}
+	return nil
 }
 
-func GetSpanish21Winnings(hand player.Hand) int {
-	winnings := 0
+// MatchTheDealer pays for each of the first two cards that matches one of the
+// dealer's, more when the suits match too.
+type MatchTheDealer struct{}
 
-	if hand.TrifectaWager > 0 {
-		firstCard := hand.Cards[0]
-		secondCard := hand.Cards[1]
+func (MatchTheDealer) Name() string { return "Match the Dealer" }
 
-		dealerUpCard := DealerUpCard()
-		dealerDownCard := DealerDownCard()
+func (MatchTheDealer) Resolves() Timing { return AfterDeal }
 
-		if firstCard.Value == dealerUpCard.Value {
-			if CardsMatchSuite(firstCard, dealerUpCard) {
-				winnings += Spanish21MatchSuitMultiplier * hand.TrifectaWager
-			} else {
-				winnings += Spanish21MatchUnsuitedMultiplier * hand.TrifectaWager
-			}
-		}
+func (MatchTheDealer) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Suited match", Odds: Spanish21MatchSuitMultiplier},
+		{Hand: "Match", Odds: Spanish21MatchUnsuitedMultiplier},
+	}
+}
 
-		if secondCard.Value == dealerUpCard.Value {
-			if CardsMatchSuite(secondCard, dealerUpCard) {
-				winnings += Spanish21MatchSuitMultiplier * hand.TrifectaWager
-			} else {
-				winnings += Spanish21MatchUnsuitedMultiplier * hand.TrifectaWager
-			}
-		}
+func (MatchTheDealer) Evaluate(t *game.Table, hand player.Hand) []int {
+	lines := make([]int, 0)
+	if len(hand.Cards) < 2 {
+		return lines
+	}
 
-		if firstCard.Value == dealerDownCard.Value {
-			if CardsMatchSuite(firstCard, dealerDownCard) {
-				winnings += Spanish21MatchSuitMultiplier * hand.TrifectaWager
-			} else {
-				winnings += Spanish21MatchUnsuitedMultiplier * hand.TrifectaWager
+	for _, dealerCard := range DealerCards(t) {
+		for _, card := range hand.Cards[:2] {
+			if card.Value != dealerCard.Value {
+				continue
 			}
-		}
-
-		if secondCard.Value == dealerDownCard.Value {
-			if CardsMatchSuite(secondCard, dealerDownCard) {
-				winnings += Spanish21MatchSuitMultiplier * hand.TrifectaWager
+			if CardsMatchSuite(card, dealerCard) {
+				lines = append(lines, 0)
 			} else {
-				winnings += Spanish21MatchUnsuitedMultiplier * hand.TrifectaWager
+				lines = append(lines, 1)
 			}
 		}
 	}
 
-	return winnings
+	return lines
 }
 
-func PaySpanish21Matches() {
-	player.ForAllPlayers(game.State.Players, func(currPlayer *player.Player) {
-		player.ForAllHands(currPlayer, func(hand *player.Hand) {
-			winnings := GetSpanish21Winnings(*hand)
-
-			if winnings > 0 {
-				currPlayer.Stack += winnings + hand.TrifectaWager
-				game.State.SidebetWinnings += winnings
-			}
-		})
-	})
-
-	game.SaveBlackjackStateYaml()
-}
+// Trifecta pays on the three card poker hand of the first two cards and the
+// dealer's up card.
+type Trifecta struct{}
 
-func PayTrifecta() {
-	for i := 0; i < len(game.State.Players); i++ {
-		playerToTest := &game.State.Players[i]
-		hand := player.ActiveHand(playerToTest)
-		trifectaWinnings := 0
-
-		if IsTrifectaTriplet(*hand, cards.Five, false) {
-			//unsuited fives = 60 to 1
-			trifectaWinnings += 60 * hand.TrifectaWager
-		} else if IsTrifectaStraightFlush(*hand) {
-			//straight flush = 40 to 1
-			trifectaWinnings += 40 * hand.TrifectaWager
-		} else if IsTrifectaTrips(*hand, false) {
-			// three of a kind = 30 to 1
-			trifectaWinnings += 30 * hand.TrifectaWager
-		} else if IsTrifectaStraight(*hand) {
-			// straight = 6 to 1
-			trifectaWinnings += 6 * hand.TrifectaWager
-		} else if IsTrifectaFlush(*hand) {
-			// flush = 4 to 1
-			trifectaWinnings += 4 * hand.TrifectaWager
-		} else if IsTrifectaJacksOrBetter(*hand) {
-			trifectaWinnings += 2 * hand.TrifectaWager
-		}
+func (Trifecta) Name() string { return "Trifecta" }
 
-		if trifectaWinnings > 0 {
-			// you get your wager back?
-			playerToTest.Stack += trifectaWinnings + hand.TrifectaWager
+func (Trifecta) Resolves() Timing { return AfterDeal }
 
-			// track our winnings
-			game.State.SidebetWinnings += trifectaWinnings
-		} else {
-			game.State.SidebetLosings += hand.TrifectaWager
-		}
+func (Trifecta) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Three fives", Odds: 60},
+		{Hand: "Straight flush", Odds: 40},
+		{Hand: "Three of a kind", Odds: 30},
+		{Hand: "Straight", Odds: 6},
+		{Hand: "Flush", Odds: 4},
+		{Hand: "Jacks or better", Odds: 2},
 	}
+}
 
-	game.SaveBlackjackStateYaml()
+func (Trifecta) Evaluate(t *game.Table, hand player.Hand) []int {
+	switch {
+	case IsTrifectaTriplet(t, hand, cards.Five, false):
+		return []int{0}
+	case IsTrifectaStraightFlush(t, hand):
+		return []int{1}
+	case IsTrifectaTrips(t, hand, false):
+		return []int{2}
+	case IsTrifectaStraight(t, hand):
+		return []int{3}
+	case IsTrifectaFlush(t, hand):
+		return []int{4}
+	case IsTrifectaJacksOrBetter(t, hand):
+		return []int{5}
+	}
+	return nil
 }
 
-func PayTrifecta3() {
-	for i := 0; i < len(game.State.Players); i++ {
-		playerToTest := &game.State.Players[i]
-		hand := player.ActiveHand(playerToTest)
-		trifectaWinnings := 0
-
-		if IsTrifectaTrips(*hand, true) {
-			//suited trips = 270 to 1
-			trifectaWinnings += 270 * hand.TrifectaWager
-		} else if IsTrifectaStraightFlush(*hand) {
-			// straight flush 180 to 1
-			trifectaWinnings += 180 * hand.TrifectaWager
-		} else if IsTrifectaTrips(*hand, false) {
-			// three of a kind = 90 to 1
-			trifectaWinnings += 90 * hand.TrifectaWager
-		}
+// Trifecta3 is Trifecta paying only its top hands, at longer odds.
+type Trifecta3 struct{}
 
-		if trifectaWinnings > 0 {
-			// you get your wager back?
-			playerToTest.Stack += trifectaWinnings + hand.TrifectaWager
+func (Trifecta3) Name() string { return "Trifecta 3" }
 
-			// track our winnings
-			game.State.SidebetWinnings += trifectaWinnings
-		} else {
-			game.State.SidebetLosings += hand.TrifectaWager
-		}
+func (Trifecta3) Resolves() Timing { return AfterDeal }
+
+func (Trifecta3) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Suited three of a kind", Odds: 270},
+		{Hand: "Straight flush", Odds: 180},
+		{Hand: "Three of a kind", Odds: 90},
 	}
+}
 
-	game.SaveBlackjackStateYaml()
+func (Trifecta3) Evaluate(t *game.Table, hand player.Hand) []int {
+	switch {
+	case IsTrifectaTrips(t, hand, true):
+		return []int{0}
+	case IsTrifectaStraightFlush(t, hand):
+		return []int{1}
+	case IsTrifectaTrips(t, hand, false):
+		return []int{2}
+	}
+	return nil
 }
 
-func PayTrifectaStax() {
-	for i := 0; i < len(game.State.Players); i++ {
-		playerToTest := &game.State.Players[i]
-		hand := *player.ActiveHand(playerToTest)
-		trifectaWinnings := 0
-
-		if IsTrifectaTripAces(hand, true) {
-			//suited trip aces win the jackpot progressive
-			trifectaWinnings += TrifectaProgressives[0] / 100
-			TrifectaProgressives[0] = 1000000
-		} else if IsTrifectaTripAces(hand, false) {
-			//unsuited trip aces win the mega progressive
-			trifectaWinnings += TrifectaProgressives[1] / 100
-			TrifectaProgressives[1] = 500000
-		} else if IsTrifectaTriplet(hand, cards.King, false) {
-			//unsuited trip kings win the super progressive
-			trifectaWinnings += TrifectaProgressives[2] / 100
-			TrifectaProgressives[2] = 100000
-		} else if IsTrifectaTriplet(hand, cards.Queen, false) {
-			//unsuited trip queens win the progressive
-			trifectaWinnings += TrifectaProgressives[3] / 100
-			TrifectaProgressives[3] = 50000
-		} else if IsTrifectaStraightFlush(hand) {
-			trifectaWinnings += 150
-		} else if IsTrifectaTrips(hand, false) {
-			trifectaWinnings += 100
-		} else if IsTrifectaStraight(hand) {
-			trifectaWinnings += 30
-		} else if IsTrifectaFlush(hand) {
-			trifectaWinnings += 20
-		} else {
-			// add losing trifecta stax wagers to the progressives, weighted
-			TrifectaProgressives[0] += int(.6 * float64(hand.TrifectaWager) * 100)
-			TrifectaProgressives[1] += int(.25 * float64(hand.TrifectaWager) * 100)
-			TrifectaProgressives[2] += int(.1 * float64(hand.TrifectaWager) * 100)
-			TrifectaProgressives[3] += int(.05 * float64(hand.TrifectaWager) * 100)
-		}
+// TrifectaStax pays the table's progressives on trip aces, kings and queens
+// and flat amounts on the other Trifecta hands. Its losing wagers feed the
+// progressives.
+type TrifectaStax struct{}
 
-		if trifectaWinnings > 0 {
-			// you get your wager back?
-			playerToTest.Stack += trifectaWinnings + hand.TrifectaWager
+func (TrifectaStax) Name() string { return "Trifecta Stax" }
 
-			// track our winnings
-			game.State.SidebetWinnings += trifectaWinnings
-		} else {
-			game.State.SidebetLosings += hand.TrifectaWager
-		}
+func (TrifectaStax) Resolves() Timing { return AfterDeal }
+
+func (TrifectaStax) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Suited trip aces", Progressive: true, Meter: "Stax Grand"},
+		{Hand: "Trip aces", Progressive: true, Meter: "Stax Mega"},
+		{Hand: "Trip kings", Progressive: true, Meter: "Stax Major"},
+		{Hand: "Trip queens", Progressive: true, Meter: "Stax Minor"},
+		{Hand: "Straight flush", Amount: 150},
+		{Hand: "Three of a kind", Amount: 100},
+		{Hand: "Straight", Amount: 30},
+		{Hand: "Flush", Amount: 20},
 	}
+}
 
-	game.SaveBlackjackStateYaml()
+func (TrifectaStax) Evaluate(t *game.Table, hand player.Hand) []int {
+	switch {
+	case IsTrifectaTripAces(t, hand, true):
+		return []int{0}
+	case IsTrifectaTripAces(t, hand, false):
+		return []int{1}
+	case IsTrifectaTriplet(t, hand, cards.King, false):
+		return []int{2}
+	case IsTrifectaTriplet(t, hand, cards.Queen, false):
+		return []int{3}
+	case IsTrifectaStraightFlush(t, hand):
+		return []int{4}
+	case IsTrifectaTrips(t, hand, false):
+		return []int{5}
+	case IsTrifectaStraight(t, hand):
+		return []int{6}
+	case IsTrifectaFlush(t, hand):
+		return []int{7}
+	}
+	return nil
+}
+
+// Meters share out each losing wager between the progressives, most to the
+// rarest.
+func (TrifectaStax) Meters() []progressive.Meter {
+	return []progressive.Meter{
+		{Name: "Stax Grand", Seed: 1000000, Reset: 1000000, Increment: .6},
+		{Name: "Stax Mega", Seed: 500000, Reset: 500000, Increment: .25},
+		{Name: "Stax Major", Seed: 100000, Reset: 100000, Increment: .1},
+		{Name: "Stax Minor", Seed: 50000, Reset: 50000, Increment: .05},
+	}
 }
 
 func CardsMatchSuite(aCard cards.Card, bCard cards.Card) bool {
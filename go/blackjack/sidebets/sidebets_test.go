@@ -7,46 +7,159 @@ import (
 	"testing"
 )
 
-func setupDealer(card cards.Card) {
-	game.State.Dealer = player.Player{Dealer: true, Hands: []player.Hand{{Cards: []cards.Card{card}, Player: &player.Player{Dealer: true}}}}
+func setupDealer(card cards.Card) *game.Table {
+	t := game.NewTable(game.Blackjack, 1)
+	t.State.Dealer = player.Player{Dealer: true, Hands: []player.Hand{{Cards: []cards.Card{card}, Player: &player.Player{Dealer: true}}}}
+	return t
+}
+
+// playerHand is a player's hand of handCards.
+func playerHand(handCards []cards.Card) player.Hand {
+	return player.Hand{Cards: handCards, Player: &player.Player{}}
+}
+
+// checkLine fails test name unless lines is just the paytable line want, or
+// nothing when want is -1.
+func checkLine(t *testing.T, name string, lines []int, want int) {
+	t.Helper()
+	if want < 0 && len(lines) != 0 || want >= 0 && (len(lines) != 1 || lines[0] != want) {
+		t.Errorf("%s: got lines %v want %d", name, lines, want)
+	}
 }
 
 func TestIsTrifectaFlush(t *testing.T) {
-	setupDealer(cards.CreateCard(cards.Spades, cards.King))
+	table := setupDealer(cards.CreateCard(cards.Spades, cards.King))
 	p := player.Player{}
 	hand := player.Hand{Cards: []cards.Card{
 		cards.CreateCard(cards.Spades, cards.Three),
 		cards.CreateCard(cards.Spades, cards.Four),
 	}, Player: &p}
-	if !IsTrifectaFlush(hand) {
+	if !IsTrifectaFlush(table, hand) {
 		t.Fatalf("expected flush")
 	}
 	hand.Cards[1].Suite = cards.Hearts
-	if IsTrifectaFlush(hand) {
+	if IsTrifectaFlush(table, hand) {
 		t.Fatalf("expected not flush")
 	}
 	hand.Split = true
-	if IsTrifectaFlush(hand) {
+	if IsTrifectaFlush(table, hand) {
 		t.Fatalf("split hand should not qualify")
 	}
 }
 
 func TestIsTrifectaTrips(t *testing.T) {
-	setupDealer(cards.CreateCard(cards.Hearts, cards.Five))
+	table := setupDealer(cards.CreateCard(cards.Hearts, cards.Five))
 	p := player.Player{}
 	hand := player.Hand{Cards: []cards.Card{
 		cards.CreateCard(cards.Clubs, cards.Five),
 		cards.CreateCard(cards.Spades, cards.Five),
 	}, Player: &p}
-	if !IsTrifectaTrips(hand, false) {
+	if !IsTrifectaTrips(table, hand, false) {
 		t.Fatalf("expected trips")
 	}
 	hand.Cards[1].Value = cards.Six
-	if IsTrifectaTrips(hand, false) {
+	if IsTrifectaTrips(table, hand, false) {
 		t.Fatalf("expected not trips")
 	}
 	hand.Split = true
-	if IsTrifectaTrips(hand, false) {
+	if IsTrifectaTrips(table, hand, false) {
 		t.Fatalf("split hand should not qualify")
 	}
 }
+
+func TestParse(t *testing.T) {
+	bets, err := Parse("", game.TrifectaStaxx)
+	if err != nil || len(bets) != 1 || bets[0].Name() != "Trifecta Stax" {
+		t.Fatalf("empty spec should be the game's own sidebet, got %v, %v", bets, err)
+	}
+	if bets, err := Parse("", game.Blackjack); err != nil || len(bets) != 0 {
+		t.Fatalf("blackjack has no sidebet of its own, got %v, %v", bets, err)
+	}
+	if bets, err := Parse("none", game.Spanish21); err != nil || len(bets) != 0 {
+		t.Fatalf("none should offer no sidebets, got %v, %v", bets, err)
+	}
+	if bets, err := Parse("trifecta, jackattack", game.Blackjack); err != nil || len(bets) != 2 {
+		t.Fatalf("got %v, %v want two sidebets", bets, err)
+	}
+	if _, err := Parse("trifecta,keno", game.Blackjack); err == nil {
+		t.Fatalf("unknown sidebet should not parse")
+	}
+}
+
+func TestSettlePaysSeveralSidebets(t *testing.T) {
+	table := setupDealer(cards.CreateCard(cards.Clubs, cards.Jack))
+	table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, cards.CreateCard(cards.Clubs, cards.Two))
+	table.State.Players = []player.Player{{Stack: 100}}
+	cardPlayer := &table.State.Players[0]
+	cardPlayer.Hands = []player.Hand{{
+		Cards:    []cards.Card{cards.CreateCard(cards.Spades, cards.Jack), cards.CreateCard(cards.Hearts, cards.Jack)},
+		Player:   cardPlayer,
+		Sidebets: map[string]int{"Jack Attack": 5, "Trifecta": 5, "Trifecta 3": 5},
+	}}
+
+	Settle(table, []Sidebet{JackAttack{}, Trifecta{}, Trifecta3{}}, AfterDeal)
+
+	hand := cardPlayer.Hands[0]
+	if hand.SidebetWinnings["Jack Attack"] != 250 {
+		t.Fatalf("one-eyed jacks should pay 50 to 1, got %d", hand.SidebetWinnings["Jack Attack"])
+	}
+	if hand.SidebetWinnings["Trifecta"] != 150 || hand.SidebetWinnings["Trifecta 3"] != 450 {
+		t.Fatalf("trips should pay 30 and 90 to 1, got %v", hand.SidebetWinnings)
+	}
+	if cardPlayer.Stack != 100+250+150+450+15 {
+		t.Fatalf("got stack %d want winnings and wagers back", cardPlayer.Stack)
+	}
+
+	// bets that resolve later are left alone
+	cardPlayer.Stack = 100
+	Settle(table, []Sidebet{JackAttack{}}, AfterDealer)
+	if cardPlayer.Stack != 100 {
+		t.Fatalf("an after deal bet settled after the dealer drew")
+	}
+}
+
+func TestMatchTheDealerPaysEachMatch(t *testing.T) {
+	table := setupDealer(cards.CreateCard(cards.Hearts, cards.Seven))
+	table.State.Dealer.Hands[0].Cards = append(table.State.Dealer.Hands[0].Cards, cards.CreateCard(cards.Clubs, cards.Seven))
+	p := player.Player{}
+	hand := player.Hand{Cards: []cards.Card{
+		cards.CreateCard(cards.Hearts, cards.Seven),
+		cards.CreateCard(cards.Spades, cards.Four),
+	}, Player: &p}
+
+	if winnings := Pay(table, MatchTheDealer{}, MatchTheDealer{}.Evaluate(table, hand), 10, 0); winnings != 150 {
+		t.Fatalf("a suited and an unsuited match should pay 15 to 1, got %d", winnings)
+	}
+}
+
+func TestTrifectaStaxFeedsProgressives(t *testing.T) {
+	table := setupDealer(cards.CreateCard(cards.Hearts, cards.Ace))
+	AddMeters(table, []Sidebet{TrifectaStax{}})
+	for _, meter := range (TrifectaStax{}).Meters() {
+		table.Progressives.Set(meter.Name, 1000)
+	}
+	table.State.Players = []player.Player{{Stack: 100}}
+	cardPlayer := &table.State.Players[0]
+	cardPlayer.Hands = []player.Hand{{
+		Cards:    []cards.Card{cards.CreateCard(cards.Spades, cards.Two), cards.CreateCard(cards.Clubs, cards.Nine)},
+		Player:   cardPlayer,
+		Sidebets: map[string]int{"Trifecta Stax": 10},
+	}}
+
+	Settle(table, []Sidebet{TrifectaStax{}}, AfterDeal)
+	if table.Progressives.Amount("Stax Grand") != 1600 || table.Progressives.Amount("Stax Minor") != 1050 {
+		t.Fatalf("losing wager should feed the progressives, got %v", table.Progressives.Meters())
+	}
+
+	table.State.Rounds = 7
+	cardPlayer.Hands[0].Cards = []cards.Card{cards.CreateCard(cards.Spades, cards.Ace), cards.CreateCard(cards.Clubs, cards.Ace)}
+	Settle(table, []Sidebet{TrifectaStax{}}, AfterDeal)
+	if cardPlayer.Hands[0].SidebetWinnings["Trifecta Stax"] != 12 || table.Progressives.Amount("Stax Mega") != 500000 {
+		t.Fatalf("trip aces should win the mega progressive, got %v and %v", cardPlayer.Hands[0].SidebetWinnings, table.Progressives.Meters())
+	}
+
+	hits := table.Progressives.Meters()[1].Hits
+	if len(hits) != 1 || hits[0].Amount != 1250 || hits[0].Round != 7 || hits[0].Seat != 0 {
+		t.Fatalf("the jackpot should be recorded, got %+v", hits)
+	}
+}
@@ -0,0 +1,62 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+)
+
+// SuperMatch pays on the pairs among the four cards first dealt to a
+// Blackjack Switch player's two hands, before any switch.
+type SuperMatch struct{}
+
+func (SuperMatch) Name() string { return "Super Match" }
+
+func (SuperMatch) Resolves() Timing { return AfterDeal }
+
+func (SuperMatch) SwitchHands() {}
+
+func (SuperMatch) Paytable() []Payout {
+	return []Payout{
+		{Hand: "Four of a kind", Odds: 40},
+		{Hand: "Two pair", Odds: 8},
+		{Hand: "Three of a kind", Odds: 5},
+		{Hand: "One pair", Odds: 1},
+	}
+}
+
+func (SuperMatch) Evaluate(t *game.Table, hand player.Hand) []int {
+	if hand.Player == nil || len(hand.Player.Hands) < 2 {
+		return nil
+	}
+
+	counts := make(map[cards.CardValue]int)
+	for _, switchHand := range hand.Player.Hands[:2] {
+		if len(switchHand.Cards) < 2 {
+			return nil
+		}
+		for _, card := range switchHand.Cards[:2] {
+			counts[card.Value]++
+		}
+	}
+
+	pairs := 0
+	for _, count := range counts {
+		switch count {
+		case 4:
+			return []int{0}
+		case 3:
+			return []int{2}
+		case 2:
+			pairs++
+		}
+	}
+
+	switch pairs {
+	case 2:
+		return []int{1}
+	case 1:
+		return []int{3}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/player"
+	"testing"
+)
+
+func TestSuperMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		cards [4]cards.CardValue
+		want  int
+	}{
+		{"four of a kind", [4]cards.CardValue{cards.Six, cards.Six, cards.Six, cards.Six}, 0},
+		{"two pair", [4]cards.CardValue{cards.Six, cards.King, cards.Six, cards.King}, 1},
+		{"three of a kind", [4]cards.CardValue{cards.Ace, cards.Two, cards.Ace, cards.Ace}, 2},
+		{"one pair", [4]cards.CardValue{cards.Nine, cards.Two, cards.Three, cards.Nine}, 3},
+		{"ten and king", [4]cards.CardValue{cards.Ten, cards.King, cards.Two, cards.Three}, -1},
+	}
+
+	for _, test := range tests {
+		table := setupDealer(cards.CreateCard(cards.Clubs, cards.Five))
+		p := player.Player{}
+		p.Hands = []player.Hand{
+			{Cards: []cards.Card{cards.CreateCard(cards.Hearts, test.cards[0]), cards.CreateCard(cards.Spades, test.cards[1])}, Player: &p},
+			{Cards: []cards.Card{cards.CreateCard(cards.Diamonds, test.cards[2]), cards.CreateCard(cards.Clubs, test.cards[3])}, Player: &p},
+		}
+
+		lines := SuperMatch{}.Evaluate(table, p.Hands[0])
+		checkLine(t, test.name, lines, test.want)
+	}
+
+	table := setupDealer(cards.CreateCard(cards.Clubs, cards.Five))
+	p := player.Player{}
+	p.Hands = []player.Hand{{Cards: []cards.Card{cards.CreateCard(cards.Hearts, cards.Six), cards.CreateCard(cards.Spades, cards.Six)}, Player: &p}}
+	if lines := (SuperMatch{}).Evaluate(table, p.Hands[0]); len(lines) != 0 {
+		t.Errorf("one hand should not play Super Match, got lines %v", lines)
+	}
+}
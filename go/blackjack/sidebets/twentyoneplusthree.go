@@ -0,0 +1,69 @@
+package sidebets
+
+import (
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+)
+
+// TwentyOnePlusThree is 21+3, paying on the three card poker hand of the
+// first two cards and the dealer's up card. Its paytable lines are suited
+// trips, straight flush, three of a kind, straight and flush, in that order,
+// so each variant only changes the odds.
+type TwentyOnePlusThree struct {
+	Variant string
+	Odds    [5]int
+}
+
+// twentyOnePlusThreeHands name the lines of every 21+3 paytable.
+var twentyOnePlusThreeHands = [5]string{"Suited three of a kind", "Straight flush", "Three of a kind", "Straight", "Flush"}
+
+// The 21+3 paytables tables offer.
+var (
+	TwentyOnePlusThreeClassic = TwentyOnePlusThree{Variant: "", Odds: [5]int{9, 9, 9, 9, 9}}
+	TwentyOnePlusThreeFlat    = TwentyOnePlusThree{Variant: "Flat", Odds: [5]int{5, 5, 5, 5, 5}}
+	TwentyOnePlusThreeTiered  = TwentyOnePlusThree{Variant: "Tiered", Odds: [5]int{20, 30, 20, 10, 5}}
+	TwentyOnePlusThreeXtreme  = TwentyOnePlusThree{Variant: "Xtreme", Odds: [5]int{100, 40, 30, 10, 5}}
+)
+
+func (b TwentyOnePlusThree) Name() string {
+	if b.Variant == "" {
+		return "21+3"
+	}
+	return "21+3 " + b.Variant
+}
+
+func (TwentyOnePlusThree) Resolves() Timing { return AfterDeal }
+
+func (b TwentyOnePlusThree) Paytable() []Payout {
+	paytable := make([]Payout, 0, len(b.Odds))
+	for i, odds := range b.Odds {
+		paytable = append(paytable, Payout{Hand: twentyOnePlusThreeHands[i], Odds: odds})
+	}
+	return paytable
+}
+
+func (TwentyOnePlusThree) Evaluate(t *game.Table, hand player.Hand) []int {
+	if len(hand.Cards) < 2 || hand.Split {
+		return nil
+	}
+
+	// only the first two cards count, however many the hand has by now
+	firstTwo := hand
+	firstTwo.Cards = hand.Cards[:2]
+	threeCards := HandToTrifectaHand(t, firstTwo)
+
+	switch {
+	case IsTrifectaTrips(t, firstTwo, true):
+		return []int{0}
+	case rules.IsStraightFlush(threeCards):
+		return []int{1}
+	case IsTrifectaTrips(t, firstTwo, false):
+		return []int{2}
+	case rules.IsStraight(threeCards):
+		return []int{3}
+	case rules.IsFlush(threeCards):
+		return []int{4}
+	}
+	return nil
+}
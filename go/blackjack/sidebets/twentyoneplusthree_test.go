@@ -0,0 +1,53 @@
+package sidebets
+
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/player"
+	"testing"
+)
+
+func TestTwentyOnePlusThree(t *testing.T) {
+	tests := []struct {
+		name  string
+		up    cards.Card
+		cards []cards.Card
+		want  int
+	}{
+		{"suited trips", cards.CreateCard(cards.Hearts, cards.Nine), []cards.Card{cards.CreateCard(cards.Hearts, cards.Nine), cards.CreateCard(cards.Hearts, cards.Nine)}, 0},
+		{"straight flush", cards.CreateCard(cards.Clubs, cards.Jack), []cards.Card{cards.CreateCard(cards.Clubs, cards.Nine), cards.CreateCard(cards.Clubs, cards.Ten)}, 1},
+		{"trips", cards.CreateCard(cards.Hearts, cards.Four), []cards.Card{cards.CreateCard(cards.Spades, cards.Four), cards.CreateCard(cards.Hearts, cards.Four)}, 2},
+		{"ace low straight", cards.CreateCard(cards.Hearts, cards.Three), []cards.Card{cards.CreateCard(cards.Spades, cards.Ace), cards.CreateCard(cards.Clubs, cards.Two)}, 3},
+		{"ace high straight", cards.CreateCard(cards.Hearts, cards.Queen), []cards.Card{cards.CreateCard(cards.Spades, cards.King), cards.CreateCard(cards.Clubs, cards.Ace)}, 3},
+		{"flush", cards.CreateCard(cards.Diamonds, cards.Two), []cards.Card{cards.CreateCard(cards.Diamonds, cards.Eight), cards.CreateCard(cards.Diamonds, cards.King)}, 4},
+		{"nothing", cards.CreateCard(cards.Diamonds, cards.Two), []cards.Card{cards.CreateCard(cards.Spades, cards.Eight), cards.CreateCard(cards.Diamonds, cards.King)}, -1},
+	}
+
+	for _, test := range tests {
+		table := setupDealer(test.up)
+
+		lines := TwentyOnePlusThreeXtreme.Evaluate(table, playerHand(test.cards))
+		checkLine(t, test.name, lines, test.want)
+	}
+}
+
+func TestTwentyOnePlusThreePaytables(t *testing.T) {
+	table := setupDealer(cards.CreateCard(cards.Clubs, cards.Jack))
+	p := player.Player{}
+	hand := player.Hand{Cards: []cards.Card{cards.CreateCard(cards.Clubs, cards.Nine), cards.CreateCard(cards.Clubs, cards.Ten), cards.CreateCard(cards.Hearts, cards.Two)}, Player: &p}
+
+	for bet, want := range map[TwentyOnePlusThree]int{
+		TwentyOnePlusThreeClassic: 90,
+		TwentyOnePlusThreeFlat:    50,
+		TwentyOnePlusThreeTiered:  300,
+		TwentyOnePlusThreeXtreme:  400,
+	} {
+		if got := Pay(table, bet, bet.Evaluate(table, hand), 10, 0); got != want {
+			t.Errorf("%s straight flush on 10: got %d want %d", bet.Name(), got, want)
+		}
+	}
+
+	if _, err := Parse("21+3-xtreme", game.Blackjack); err != nil {
+		t.Fatalf("21+3-xtreme should parse: %v", err)
+	}
+}
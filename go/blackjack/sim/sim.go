@@ -0,0 +1,285 @@
+package sim
+
+import (
+	"blackjack/dealer"
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+	"blackjack/ui"
+
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultRounds is how many rounds sim plays when -rounds is not given.
+const DefaultRounds = 1000000
+
+// simStack is large enough that a simulated player never has to reload.
+const simStack = 1 << 40
+
+// simBankroll is the bankroll, in minimum bets, kelly bets from when
+// -bankroll is not given.
+const simBankroll = 1000
+
+// z95 is the z-score of a 95% confidence interval.
+const z95 = 1.96
+
+// Results are the totals of one or more simulated tables. Amounts are in
+// units of the table minimum so they do not depend on it.
+type Results struct {
+	Seed              int64
+	Tables            int
+	Rounds            int
+	PlayerRounds      int // one per player per round
+	Hands             int // includes split hands
+	Wins              int
+	Losses            int
+	Pushes            int
+	Wagered           float64 // initial wagers, before doubles and splits
+	Net               float64
+	NetSquared        float64
+	PlayerBlackjacks  int
+	PlayerBusts       int
+	DealerBlackjacks  int
+	DealerBusts       int
+	Sidebets          int
+	SidebetNet        float64
+	SidebetNetSquared float64
+	Shuffles          int
+}
+
+// headless is a ui.IO that renders nothing. Autoplay answers every question,
+// so it is never asked to read an action.
+type headless struct{}
+
+func (headless) ReadAction() (rune, error) { return 'x', nil }
+func (headless) Render(ui.GameState)       {}
+
+// Run plays cfg.Rounds rounds of mode spread across cfg.Tables tables, each
+// with its own shoe and random source, in parallel. Table i is seeded with
+// the run's seed plus i, so a run can be repeated with the same -seed and
+// -tables.
+func Run(cfg flags.Config, mode game.Game) Results {
+	tables := cfg.Tables
+	if tables <= 0 {
+		tables = 1
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	results := make([]Results, tables)
+	var wg sync.WaitGroup
+	for i := 0; i < tables; i++ {
+		rounds := cfg.Rounds / tables
+		if i < cfg.Rounds%tables {
+			rounds += 1
+		}
+
+		wg.Add(1)
+		go func(i int, rounds int) {
+			defer wg.Done()
+			results[i] = PlayTable(cfg, mode, seed+int64(i), rounds)
+		}(i, rounds)
+	}
+	wg.Wait()
+
+	total := Results{Seed: seed}
+	for _, result := range results {
+		total = Combine(total, result)
+	}
+
+	return total
+}
+
+// PlayTable plays rounds rounds at a single unsaved table seeded with seed.
+// Every player bets by cfg.Betting, plays the autoplay strategy and plays the
+// sidebet whenever cfg.TrifectaStax pays it.
+func PlayTable(cfg flags.Config, mode game.Game, seed int64, rounds int) Results {
+	cfg.Seed = seed
+	cfg.Clean = true
+	cfg.Autoplay = true
+	cfg.Rounds = 0
+	cfg.PlayerStartStack = simStack
+	cfg.HistoryPath = ""
+	cfg.ProgressivesPath = ""
+	if cfg.MinWager <= 0 {
+		cfg.MinWager = 1
+	}
+	if cfg.Bankroll <= 0 {
+		// kelly would otherwise size its bets from the bottomless sim stack
+		cfg.Bankroll = simBankroll * cfg.MinWager
+	}
+
+	t := dealer.OpenTable(cfg, mode, "")
+	dealer.AutoplayPlayers(t, cfg)
+
+	for i := 0; i < len(t.State.Players); i++ {
+		t.State.Players[i].WillPlaySidebet = func(name string, stack int) bool {
+			return cfg.TrifectaStax
+		}
+	}
+
+	results := Results{Tables: 1}
+	stacks := make([]int, len(t.State.Players))
+	minimum := float64(cfg.MinWager)
+
+	for round := 0; round < rounds; round++ {
+		for i := 0; i < len(t.State.Players); i++ {
+			stacks[i] = t.State.Players[i].Stack
+		}
+
+		dealt := t.State.Shoe.Index
+		dealer.DealRound(t, headless{}, cfg)
+		results.Rounds += 1
+		if t.State.Shoe.Index < dealt || t.State.Shoe.Index == 0 {
+			// dealing only moves on through the shoe, and a continuous
+			// shuffler starts it over after every round
+			results.Shuffles += 1
+		}
+
+		dealerHand := t.State.Dealer.Hands[0]
+		if rules.IsBlackjack(dealerHand) {
+			results.DealerBlackjacks += 1
+		} else if player.HandValue(&dealerHand, true) > 21 {
+			results.DealerBusts += 1
+		}
+
+		for i := 0; i < len(t.State.Players); i++ {
+			cardPlayer := &t.State.Players[i]
+			if len(cardPlayer.Hands) == 0 {
+				continue
+			}
+
+			sidebetNet := 0
+			hand := cardPlayer.Hands[0]
+			for name, wager := range hand.Sidebets {
+				net := -wager
+				if winnings := hand.SidebetWinnings[name]; winnings > 0 {
+					net = winnings
+				}
+				sidebetNet += net
+
+				units := float64(net) / float64(wager)
+				results.Sidebets += 1
+				results.SidebetNet += units
+				results.SidebetNetSquared += units * units
+			}
+
+			net := cardPlayer.Stack - stacks[i] - sidebetNet
+			units := float64(net) / minimum
+			results.PlayerRounds += 1
+			results.Wagered += float64(cardPlayer.LastWager) / minimum
+			results.Net += units
+			results.NetSquared += units * units
+
+			if net > 0 {
+				results.Wins += 1
+			} else if net < 0 {
+				results.Losses += 1
+			} else {
+				results.Pushes += 1
+			}
+
+			if rules.IsBlackjack(cardPlayer.Hands[0]) {
+				results.PlayerBlackjacks += 1
+			}
+
+			for j := 0; j < len(cardPlayer.Hands); j++ {
+				hand := cardPlayer.Hands[j]
+				results.Hands += 1
+				if !hand.Surrendered && player.HandValue(&hand, true) > 21 {
+					results.PlayerBusts += 1
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+// Combine adds two sets of results together, keeping a's seed.
+func Combine(a Results, b Results) Results {
+	return Results{
+		Seed:              a.Seed,
+		Tables:            a.Tables + b.Tables,
+		Rounds:            a.Rounds + b.Rounds,
+		PlayerRounds:      a.PlayerRounds + b.PlayerRounds,
+		Hands:             a.Hands + b.Hands,
+		Wins:              a.Wins + b.Wins,
+		Losses:            a.Losses + b.Losses,
+		Pushes:            a.Pushes + b.Pushes,
+		Wagered:           a.Wagered + b.Wagered,
+		Net:               a.Net + b.Net,
+		NetSquared:        a.NetSquared + b.NetSquared,
+		PlayerBlackjacks:  a.PlayerBlackjacks + b.PlayerBlackjacks,
+		PlayerBusts:       a.PlayerBusts + b.PlayerBusts,
+		DealerBlackjacks:  a.DealerBlackjacks + b.DealerBlackjacks,
+		DealerBusts:       a.DealerBusts + b.DealerBusts,
+		Sidebets:          a.Sidebets + b.Sidebets,
+		SidebetNet:        a.SidebetNet + b.SidebetNet,
+		SidebetNetSquared: a.SidebetNetSquared + b.SidebetNetSquared,
+		Shuffles:          a.Shuffles + b.Shuffles,
+	}
+}
+
+// Ratio is part/whole, 0 when whole is 0.
+func Ratio(part int, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// MeanAndInterval returns the mean of n samples from their sum and sum of
+// squares, their standard deviation and the half width of the mean's 95%
+// confidence interval.
+func MeanAndInterval(sum float64, sumSquared float64, n int) (float64, float64, float64) {
+	if n == 0 {
+		return 0, 0, 0
+	}
+
+	mean := sum / float64(n)
+	if n == 1 {
+		return mean, 0, 0
+	}
+
+	variance := (sumSquared - sum*mean) / float64(n-1)
+	stdDev := math.Sqrt(math.Max(variance, 0))
+
+	return mean, stdDev, z95 * stdDev / math.Sqrt(float64(n))
+}
+
+func PrintResults(results Results) {
+	ev, stdDev, evInterval := MeanAndInterval(results.Net, results.NetSquared, results.PlayerRounds)
+
+	fmt.Printf("Simulated %d rounds at %d tables (seed %d)\n", results.Rounds, results.Tables, results.Seed)
+	fmt.Printf("  Player rounds:     %d (%d hands)\n", results.PlayerRounds, results.Hands)
+	fmt.Printf("  Win rate:          %6.2f%%  (lose %.2f%%, push %.2f%%)\n",
+		100*Ratio(results.Wins, results.PlayerRounds),
+		100*Ratio(results.Losses, results.PlayerRounds),
+		100*Ratio(results.Pushes, results.PlayerRounds))
+	fmt.Printf("  EV per hand:       %+.4f ± %.4f units (95%% CI)\n", ev, evInterval)
+	if averageBet := results.Wagered / float64(results.PlayerRounds); results.PlayerRounds > 0 && averageBet != 1 {
+		fmt.Printf("  Average bet:       %.2f units (%+.2f%% of the money bet)\n", averageBet, 100*ev/averageBet)
+	}
+	fmt.Printf("  Std deviation:     %.4f units\n", stdDev)
+	fmt.Printf("  Player blackjacks: %6.2f%%\n", 100*Ratio(results.PlayerBlackjacks, results.PlayerRounds))
+	fmt.Printf("  Player busts:      %6.2f%% of hands\n", 100*Ratio(results.PlayerBusts, results.Hands))
+	fmt.Printf("  Dealer blackjacks: %6.2f%%\n", 100*Ratio(results.DealerBlackjacks, results.Rounds))
+	fmt.Printf("  Dealer busts:      %6.2f%%\n", 100*Ratio(results.DealerBusts, results.Rounds))
+	if results.Shuffles > 0 {
+		fmt.Printf("  Shuffles:          %d, one every %.1f rounds\n", results.Shuffles, float64(results.Rounds)/float64(results.Shuffles))
+	}
+
+	if results.Sidebets > 0 {
+		sidebetEV, _, sidebetInterval := MeanAndInterval(results.SidebetNet, results.SidebetNetSquared, results.Sidebets)
+		// the house edge is the player's expected loss per unit wagered
+		fmt.Printf("  Sidebet edge:      %+.2f%% ± %.2f%% (95%% CI, %d bets)\n", -100*sidebetEV, 100*sidebetInterval, results.Sidebets)
+	}
+}
@@ -0,0 +1,63 @@
+package sim
+
+import (
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/rules"
+	"math"
+	"testing"
+)
+
+func simConfig() flags.Config {
+	return flags.Config{
+		NumOfDecks:   6,
+		NumOfPlayers: 2,
+		MinWager:     10,
+		TrifectaStax: true,
+		Rounds:       10,
+		Tables:       3,
+		TableRules:   rules.DefaultTableRules(),
+	}
+}
+
+func TestPlayTableIsRepeatable(t *testing.T) {
+	cfg := simConfig()
+	// blackjack has no sidebet of its own
+	cfg.Sidebets = "trifecta"
+
+	first := PlayTable(cfg, game.Blackjack, 42, 500)
+	second := PlayTable(cfg, game.Blackjack, 42, 500)
+	if first != second {
+		t.Fatalf("same seed gave different results:\n%+v\n%+v", first, second)
+	}
+	if first.PlayerRounds != 500*cfg.NumOfPlayers {
+		t.Fatalf("PlayerRounds=%d want %d", first.PlayerRounds, 500*cfg.NumOfPlayers)
+	}
+	if first.Wins+first.Losses+first.Pushes != first.PlayerRounds {
+		t.Fatalf("wins, losses and pushes should add up to every player round: %+v", first)
+	}
+	if first.Sidebets != first.PlayerRounds {
+		t.Fatalf("Sidebets=%d want one per player round", first.Sidebets)
+	}
+}
+
+func TestRunSplitsRoundsAcrossTables(t *testing.T) {
+	results := Run(simConfig(), game.Spanish21)
+	if results.Rounds != 10 || results.Tables != 3 {
+		t.Fatalf("Rounds=%d Tables=%d want 10 and 3", results.Rounds, results.Tables)
+	}
+}
+
+func TestMeanAndInterval(t *testing.T) {
+	// samples 1, -1, 1, -1
+	mean, stdDev, interval := MeanAndInterval(0, 4, 4)
+	if mean != 0 {
+		t.Fatalf("mean=%f want 0", mean)
+	}
+	if math.Abs(stdDev-math.Sqrt(4.0/3.0)) > 1e-9 {
+		t.Fatalf("stdDev=%f want %f", stdDev, math.Sqrt(4.0/3.0))
+	}
+	if math.Abs(interval-z95*stdDev/2) > 1e-9 {
+		t.Fatalf("interval=%f want %f", interval, z95*stdDev/2)
+	}
+}
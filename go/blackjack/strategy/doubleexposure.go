@@ -0,0 +1,61 @@
+package strategy
+
+import (
+	"blackjack/cards"
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/rules"
+)
+
+type doubleExposureKey struct {
+	decks      int
+	tableRules rules.TableRules
+	total      int
+	hasAce     bool
+}
+
+var doubleExposureCache = make(map[doubleExposureKey]*rules.StrategyTable)
+
+// ForRound returns the strategy the round being dealt is played by: the
+// table's basic strategy, or in Double Exposure the chart against both of the
+// dealer's face up cards, knowing the dealer wins ties. That chart is the same
+// in every upcard column, so it is looked up like any other.
+func ForRound(t *game.Table, cfg flags.Config) *rules.StrategyTable {
+	if t.Mode != game.DoubleExposure || len(t.State.Dealer.Hands) == 0 || len(t.State.Dealer.Hands[0].Cards) < 2 {
+		return ForTable(t, cfg)
+	}
+
+	dealerCards := t.State.Dealer.Hands[0].Cards
+
+	first, second := cards.CardToValue(dealerCards[0], true), cards.CardToValue(dealerCards[1], true)
+	key := doubleExposureKey{decks: cfg.NumOfDecks, tableRules: rules.ForGame(t.Mode, cfg.TableRules), total: first + second, hasAce: first == 1 || second == 1}
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if strategyTable, ok := doubleExposureCache[key]; ok {
+		return strategyTable
+	}
+
+	shoe := Composition(key.decks, t.Mode)
+	shoe[first] -= 1
+	shoe[second] -= 1
+
+	e := &evaluator{dealerWinsTies: true}
+	remaining := 0
+	for value := 1; value <= 10; value++ {
+		remaining += shoe[value]
+	}
+	for value := 1; value <= 10; value++ {
+		e.draw[value] = float64(shoe[value]) / float64(remaining)
+	}
+	dealerDraws(&shoe, remaining, key.total, key.hasAce, key.tableRules.DealerHitsSoft17, 1, &e.dealer)
+
+	strategyTable := &rules.StrategyTable{}
+	for up := 1; up <= 10; up++ {
+		fillColumn(strategyTable, up, e, surrenderEV(0, key.tableRules), key.tableRules)
+	}
+	doubleExposureCache[key] = strategyTable
+
+	return strategyTable
+}
@@ -0,0 +1,31 @@
+package strategy
+
+import (
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+	"testing"
+)
+
+func TestForRoundDoubleExposure(t *testing.T) {
+	cfg := flags.Config{NumOfDecks: 6, TableRules: rules.DefaultTableRules()}
+	table := game.NewTable(game.DoubleExposure, 1)
+
+	table.State.Dealer.Hands = []player.Hand{player.ToHand([]string{"♠10", "♥K"})}
+	if decision := ForRound(table, cfg).Hard[19][10]; decision.Action != 'h' {
+		t.Fatalf("19 loses to a dealer 20 and should hit, got %c", decision.Action)
+	}
+
+	table.State.Dealer.Hands = []player.Hand{player.ToHand([]string{"♠10", "♥6"})}
+	if decision := ForRound(table, cfg).Hard[11][10]; decision.Action != 'd' {
+		t.Fatalf("11 against a dealer 16 should double, got %c", decision.Action)
+	}
+	if decision := ForRound(table, cfg).Hard[13][10]; decision.Action != 's' {
+		t.Fatalf("13 against a dealer 16 should stand, got %c", decision.Action)
+	}
+
+	if ForRound(game.NewTable(game.Blackjack, 1), cfg) != ForTable(game.NewTable(game.Blackjack, 1), cfg) {
+		t.Fatalf("other games should play the table's basic strategy")
+	}
+}
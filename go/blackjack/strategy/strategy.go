@@ -0,0 +1,418 @@
+package strategy
+
+import (
+	"blackjack/cards"
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/rules"
+	"sync"
+)
+
+// Shoe counts the cards left of each value, index 1 is an ace and 10 is any
+// ten-valued card.
+type Shoe [11]int
+
+// dealerOutcomes are the chances the dealer finishes on 17, 18, 19, 20, 21,
+// busts on more than 22, or finishes on exactly 22.
+type dealerOutcomes [7]float64
+
+const (
+	dealerBusts = 5
+	dealer22    = 6
+)
+
+// evaluator holds what the player's EVs against one dealer upcard depend on,
+// the chance of drawing each value and the dealer's chance of each outcome.
+// extraAtRisk is what each further wager put out on a double or split costs
+// when a dealer blackjack found after the players act takes it too.
+type evaluator struct {
+	draw           [11]float64
+	dealer         dealerOutcomes
+	hit            [32][2]float64
+	hitSet         [32][2]bool
+	extraAtRisk    float64
+	dealerWinsTies bool
+	dealerPushes22 bool
+	leastStand     int  // the least a hand may stand on, Pontoon's 15
+	buyPlaysOn     bool // a double is a Pontoon buy, after which the hand plays on
+}
+
+type cacheKey struct {
+	decks                int
+	mode                 game.Game
+	tableRules           rules.TableRules
+	compositionDependent bool
+}
+
+var cache = make(map[cacheKey]*rules.StrategyTable)
+var cacheMutex sync.Mutex
+
+// ForTable returns the basic strategy for the table's game under cfg,
+// generating it the first time it is asked for.
+func ForTable(t *game.Table, cfg flags.Config) *rules.StrategyTable {
+	key := cacheKey{decks: cfg.NumOfDecks, mode: t.Mode, tableRules: rules.ForGame(t.Mode, cfg.TableRules), compositionDependent: cfg.CompositionDependent}
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if strategyTable, ok := cache[key]; ok {
+		return strategyTable
+	}
+
+	strategyTable := Generate(key.decks, key.mode, key.tableRules, key.compositionDependent)
+	cache[key] = strategyTable
+
+	return strategyTable
+}
+
+// Composition is a full shoe of decks decks for mode.
+func Composition(decks int, mode game.Game) Shoe {
+	shoe := Shoe{}
+	decks = game.DecksFor(mode, decks)
+
+	for _, value := range cards.CardValues {
+		if game.InDeck(mode, value) {
+			shoe[cards.CardToValue(cards.CreateCard(cards.Spades, value), true)] += len(cards.Suites) * decks
+		}
+	}
+
+	return shoe
+}
+
+// Generate works out basic strategy for decks decks of mode under tableRules
+// from exact dealer probabilities. The dealer draws from the shoe less the
+// upcard without replacement. The total-dependent charts assume the player
+// draws from that same shoe; composition-dependent two card plays also remove
+// the player's two cards. Splits are worked out as two hands that are not
+// split again.
+func Generate(decks int, mode game.Game, tableRules rules.TableRules, compositionDependent bool) *rules.StrategyTable {
+	strategyTable := &rules.StrategyTable{CompositionDependent: compositionDependent}
+	fullShoe := Composition(decks, mode)
+
+	for up := 1; up <= 10; up++ {
+		shoe := fullShoe
+		if shoe[up] == 0 {
+			continue
+		}
+		shoe[up] -= 1
+
+		e, blackjackChance := newEvaluator(shoe, up, mode, tableRules)
+		fillColumn(strategyTable, up, e, surrenderEV(blackjackChance, tableRules), tableRules)
+	}
+
+	if compositionDependent {
+		for first := 1; first <= 10; first++ {
+			for second := first; second <= 10; second++ {
+				for up := 1; up <= 10; up++ {
+					decision := twoCardDecision(strategyTable, fullShoe, mode, first, second, up, tableRules)
+					strategyTable.TwoCards[first][second][up] = decision
+					strategyTable.TwoCards[second][first][up] = decision
+				}
+			}
+		}
+	}
+
+	return strategyTable
+}
+
+// fillColumn charts every hard, soft and pair hand against the dealer hand e
+// was made for, in column up.
+func fillColumn(strategyTable *rules.StrategyTable, up int, e *evaluator, surrender float64, tableRules rules.TableRules) {
+	for total := 4; total <= 21; total++ {
+		strategyTable.Hard[total][up], _ = decide(e, total, false, surrender, tableRules)
+	}
+
+	for total := 12; total <= 21; total++ {
+		strategyTable.Soft[total][up], _ = decide(e, total-10, true, surrender, tableRules)
+	}
+
+	for value := 1; value <= 10; value++ {
+		decision, ev := decide(e, 2*value, value == 1, surrender, tableRules)
+		if canSplitPairs(tableRules) && splitEV(e, value, tableRules) > ev {
+			decision = rules.Decision{Action: 'p', Fallback: decision.Fallback}
+		}
+		strategyTable.Pairs[value][up] = decision
+	}
+}
+
+// twoCardDecision plays first and second against up with all three cards out
+// of the shoe, falling back to the total-dependent chart when the shoe does
+// not hold them.
+func twoCardDecision(strategyTable *rules.StrategyTable, shoe Shoe, mode game.Game, first int, second int, up int, tableRules rules.TableRules) rules.Decision {
+	total := first + second
+	hasAce := first == 1 || second == 1
+
+	shoe[first] -= 1
+	shoe[second] -= 1
+	shoe[up] -= 1
+	if shoe[first] < 0 || shoe[second] < 0 || shoe[up] < 0 {
+		if hasAce && total+10 <= 21 {
+			return strategyTable.Soft[total+10][up]
+		}
+		return strategyTable.Hard[total][up]
+	}
+
+	e, blackjackChance := newEvaluator(shoe, up, mode, tableRules)
+	decision, _ := decide(e, total, hasAce, surrenderEV(blackjackChance, tableRules), tableRules)
+
+	return decision
+}
+
+func newEvaluator(shoe Shoe, up int, mode game.Game, tableRules rules.TableRules) (*evaluator, float64) {
+	e := &evaluator{dealerWinsTies: rules.TiesLose(mode), dealerPushes22: rules.Pushes22(mode)}
+	if mode == game.Pontoon {
+		e.leastStand = rules.PontoonStick
+		e.buyPlaysOn = true
+	}
+
+	remaining := 0
+	for value := 1; value <= 10; value++ {
+		remaining += shoe[value]
+	}
+	for value := 1; value <= 10; value++ {
+		e.draw[value] = float64(shoe[value]) / float64(remaining)
+	}
+
+	var blackjackChance float64
+	e.dealer, blackjackChance = dealerProbabilities(shoe, up, tableRules.DealerHitsSoft17)
+	if tableRules.NoHoleCard && !tableRules.OriginalBetsOnly && blackjackChance < 1 {
+		e.extraAtRisk = blackjackChance / (1 - blackjackChance)
+	}
+
+	return e, blackjackChance
+}
+
+// dealerProbabilities returns the dealer's outcomes given they do not have
+// blackjack, since players only act once the dealer has checked, and the
+// chance they do.
+func dealerProbabilities(shoe Shoe, up int, hitsSoft17 bool) (dealerOutcomes, float64) {
+	outcomes := dealerOutcomes{}
+	blackjackChance := 0.0
+
+	remaining := 0
+	for value := 1; value <= 10; value++ {
+		remaining += shoe[value]
+	}
+
+	for hole := 1; hole <= 10; hole++ {
+		if shoe[hole] == 0 {
+			continue
+		}
+
+		chance := float64(shoe[hole]) / float64(remaining)
+		if (up == 1 && hole == 10) || (up == 10 && hole == 1) {
+			blackjackChance += chance
+			continue
+		}
+
+		shoe[hole] -= 1
+		dealerDraws(&shoe, remaining-1, up+hole, up == 1 || hole == 1, hitsSoft17, chance, &outcomes)
+		shoe[hole] += 1
+	}
+
+	for i := range outcomes {
+		outcomes[i] /= 1 - blackjackChance
+	}
+
+	return outcomes, blackjackChance
+}
+
+// dealerDraws adds the chance of each way the dealer can finish from total to
+// outcomes, drawing without replacement from shoe.
+func dealerDraws(shoe *Shoe, remaining int, total int, hasAce bool, hitsSoft17 bool, chance float64, outcomes *dealerOutcomes) {
+	if total == 22 {
+		outcomes[dealer22] += chance
+		return
+	}
+	if total > 21 {
+		outcomes[dealerBusts] += chance
+		return
+	}
+
+	best, soft := bestTotal(total, hasAce)
+	if best > 17 || (best == 17 && !(soft && hitsSoft17)) {
+		outcomes[best-17] += chance
+		return
+	}
+
+	for value := 1; value <= 10; value++ {
+		if shoe[value] == 0 {
+			continue
+		}
+
+		drawChance := float64(shoe[value]) / float64(remaining)
+		shoe[value] -= 1
+		dealerDraws(shoe, remaining-1, total+value, hasAce || value == 1, hitsSoft17, chance*drawChance, outcomes)
+		shoe[value] += 1
+	}
+}
+
+// bestTotal counts one ace as 11 when that does not bust the hand.
+func bestTotal(total int, hasAce bool) (int, bool) {
+	if hasAce && total+10 <= 21 {
+		return total + 10, true
+	}
+	return total, false
+}
+
+func standEV(e *evaluator, total int, hasAce bool) float64 {
+	best, _ := bestTotal(total, hasAce)
+	if best > 21 {
+		return -1
+	}
+
+	ev := e.dealer[dealerBusts]
+	if !e.dealerPushes22 {
+		ev += e.dealer[dealer22]
+	}
+	for i := 0; i < dealerBusts; i++ {
+		dealerTotal := 17 + i
+		if best > dealerTotal {
+			ev += e.dealer[i]
+		} else if best < dealerTotal || e.dealerWinsTies {
+			ev -= e.dealer[i]
+		}
+	}
+
+	return ev
+}
+
+// hitEV is the EV of taking a card and then playing on as well as possible.
+func hitEV(e *evaluator, total int, hasAce bool) float64 {
+	aceIndex := 0
+	if hasAce {
+		aceIndex = 1
+	}
+	if e.hitSet[total][aceIndex] {
+		return e.hit[total][aceIndex]
+	}
+
+	ev := 0.0
+	for value := 1; value <= 10; value++ {
+		ev += e.draw[value] * playOnEV(e, total+value, hasAce || value == 1)
+	}
+
+	e.hit[total][aceIndex] = ev
+	e.hitSet[total][aceIndex] = true
+
+	return ev
+}
+
+// playOnEV is the better of standing and hitting a hand that can no longer
+// double or surrender.
+func playOnEV(e *evaluator, total int, hasAce bool) float64 {
+	if total > 21 {
+		return -1
+	}
+
+	best, _ := bestTotal(total, hasAce)
+	if best < e.leastStand {
+		return hitEV(e, total, hasAce)
+	}
+
+	stand := standEV(e, total, hasAce)
+	if best == 21 {
+		return stand
+	}
+
+	if hit := hitEV(e, total, hasAce); hit > stand {
+		return hit
+	}
+
+	return stand
+}
+
+// doubleEV is the EV of doubling and standing on the one card it takes, or of
+// a Pontoon buy, which twists or sticks as well as possible after its card.
+func doubleEV(e *evaluator, total int, hasAce bool) float64 {
+	ev := 0.0
+	for value := 1; value <= 10; value++ {
+		if e.buyPlaysOn {
+			ev += e.draw[value] * playOnEV(e, total+value, hasAce || value == 1)
+		} else {
+			ev += e.draw[value] * standEV(e, total+value, hasAce || value == 1)
+		}
+	}
+
+	return 2*ev - e.extraAtRisk
+}
+
+// splitEV is the EV of both hands of a split value pair, neither of which is
+// split again.
+func splitEV(e *evaluator, value int, tableRules rules.TableRules) float64 {
+	ev := 0.0
+	for drawn := 1; drawn <= 10; drawn++ {
+		total := value + drawn
+		hasAce := value == 1 || drawn == 1
+
+		var handEV float64
+		if value == 1 && !tableRules.HitSplitAces {
+			// split aces get one card each
+			handEV = standEV(e, total, hasAce)
+		} else {
+			handEV = playOnEV(e, total, hasAce)
+			best, _ := bestTotal(total, hasAce)
+			if value != 1 && tableRules.DoubleAfterSplit && canDouble(best, tableRules) {
+				if double := doubleEV(e, total, hasAce); double > handEV {
+					handEV = double
+				}
+			}
+		}
+
+		ev += e.draw[drawn] * handEV
+	}
+
+	return 2*ev - e.extraAtRisk
+}
+
+// surrenderEV is what surrendering is worth among the hands the dealer does
+// not have blackjack against. Early surrender, and any surrender without a
+// hole card, also gets out of the hands they do, so it is worth more.
+func surrenderEV(blackjackChance float64, tableRules rules.TableRules) float64 {
+	if tableRules.Surrender == rules.EarlySurrender || tableRules.NoHoleCard && tableRules.Surrender != rules.NoSurrender {
+		return (blackjackChance - .5) / (1 - blackjackChance)
+	}
+	return -.5
+}
+
+func canDouble(best int, tableRules rules.TableRules) bool {
+	if tableRules.DoubleNineToEleven {
+		return best >= 9 && best <= 11
+	}
+	return best < 21
+}
+
+func canSplitPairs(tableRules rules.TableRules) bool {
+	return tableRules.MaxSplitHands == 0 || tableRules.MaxSplitHands >= 2
+}
+
+// decide picks the best play for a two card total against e's upcard and
+// returns its EV.
+func decide(e *evaluator, total int, hasAce bool, surrender float64, tableRules rules.TableRules) (rules.Decision, float64) {
+	best, _ := bestTotal(total, hasAce)
+	stand := standEV(e, total, hasAce)
+
+	decision := rules.Decision{Action: 's', Fallback: 's'}
+	ev := stand
+
+	if best < 21 {
+		if hit := hitEV(e, total, hasAce); hit > stand || best < e.leastStand {
+			decision = rules.Decision{Action: 'h', Fallback: 'h'}
+			ev = hit
+		}
+	}
+
+	if best < 21 && canDouble(best, tableRules) {
+		if double := doubleEV(e, total, hasAce); double > ev {
+			decision.Action = 'd'
+			ev = double
+		}
+	}
+
+	if tableRules.Surrender != rules.NoSurrender && surrender > ev {
+		decision.Action = 'u'
+		ev = surrender
+	}
+
+	return decision, ev
+}
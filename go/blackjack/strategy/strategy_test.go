@@ -0,0 +1,126 @@
+package strategy
+
+import (
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/rules"
+	"math"
+	"testing"
+)
+
+func TestComposition(t *testing.T) {
+	shoe := Composition(6, game.Blackjack)
+	if shoe[1] != 24 || shoe[5] != 24 || shoe[10] != 96 {
+		t.Fatalf("unexpected 6 deck shoe %v", shoe)
+	}
+
+	spanish := Composition(1, game.Spanish21)
+	if spanish[10] != 12 || spanish[1] != 4 {
+		t.Fatalf("a Spanish21 deck has no 10s, got %v", spanish)
+	}
+}
+
+func TestGenerateMatchesBasicStrategy(t *testing.T) {
+	strategyTable := Generate(6, game.Blackjack, rules.DefaultTableRules(), false)
+
+	tests := []struct {
+		name     string
+		decision rules.Decision
+		want     rules.Decision
+	}{
+		{"hard 12 v 2", strategyTable.Hard[12][2], rules.Decision{Action: 'h', Fallback: 'h'}},
+		{"hard 12 v 4", strategyTable.Hard[12][4], rules.Decision{Action: 's', Fallback: 's'}},
+		{"hard 16 v 10", strategyTable.Hard[16][10], rules.Decision{Action: 'h', Fallback: 'h'}},
+		{"hard 11 v 6", strategyTable.Hard[11][6], rules.Decision{Action: 'd', Fallback: 'h'}},
+		{"hard 11 v A", strategyTable.Hard[11][1], rules.Decision{Action: 'h', Fallback: 'h'}},
+		{"soft 18 v 2", strategyTable.Soft[18][2], rules.Decision{Action: 's', Fallback: 's'}},
+		{"soft 18 v 4", strategyTable.Soft[18][4], rules.Decision{Action: 'd', Fallback: 's'}},
+		{"soft 18 v 9", strategyTable.Soft[18][9], rules.Decision{Action: 'h', Fallback: 'h'}},
+		{"pair 8 v A", strategyTable.Pairs[8][1], rules.Decision{Action: 'p', Fallback: 'h'}},
+		{"pair 9 v 7", strategyTable.Pairs[9][7], rules.Decision{Action: 's', Fallback: 's'}},
+		{"pair 10 v 6", strategyTable.Pairs[10][6], rules.Decision{Action: 's', Fallback: 's'}},
+		{"pair A v A", strategyTable.Pairs[1][1], rules.Decision{Action: 'p', Fallback: 'h'}},
+	}
+
+	for _, test := range tests {
+		if test.decision != test.want {
+			t.Errorf("%s = %c%c want %c%c", test.name, test.decision.Action, test.decision.Fallback, test.want.Action, test.want.Fallback)
+		}
+	}
+}
+
+func TestGenerateFollowsTableRules(t *testing.T) {
+	tableRules := rules.DefaultTableRules()
+	tableRules.DealerHitsSoft17 = true
+	tableRules.Surrender = rules.LateSurrender
+
+	strategyTable := Generate(6, game.Blackjack, tableRules, false)
+	if decision := strategyTable.Hard[16][10]; decision.Action != 'u' || decision.Fallback != 'h' {
+		t.Fatalf("hard 16 v 10 = %c%c want uh", decision.Action, decision.Fallback)
+	}
+	if decision := strategyTable.Hard[11][1]; decision.Action != 'd' {
+		t.Fatalf("H17 hard 11 v A = %c want d", decision.Action)
+	}
+
+	tableRules = rules.DefaultTableRules()
+	tableRules.DoubleNineToEleven = true
+	strategyTable = Generate(6, game.Blackjack, tableRules, false)
+	if decision := strategyTable.Soft[18][4]; decision.Action != 's' {
+		t.Fatalf("soft 18 v 4 may not double on 9 to 11 only, got %c", decision.Action)
+	}
+
+	tableRules = rules.DefaultTableRules()
+	tableRules.NoHoleCard = true
+	tableRules.OriginalBetsOnly = false
+	strategyTable = Generate(6, game.Blackjack, tableRules, false)
+	if decision := strategyTable.Hard[11][10]; decision.Action != 'h' {
+		t.Fatalf("hard 11 v 10 should not double into a dealer blackjack that takes it, got %c", decision.Action)
+	}
+}
+
+func TestDealer22Pushes(t *testing.T) {
+	shoe := Composition(6, game.Blackjack)
+	shoe[6] -= 1
+
+	blackjack, _ := newEvaluator(shoe, 6, game.Blackjack, rules.DefaultTableRules())
+	freeBet, _ := newEvaluator(shoe, 6, game.FreeBet, rules.DefaultTableRules())
+	if freeBet.dealer[dealer22] < 0.05 {
+		t.Fatalf("dealer 22 against a 6 = %f, want over 5%%", freeBet.dealer[dealer22])
+	}
+
+	stand, pushed := standEV(blackjack, 16, false), standEV(freeBet, 16, false)
+	if math.Abs(stand-pushed-freeBet.dealer[dealer22]) > 1e-9 {
+		t.Fatalf("standing on 16 v 6 = %f, with 22 pushing %f, want %f less", stand, pushed, freeBet.dealer[dealer22])
+	}
+}
+
+func TestPontoonBuyPlaysOn(t *testing.T) {
+	pontoon, _ := newEvaluator(Composition(1, game.Pontoon), 10, game.Pontoon, rules.DefaultTableRules())
+
+	// a buy on 6 must twist again, which standing on its one card never does
+	standing := 0.0
+	for value := 1; value <= 10; value++ {
+		standing += pontoon.draw[value] * standEV(pontoon, 6+value, value == 1)
+	}
+	if buy := doubleEV(pontoon, 6, false); buy <= 2*standing {
+		t.Fatalf("buying on 6 v 10 = %f, want more than standing on the bought card %f", buy, 2*standing)
+	}
+}
+
+func TestCompositionDependent(t *testing.T) {
+	strategyTable := Generate(1, game.Blackjack, rules.DefaultTableRules(), true)
+	if strategyTable.TwoCards[7][9] != strategyTable.TwoCards[9][7] {
+		t.Fatalf("two card plays should not depend on card order")
+	}
+	if decision := strategyTable.TwoCards[10][2][4]; decision.Action != 's' && decision.Action != 'h' {
+		t.Fatalf("10,2 v 4 = %c, want a hit or stand", decision.Action)
+	}
+}
+
+func TestForTableCaches(t *testing.T) {
+	cfg := flags.Config{NumOfDecks: 2, TableRules: rules.DefaultTableRules()}
+	table := game.NewTable(game.Spanish21, 1)
+	if ForTable(table, cfg) != ForTable(table, cfg) {
+		t.Fatalf("expected the same strategy table for the same game")
+	}
+}
@@ -0,0 +1,58 @@
+package strategy
+
+import (
+	"blackjack/cards"
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+)
+
+// ShouldSwitch reports whether swapping the second cards of a Blackjack
+// Switch player's two hands leaves them worth more against the dealer's up
+// card, each hand valued at the EV of its best play with the dealt cards out
+// of the shoe.
+func ShouldSwitch(t *game.Table, cfg flags.Config, first player.Hand, second player.Hand) bool {
+	if len(first.Cards) != 2 || len(second.Cards) != 2 || len(t.State.Dealer.Hands) == 0 {
+		return false
+	}
+
+	tableRules := rules.ForGame(t.Mode, cfg.TableRules)
+	up := cards.CardToValue(t.State.Dealer.Hands[0].Cards[0], true)
+	a, b := cards.CardToValue(first.Cards[0], true), cards.CardToValue(first.Cards[1], true)
+	c, d := cards.CardToValue(second.Cards[0], true), cards.CardToValue(second.Cards[1], true)
+
+	shoe := Composition(cfg.NumOfDecks, t.Mode)
+	for _, value := range []int{up, a, b, c, d} {
+		shoe[value] -= 1
+	}
+
+	e, blackjackChance := newEvaluator(shoe, up, t.Mode, tableRules)
+	surrender := surrenderEV(blackjackChance, tableRules)
+
+	kept := twoCardEV(e, a, b, false, surrender, tableRules) + twoCardEV(e, c, d, false, surrender, tableRules)
+	switched := twoCardEV(e, a, d, true, surrender, tableRules) + twoCardEV(e, c, b, true, surrender, tableRules)
+	return switched > kept
+}
+
+// twoCardEV is what a two card hand is worth played as well as possible, a
+// blackjack what the table pays for one. A 21 made by switching is no
+// blackjack and is valued as any other 21.
+func twoCardEV(e *evaluator, first int, second int, switched bool, surrender float64, tableRules rules.TableRules) float64 {
+	hasAce := first == 1 || second == 1
+	if hasAce && first+second == 11 && !switched {
+		payout := tableRules.BlackjackPayout
+		if payout.Bet == 0 {
+			return 1
+		}
+		return float64(payout.Win) / float64(payout.Bet)
+	}
+
+	_, ev := decide(e, first+second, hasAce, surrender, tableRules)
+	if first == second && canSplitPairs(tableRules) {
+		if split := splitEV(e, first, tableRules); split > ev {
+			ev = split
+		}
+	}
+	return ev
+}
@@ -0,0 +1,33 @@
+package strategy
+
+import (
+	"blackjack/flags"
+	"blackjack/game"
+	"blackjack/player"
+	"blackjack/rules"
+	"testing"
+)
+
+func TestShouldSwitch(t *testing.T) {
+	cfg := flags.Config{NumOfDecks: 6, TableRules: rules.DefaultTableRules()}
+	tests := []struct {
+		up     string
+		first  []string
+		second []string
+		want   bool
+	}{
+		{"♠K", []string{"♥A", "♣6"}, []string{"♦10", "♠Q"}, true},
+		{"♠6", []string{"♥10", "♣K"}, []string{"♦5", "♠6"}, false},
+		{"♠6", []string{"♥10", "♣5"}, []string{"♦10", "♠6"}, false},
+		{"♠7", []string{"♥10", "♣2"}, []string{"♦9", "♠9"}, true},
+		{"♠2", []string{"♥3", "♣9"}, []string{"♦4", "♠10"}, true},
+	}
+
+	for _, test := range tests {
+		table := game.NewTable(game.Switch, 1)
+		table.State.Dealer.Hands = []player.Hand{player.ToHand([]string{test.up, "♥5"})}
+		if got := ShouldSwitch(table, cfg, player.ToHand(test.first), player.ToHand(test.second)); got != test.want {
+			t.Errorf("%v and %v against %s: switch %t want %t", test.first, test.second, test.up, got, test.want)
+		}
+	}
+}
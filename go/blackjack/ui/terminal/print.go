@@ -6,13 +6,16 @@ package terminal
 import (
 	"blackjack/cards"
 	"blackjack/constants"
+	"blackjack/deviations"
 	"blackjack/flags"
 	"blackjack/game"
 	"blackjack/player"
 	"blackjack/rules"
 	"blackjack/sidebets"
+	"blackjack/strategy"
 	"blackjack/utils"
 	"fmt"
+	"strings"
 	"unicode"
 )
 
@@ -83,65 +86,66 @@ func DrawHand(hand player.Hand, colorTerminal bool) {
 	fmt.Println()
 }
 
-func PrintAutoplayString(chr rune) string {
+func PrintAutoplayString(t *game.Table, chr rune) string {
 	switch chr {
-	case 'h':
-		return "HIT"
-	case 's':
-		return "STAND"
-	case 'd':
-		return "DOUBLE DOWN"
-	case 'p':
-		return "SPLIT"
+	case 'h', 's', 'd', 'p', 'u':
+		return strings.ToUpper(rules.ActionFor(t.Mode, chr).Name)
 	default:
 		return "?????"
 	}
 }
 
-func PrintAutoPlayTable() {
+func PrintAutoPlayTable(strategyTable *rules.StrategyTable) {
+	dealerCards := []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 1}
+
+	printRow := func(label string, decisions [11]rules.Decision) {
+		fmt.Printf("%-11s", label)
+		for _, dealerCard := range dealerCards {
+			decision := decisions[dealerCard]
+			switch decision.Action {
+			case 'h':
+				fmt.Print(constants.Red)
+			case 's':
+				fmt.Print(constants.Green)
+			case 'd':
+				fmt.Print(constants.Yellow)
+			case 'p':
+				fmt.Print(constants.Cyan)
+			case 'u':
+				fmt.Print(constants.Purple)
+			}
+			if decision.Action == 'd' || decision.Action == 'u' {
+				// otherwise hit or stand
+				fmt.Printf("░%c%c", unicode.ToUpper(decision.Action), decision.Fallback)
+			} else {
+				fmt.Printf("░%c░", unicode.ToUpper(decision.Action))
+			}
+			fmt.Print(constants.Reset)
+		}
+		fmt.Println()
+	}
+
 	fmt.Println(" AUTOPLAY TABLE")
 	fmt.Println("=============================================================================")
 	fmt.Print("Dealer ==> ")
-	cards.ForAllCardValues(func(card cards.Card) {
-		if card.Value != 1 {
-			fmt.Printf(" %s ", cards.CardValueToString[card.Value])
-		}
-	})
+	for _, dealerCard := range dealerCards {
+		fmt.Printf(" %-2s", cards.CardValueToString[cards.CardValue(dealerCard)])
+	}
 	fmt.Println()
 	fmt.Println("=============================================================================")
-	cards.ForAllCardValues(func(firstCard cards.Card) {
-		cards.ForAllCardValues(func(secondCard cards.Card) {
-			if firstCard.Value != 1 && secondCard.Value != 1 {
-				fmt.Printf("%s,%s\t   ", cards.CardValueToString[firstCard.Value], cards.CardValueToString[secondCard.Value])
-				cards.ForAllCardValues(func(dealerCard cards.Card) {
-					if dealerCard.Value != 1 {
-						hand := player.Hand{Active: true, Cards: make([]cards.Card, 0)}
-						hand.Cards = append(hand.Cards, firstCard)
-						hand.Cards = append(hand.Cards, secondCard)
-						hand.Player = &game.State.Players[0]
-						char, err := rules.GetAutoPlayPlayerAction(&hand, cards.CardToValue(dealerCard, true))
-						if err != nil {
-							panic(err)
-						} else {
-							switch char {
-							case 'h':
-								fmt.Print(constants.Red)
-							case 's':
-								fmt.Print(constants.Green)
-							case 'd':
-								fmt.Print(constants.Yellow)
-							case 'p':
-								fmt.Print(constants.Cyan)
-							}
-							fmt.Printf("░%c░", unicode.ToUpper(char))
-							fmt.Print(constants.Reset)
-						}
-					}
-				})
-				fmt.Println()
-			}
-		})
-	})
+	for total := 5; total <= 20; total++ {
+		printRow(fmt.Sprintf("Hard %d", total), strategyTable.Hard[total])
+	}
+	for total := 13; total <= 20; total++ {
+		printRow(fmt.Sprintf("Soft A,%d", total-11), strategyTable.Soft[total])
+	}
+	for value := 1; value <= 10; value++ {
+		card := cards.CardValueToString[cards.CardValue(value)]
+		printRow(fmt.Sprintf("Pair %s,%s", card, card), strategyTable.Pairs[value])
+	}
+	if strategyTable.CompositionDependent {
+		fmt.Println("Two card hands are played by their cards, which can differ from their total.")
+	}
 }
 
 func PrintCards(cards []cards.Card) {
@@ -160,6 +164,12 @@ func PrintCards(cards []cards.Card) {
 	}
 }
 
+// PrintCountString shows the running count, the true count and how many aces
+// more or fewer than an even share are left in the shoe.
+func PrintCountString(t *game.Table) string {
+	return fmt.Sprintf("%s %+g (true %+.1f, aces %+.1f)", t.Counter.Name(), t.State.Count, game.TrueCount(t), game.AceSurplus(t))
+}
+
 func PrintCurrency(value int) string {
 	var result string
 	var isNegative bool
@@ -190,29 +200,53 @@ func PrintCard(card cards.Card) {
 	fmt.Print(cards.CardToString(card, true, false, false))
 }
 
-func PrintDoubleDownString(hand player.Hand) string {
-	if rules.CanDoubleDown(&hand) {
-		return fmt.Sprintf("%sd%souble down?        ", constants.UnderlineOn, constants.UnderlineOff)
+func PrintDoubleDownString(t *game.Table, hand player.Hand, tableRules rules.TableRules) string {
+	if rules.CanDoubleDown(t, &hand, tableRules) && rules.IsFreeDouble(t, hand) {
+		return "free " + actionPrompt(t, 'd')
+	}
+	if rules.CanDoubleDown(t, &hand, tableRules) {
+		return actionPrompt(t, 'd')
 	}
 	return ""
 }
 
-func PrintEvenMoneyString(hand player.Hand) string {
-	if rules.CanEvenMoney(&hand) {
+// actionPrompt offers action by the game's name for it, underlining the key
+// that plays it.
+func actionPrompt(t *game.Table, action rune) string {
+	named := rules.ActionFor(t.Mode, action)
+	i := strings.IndexRune(named.Name, named.Key)
+	if i < 0 {
+		return named.Name + "?        "
+	}
+	return fmt.Sprintf("%s%s%c%s%s?        ", named.Name[:i], constants.UnderlineOn, named.Key, constants.UnderlineOff, named.Name[i+1:])
+}
+
+func PrintEvenMoneyString(t *game.Table, hand player.Hand, tableRules rules.TableRules) string {
+	if rules.CanEvenMoney(t, &hand, tableRules) {
 		return fmt.Sprintf("%se%sven money?        ", constants.UnderlineOn, constants.UnderlineOff)
 	}
 	return ""
 }
 
-func PrintGameString(trifectaStax bool) string {
+func PrintGameString(t *game.Table, trifectaStax bool) string {
 	if trifectaStax {
 		// why does this fail during autoplay?
 		// if !*Autoplay {
-		switch game.GameMode {
+		switch t.Mode {
 		case game.JackAttack:
 			return "JackAttack"
 		case game.Spanish21:
 			return "Spanish21"
+		case game.FreeBet:
+			return "FreeBet"
+		case game.Switch:
+			return "BlackjackSwitch"
+		case game.DoubleExposure:
+			return "DoubleExposure"
+		case game.Pontoon:
+			return "Pontoon"
+		case game.SuperFun21:
+			return "SuperFun21"
 		case game.Trifecta:
 			return "Trifecta"
 		case game.Trifecta3:
@@ -229,23 +263,36 @@ func PrintGameString(trifectaStax bool) string {
 	return ""
 }
 
-func PrintHitString(hand player.Hand) string {
-	if rules.CanHit(&hand) {
-		return fmt.Sprintf("%sh%sit?        ", constants.UnderlineOn, constants.UnderlineOff)
+func PrintHitString(t *game.Table, hand player.Hand, tableRules rules.TableRules) string {
+	if rules.CanHit(t, &hand, tableRules) {
+		return actionPrompt(t, 'h')
 	}
 	return ""
 }
 
-func PrintInsuranceString(hand player.Hand) string {
-	if rules.CanInsurance(&hand) {
+func PrintInsuranceString(t *game.Table, hand player.Hand, tableRules rules.TableRules) string {
+	if rules.CanInsurance(t, &hand, tableRules) {
 		return fmt.Sprintf("%si%snsurance?        %sn%so thanks!\t", constants.UnderlineOn, constants.UnderlineOff, constants.UnderlineOn, constants.UnderlineOff)
 	}
 	return ""
 }
 
-func PrintSplitString(hand player.Hand) string {
-	if rules.CanSplit(hand) {
-		return fmt.Sprintf("s%sp%slit?        ", constants.UnderlineOn, constants.UnderlineOff)
+func PrintSplitString(t *game.Table, hand player.Hand, tableRules rules.TableRules) string {
+	if rules.CanSplit(t, hand, tableRules) && rules.IsFreeSplit(t, hand) {
+		return "free " + actionPrompt(t, 'p')
+	}
+	if rules.CanSplit(t, hand, tableRules) {
+		return actionPrompt(t, 'p')
+	}
+	return ""
+}
+
+func PrintSurrenderString(t *game.Table, hand player.Hand, tableRules rules.TableRules) string {
+	if rules.CanRescue(t, &hand) && t.Mode == game.Spanish21 {
+		return fmt.Sprintf("resc%su%se?        ", constants.UnderlineOn, constants.UnderlineOff)
+	}
+	if rules.CanSurrender(t, &hand, tableRules) {
+		return actionPrompt(t, 'u')
 	}
 	return ""
 }
@@ -274,24 +321,24 @@ func PrintShoe(shoe game.Shoe) {
 	}
 }
 
-func PrintStandString(hand player.Hand) string {
-	if rules.CanStand(hand) {
-		return fmt.Sprintf("%ss%stand?        ", constants.UnderlineOn, constants.UnderlineOff)
+func PrintStandString(t *game.Table, hand player.Hand, tableRules rules.TableRules) string {
+	if rules.CanStand(t, hand, tableRules) {
+		return actionPrompt(t, 's')
 	}
 	return ""
 }
 
-func PrintStats(trifectaStax bool) {
-	state := game.State
-	hands := state.Wins + state.Losses + state.Pushes
-	winPct := float32(game.State.Wins) / float32(utils.Max(hands-state.Pushes, 1)) * 100
-	fmt.Printf(constants.BoldOn+"Round #%d"+constants.BoldOff+"\n\n   Wins | Losses | Pushes\n"+constants.Reset+"     %d | %d | %d\n\n   Hands: %d   Win Pct: %.2f%%\n", state.Rounds, state.Wins, state.Losses, state.Pushes, hands, winPct)
+func PrintStats(t *game.Table, trifectaStax bool) {
+	state := t.State
+	hands := state.Wins + state.Losses + state.Pushes + state.Surrenders
+	winPct := float32(t.State.Wins) / float32(utils.Max(hands-state.Pushes, 1)) * 100
+	fmt.Printf(constants.BoldOn+"Round #%d"+constants.BoldOff+"\n\n   Wins | Losses | Pushes | Surrenders\n"+constants.Reset+"     %d | %d | %d | %d\n\n   Hands: %d   Win Pct: %.2f%%\n", state.Rounds, state.Wins, state.Losses, state.Pushes, state.Surrenders, hands, winPct)
 	if trifectaStax {
-		fmt.Printf("   %s Earnings:  %s\n", PrintGameString(trifectaStax), PrintCurrency((game.State.SidebetWinnings-state.SidebetLosings)*100))
+		fmt.Printf("   %s Earnings:  %s\n", PrintGameString(t, trifectaStax), PrintCurrency((t.State.SidebetWinnings-state.SidebetLosings)*100))
 	}
 
 	totalNet := 0
-	for i := 0; i < len(game.State.Players); i++ {
+	for i := 0; i < len(t.State.Players); i++ {
 		player := state.Players[i]
 		totalNet += player.Winnings + player.Stack
 	}
@@ -302,9 +349,9 @@ func PrintStats(trifectaStax bool) {
 		fmt.Printf(constants.Red+"   Total Losses:  %s\n"+constants.Reset, PrintCurrency(totalNet*100))
 	}
 
-	fmt.Printf(constants.UnderlineOn+"\n    Dealer    "+constants.UnderlineOff+"\n   Blackjacks: %d   Busts:  %d   Bust %%: %.2f%%  Blackjack %%: %.2f%%\n", state.DealerBlackjacks, state.DealerBusts, float32(game.State.DealerBusts)/float32(game.State.Rounds)*100, float32(game.State.DealerBlackjacks)/float32(game.State.Rounds)*100)
-	fmt.Printf(constants.UnderlineOn+"\n    Player    "+constants.UnderlineOff+"\n   Blackjacks: %d   Busts:  %d   Bust %%: %.2f%%  Blackjack %%: %.2f%%\n", state.PlayerBlackjacks, state.PlayerBusts, float32(game.State.PlayerBusts)/float32(hands)*100, float32(game.State.PlayerBlackjacks)/float32(hands)*100)
-	fmt.Printf("\nDecks: %d Cards: %d Index: %d Cut: %d Penetration: %.2f%%\n", len(state.Shoe.Decks), len(state.Shoe.Cards), state.Shoe.Index, state.Shoe.Cut, float32(state.Shoe.Index)/float32(len(state.Shoe.Cards))*100)
+	fmt.Printf(constants.UnderlineOn+"\n    Dealer    "+constants.UnderlineOff+"\n   Blackjacks: %d   Busts:  %d   Bust %%: %.2f%%  Blackjack %%: %.2f%%\n", state.DealerBlackjacks, state.DealerBusts, float32(t.State.DealerBusts)/float32(t.State.Rounds)*100, float32(t.State.DealerBlackjacks)/float32(t.State.Rounds)*100)
+	fmt.Printf(constants.UnderlineOn+"\n    Player    "+constants.UnderlineOff+"\n   Blackjacks: %d   Busts:  %d   Bust %%: %.2f%%  Blackjack %%: %.2f%%\n", state.PlayerBlackjacks, state.PlayerBusts, float32(t.State.PlayerBusts)/float32(hands)*100, float32(t.State.PlayerBlackjacks)/float32(hands)*100)
+	fmt.Printf("\nDecks: %d Cards: %d Index: %d Cut: %d Penetration: %.2f%% Seed: %d\n", len(state.Shoe.Decks), len(state.Shoe.Cards), state.Shoe.Index, state.Shoe.Cut, float32(state.Shoe.Index)/float32(len(state.Shoe.Cards))*100, state.Seed)
 
 	fmt.Printf("\n" + constants.UnderlineOn + "=== Bust Heuristics ===" + constants.UnderlineOff + "\n")
 	for _, value := range cards.CardValues {
@@ -325,9 +372,9 @@ func PrintStats(trifectaStax bool) {
 	}
 }
 
-func PrintWinnerLoserString(hand *player.Hand) string {
+func PrintWinnerLoserString(t *game.Table, hand *player.Hand, tableRules rules.TableRules) string {
 	var dealer bool
-	dealerHand := &game.State.Dealer.Hands[0]
+	dealerHand := &t.State.Dealer.Hands[0]
 
 	// what is causing this? @#!$#!#$@
 	if hand.Player == nil {
@@ -336,7 +383,11 @@ func PrintWinnerLoserString(hand *player.Hand) string {
 		dealer = rules.IsDealer(*hand.Player)
 	}
 
-	if rules.CanHit(dealerHand) || rules.IsBlackjack(*dealerHand) && !hand.Insured {
+	if hand.Surrendered {
+		return constants.Purple + "SURRENDERED!" + constants.Reset
+	}
+
+	if rules.CanHit(t, dealerHand, tableRules) || rules.IsBlackjack(*dealerHand) && !hand.Insured {
 		return ""
 	} else {
 		if hand.Busted {
@@ -369,25 +420,40 @@ func PrintWinnerLoserString(hand *player.Hand) string {
 	}
 }
 
-func PrintGame(cfg flags.Config, askingForInsurance bool, askingToDeal bool) {
+// PrintProgressives lines up the table's progressive meters, each in its own
+// color.
+func PrintProgressives(t *game.Table) string {
+	colors := []string{constants.Yellow, constants.Blue, constants.Purple, constants.Cyan}
+	meters := make([]string, 0)
+	for i, meter := range t.Progressives.Meters() {
+		meters = append(meters, colors[i%len(colors)]+PrintCurrency(meter.Amount)+constants.Reset)
+	}
+	return "     " + strings.Join(meters, "         ")
+}
+
+func PrintGame(t *game.Table, cfg flags.Config, askingForInsurance bool, askingToDeal bool, askingForSurrender bool, askingForSwitch bool) {
 	ClearScr()
 
 	if cfg.TrifectaStax { // && gameMode == TrifectaStax {
 		fmt.Println("=============================================================================")
 		fmt.Println("                              PROGRESSIVES")
 		fmt.Println("=============================================================================")
-		fmt.Printf(constants.Yellow+"     %s"+constants.Blue+"         %s"+constants.Purple+"         %s"+constants.Cyan+"         %s\n"+constants.Reset, PrintCurrency(sidebets.TrifectaProgressives[0]), PrintCurrency(sidebets.TrifectaProgressives[1]), PrintCurrency(sidebets.TrifectaProgressives[2]), PrintCurrency(sidebets.TrifectaProgressives[3]))
+		fmt.Println(PrintProgressives(t))
 	}
 	fmt.Println("=============================================================================")
-	fmt.Printf("Dealer:   House: %d\tCount: %d\n", game.State.House, game.State.Count)
-	PrintHand(game.State.Dealer.Hands[0], cfg)
+	fmt.Printf("Dealer:   House: %d\tCount: %s", t.State.House, PrintCountString(t))
+	if note := rules.DealerCardsNote(t, cfg.TableRules); note != "" {
+		fmt.Printf("\t(%s)", note)
+	}
+	fmt.Println()
+	PrintHand(t, t.State.Dealer.Hands[0], cfg)
 
 	actionsPrinted := false
 	autoplayHintPrinted := false
 
-	for i := 0; i < len(game.State.Players); i++ {
-		cardPlayer := game.State.Players[i]
-		isActivePlayer := !actionsPrinted && rules.CanPlay(cardPlayer, cfg.MinWager)
+	for i := 0; i < len(t.State.Players); i++ {
+		cardPlayer := t.State.Players[i]
+		isActivePlayer := !actionsPrinted && rules.CanPlay(t, cardPlayer, cfg.TableRules)
 
 		if isActivePlayer {
 			fmt.Print(constants.BoldOn + constants.White)
@@ -412,8 +478,11 @@ func PrintGame(cfg flags.Config, askingForInsurance bool, askingToDeal bool) {
 				fmt.Print(constants.BoldOn + constants.White)
 			}
 			fmt.Printf("Hand %d:   Wager: $%d   ", i+1, hand.Wager)
-			if hand.TrifectaWager > 0 {
-				fmt.Printf(constants.Purple+"Trifecta Wager: $%d"+constants.Reset, hand.TrifectaWager)
+			if hand.FreeWager > 0 {
+				fmt.Printf("(free $%d)   ", hand.FreeWager)
+			}
+			for _, name := range sidebets.Wagered(hand) {
+				fmt.Printf(constants.Purple+"%s Wager: $%d   "+constants.Reset, name, hand.Sidebets[name])
 			}
 			fmt.Println()
 
@@ -421,41 +490,48 @@ func PrintGame(cfg flags.Config, askingForInsurance bool, askingToDeal bool) {
 				fmt.Print(constants.BoldOn + constants.White)
 			}
 
-			PrintHand(hand, cfg)
+			PrintHand(t, hand, cfg)
 
 			if i == 0 {
-				fmt.Println(PrintSidebetsOutcome(hand))
+				fmt.Println(PrintSidebetsOutcome(t, hand))
 			}
 
 			if isActivePlayer {
 				fmt.Print(constants.BoldOn + constants.White)
 			}
 
-			if askingForInsurance && !hand.Insured && !actionsPrinted {
-				if rules.CanEvenMoney(&hand) {
-					fmt.Printf("   %s%s   \n", PrintEvenMoneyString(hand), PrintStandString(hand))
-				} else if rules.CanInsurance(&hand) {
-					fmt.Printf("   %s   \n", PrintInsuranceString(hand))
+			if askingForSwitch && !actionsPrinted && rules.CanSwitch(t, &cardPlayer) {
+				fmt.Printf("   swi%st%sch?        %sn%so thanks!\t   \n", constants.UnderlineOn, constants.UnderlineOff, constants.UnderlineOn, constants.UnderlineOff)
+				actionsPrinted = true
+			} else if askingForSurrender && !actionsPrinted && rules.CanSurrender(t, &hand, cfg.TableRules) {
+				fmt.Printf("   %s%sn%so thanks!\t   \n", PrintSurrenderString(t, hand, cfg.TableRules), constants.UnderlineOn, constants.UnderlineOff)
+				actionsPrinted = true
+			} else if askingForInsurance && !hand.Insured && !actionsPrinted {
+				if rules.CanEvenMoney(t, &hand, cfg.TableRules) {
+					fmt.Printf("   %s%s   \n", PrintEvenMoneyString(t, hand, cfg.TableRules), PrintStandString(t, hand, cfg.TableRules))
+				} else if rules.CanInsurance(t, &hand, cfg.TableRules) {
+					fmt.Printf("   %s   \n", PrintInsuranceString(t, hand, cfg.TableRules))
 				}
 				actionsPrinted = true
-			} else if !askingForInsurance && !hand.Busted && hand.Active && rules.CanPlay(*hand.Player, cfg.MinWager) && !actionsPrinted {
-				if rules.CanEvenMoney(&hand) {
-					fmt.Printf("   %s%s   \n", PrintEvenMoneyString(hand), PrintStandString(hand))
-				} else if rules.CanInsurance(&hand) {
-					fmt.Printf("   %s   \n", PrintInsuranceString(hand))
+			} else if !askingForInsurance && !hand.Busted && hand.Active && rules.CanPlay(t, *hand.Player, cfg.TableRules) && !actionsPrinted {
+				if rules.CanEvenMoney(t, &hand, cfg.TableRules) {
+					fmt.Printf("   %s%s   \n", PrintEvenMoneyString(t, hand, cfg.TableRules), PrintStandString(t, hand, cfg.TableRules))
+				} else if rules.CanInsurance(t, &hand, cfg.TableRules) {
+					fmt.Printf("   %s   \n", PrintInsuranceString(t, hand, cfg.TableRules))
 				} else {
-					fmt.Printf("   %s%s%s%s   \n", PrintSplitString(hand), PrintHitString(hand), PrintStandString(hand), PrintDoubleDownString(hand))
+					fmt.Printf("   %s%s%s%s%s   \n", PrintSplitString(t, hand, cfg.TableRules), PrintHitString(t, hand, cfg.TableRules), PrintStandString(t, hand, cfg.TableRules), PrintDoubleDownString(t, hand, cfg.TableRules), PrintSurrenderString(t, hand, cfg.TableRules))
 				}
 				actionsPrinted = true
 			}
 
 			if !autoplayHintPrinted {
-				if rules.CanPlay(cardPlayer, cfg.MinWager) {
-					chr, err := rules.GetAutoPlayPlayerAction(&hand, cards.CardToValue(game.State.Dealer.Hands[0].Cards[0], true))
+				if rules.CanPlay(t, cardPlayer, cfg.TableRules) {
+					deviationSet, _ := deviations.Load(cfg.Deviations)
+					chr, err := deviations.GetAutoPlayPlayerAction(t, &hand, cards.CardToValue(t.State.Dealer.Hands[0].Cards[0], true), cfg.TableRules, strategy.ForRound(t, cfg), deviationSet)
 					if err != nil {
 						panic(err)
 					}
-					fmt.Printf(constants.Cyan+"\nHint: Autoplay says you should %s!\n"+constants.Reset, PrintAutoplayString(chr))
+					fmt.Printf(constants.Cyan+"\nHint: Autoplay says you should %s!\n"+constants.Reset, PrintAutoplayString(t, chr))
 					switch chr {
 					case 'h':
 						fmt.Println(constants.Yellow + "Your hand is somewhat weak. You should hit to try and improve your position." + constants.Reset)
@@ -475,6 +551,8 @@ func PrintGame(cfg flags.Config, askingForInsurance bool, askingToDeal bool) {
 						fmt.Println(constants.Cyan + "Odds are in your favor. You should double down." + constants.Reset)
 					case 'p':
 						fmt.Println(constants.Cyan + "You have a pair in a favorable position. You should split." + constants.Reset)
+					case 'u':
+						fmt.Println(constants.Purple + "The odds are against this hand. You should surrender and keep half your wager." + constants.Reset)
 					}
 
 					autoplayHintPrinted = true
@@ -494,7 +572,7 @@ func PrintGame(cfg flags.Config, askingForInsurance bool, askingToDeal bool) {
 	}
 }
 
-func PrintHand(hand player.Hand, cfg flags.Config) {
+func PrintHand(t *game.Table, hand player.Hand, cfg flags.Config) {
 	if cfg.DrawCards {
 		DrawHand(hand, cfg.ColorTerminal)
 	} else {
@@ -505,13 +583,13 @@ func PrintHand(hand player.Hand, cfg flags.Config) {
 
 	softValue := player.HandValue(&hand, true)
 	hardValue := player.HandValue(&hand, false)
-	if softValue != hardValue && rules.CanHit(&hand) && !rules.IsBlackjack(hand) {
-		fmt.Printf("Total: %d/%d   %s   ", softValue, hardValue, PrintWinnerLoserString(&hand))
+	if softValue != hardValue && rules.CanHit(t, &hand, cfg.TableRules) && !rules.IsBlackjack(hand) {
+		fmt.Printf("Total: %d/%d   %s   ", softValue, hardValue, PrintWinnerLoserString(t, &hand, cfg.TableRules))
 	} else {
-		fmt.Printf("Total: %d   %s   ", hardValue, PrintWinnerLoserString(&hand))
+		fmt.Printf("Total: %d   %s   ", hardValue, PrintWinnerLoserString(t, &hand, cfg.TableRules))
 	}
 
-	if rules.IsBlackjack(hand) && !rules.CanEvenMoney(&hand) && !hand.EvenMoney {
+	if rules.IsBlackjack(hand) && !rules.CanEvenMoney(t, &hand, cfg.TableRules) && !hand.EvenMoney {
 		fmt.Printf("Blackjack!   ")
 	} else if hardValue > 21 {
 		hand.Busted = true
@@ -521,93 +599,27 @@ func PrintHand(hand player.Hand, cfg flags.Config) {
 	fmt.Println()
 }
 
-func PrintShoeDetails() {
-	fmt.Printf("Decks: %d   Cards: %d   Index: %d   Cut: %d\n", len(game.State.Shoe.Decks), len(game.State.Shoe.Cards), game.State.Shoe.Index, game.State.Shoe.Cut)
-	PrintShoe(game.State.Shoe)
+func PrintShoeDetails(t *game.Table) {
+	fmt.Printf("Decks: %d   Cards: %d   Index: %d   Cut: %d\n", len(t.State.Shoe.Decks), len(t.State.Shoe.Cards), t.State.Shoe.Index, t.State.Shoe.Cut)
+	PrintShoe(t.State.Shoe)
 }
 
-func PrintSidebetsOutcome(hand player.Hand) string {
-	switch game.GameMode {
-	case game.Spanish21:
-		firstCard := hand.Cards[0]
-		secondCard := hand.Cards[1]
-
-		outcome := constants.Purple
-		dealerUpCard := sidebets.DealerUpCard()
-		dealerDownCard := sidebets.DealerDownCard()
-
-		if firstCard.Value == dealerUpCard.Value {
-			if sidebets.CardsMatchSuite(firstCard, dealerUpCard) {
-				outcome += fmt.Sprintf("First Card Matches Up Card, Matches Suite! %d to 1 WINNER!", sidebets.Spanish21MatchSuitMultiplier)
-			} else {
-				outcome += fmt.Sprintf("First Card Matches Up Card! %d to 1 WINNER!", sidebets.Spanish21MatchUnsuitedMultiplier)
-			}
-		}
-
-		if secondCard.Value == dealerUpCard.Value {
-			if sidebets.CardsMatchSuite(secondCard, dealerUpCard) {
-				outcome += fmt.Sprintf("Second Card Matches Up Card, Matches Suite! %d to 1 WINNER!", sidebets.Spanish21MatchSuitMultiplier)
-			} else {
-				outcome += fmt.Sprintf("Second Card Matches Up Card! %d to 1 WINNER!", sidebets.Spanish21MatchUnsuitedMultiplier)
-			}
-		}
-
-		if !dealerDownCard.Masked {
-			if firstCard.Value == dealerDownCard.Value {
-				if sidebets.CardsMatchSuite(firstCard, dealerDownCard) {
-					outcome += fmt.Sprintf("First Card Matches Down Card, Matches Suite! %d to 1 WINNER!", sidebets.Spanish21MatchSuitMultiplier)
-				} else {
-					outcome += fmt.Sprintf("First Card Matches Down Card! %d to 1 WINNER!", sidebets.Spanish21MatchUnsuitedMultiplier)
-				}
-			}
-
-			if secondCard.Value == dealerDownCard.Value {
-				if sidebets.CardsMatchSuite(secondCard, dealerDownCard) {
-					outcome += fmt.Sprintf("Second Card Matches Down Card, Matches Suite! %d to 1 WINNER!", sidebets.Spanish21MatchSuitMultiplier)
-				} else {
-					outcome += fmt.Sprintf("Second Card Matches Down Card! %d to 1 WINNER!", sidebets.Spanish21MatchUnsuitedMultiplier)
-				}
-			}
-		}
-
-		winnings := sidebets.GetSpanish21Winnings(hand)
-		if winnings > 0 && !dealerDownCard.Masked {
-			outcome += fmt.Sprintf("\t$%d", winnings)
-		}
-
-		outcome += constants.Reset
-
-		return outcome
-	case game.TrifectaStaxx:
-		if hand.TrifectaWager > 0 && hand.TrifectaWinnings > 0 {
-			outcome := constants.Purple
-
-			if sidebets.IsTrifectaTripAces(hand, true) || sidebets.IsTrifectaTripAces(hand, false) || sidebets.IsTrifectaTriplet(hand, cards.King, false) || sidebets.IsTrifectaTriplet(hand, cards.Queen, false) {
-				outcome += "Trifecta PROGRESSIVE!"
-			} else if sidebets.IsTrifectaStraightFlush(hand) {
-				outcome += "Trifecta STRAIGHT FLUSH!"
-			} else if sidebets.IsTrifectaTrips(hand, false) {
-				outcome += "Trifecta TRIPS!"
-			} else if sidebets.IsTrifectaFlush(hand) {
-				outcome += "Trifecta FLUSH!"
-			} else if sidebets.IsTrifectaStraight(hand) {
-				outcome += "Trifecta STRAIGHT!"
-			}
-
-			if hand.TrifectaWinnings > 0 {
-				outcome += outcome + "\t" + PrintCurrency(hand.TrifectaWinnings)
-			}
-
-			outcome += constants.Reset
+// PrintSidebetsOutcome lists what hand's sidebets won, once the dealer's
+// hole card is face up.
+func PrintSidebetsOutcome(t *game.Table, hand player.Hand) string {
+	if len(t.State.Dealer.Hands) == 0 || len(t.State.Dealer.Hands[0].Cards) < 2 || sidebets.DealerDownCard(t).Masked {
+		return ""
+	}
 
-			return outcome
+	outcome := ""
+	for _, name := range sidebets.Wagered(hand) {
+		winnings := hand.SidebetWinnings[name]
+		bet, ok := sidebets.ByName(name)
+		if !ok || winnings == 0 {
+			continue
 		}
-
-		return ""
-	case game.Blackjack:
-	default:
-		return ""
+		outcome += fmt.Sprintf("%s%s: %s WINNER!\t$%d%s   ", constants.Purple, name, sidebets.Outcome(t, bet, hand), winnings, constants.Reset)
 	}
 
-	return ""
+	return outcome
 }
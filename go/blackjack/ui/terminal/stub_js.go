@@ -2,10 +2,14 @@
 
 package terminal
 
-import "blackjack/cards"
+import (
+	"blackjack/cards"
+	"blackjack/game"
+	"blackjack/rules"
+)
 
-func PrintCurrency(value int) string { return "" }
-func PrintStats(bool)                {}
-func PrintAutoPlayTable()            {}
-func PrintShoeDetails()              {}
-func PrintCards([]cards.Card)        {}
+func PrintCurrency(value int) string          { return "" }
+func PrintStats(*game.Table, bool)            {}
+func PrintAutoPlayTable(*rules.StrategyTable) {}
+func PrintShoeDetails(*game.Table)            {}
+func PrintCards([]cards.Card)                 {}
@@ -5,21 +5,23 @@ package terminal
 
 import (
 	"blackjack/flags"
+	"blackjack/game"
 	"blackjack/ui"
 	"github.com/mattn/go-tty"
 )
 
 type TerminalUI struct {
-	t   *tty.TTY
-	cfg flags.Config
+	t     *tty.TTY
+	cfg   flags.Config
+	table *game.Table
 }
 
-func New(cfg flags.Config) (*TerminalUI, error) {
+func New(cfg flags.Config, table *game.Table) (*TerminalUI, error) {
 	t, err := tty.Open()
 	if err != nil {
 		return nil, err
 	}
-	return &TerminalUI{t: t, cfg: cfg}, nil
+	return &TerminalUI{t: t, cfg: cfg, table: table}, nil
 }
 
 func (c *TerminalUI) ReadAction() (rune, error) {
@@ -27,7 +29,7 @@ func (c *TerminalUI) ReadAction() (rune, error) {
 }
 
 func (c *TerminalUI) Render(state ui.GameState) {
-	PrintGame(c.cfg, state.AskingForInsurance, state.AskingToDeal)
+	PrintGame(c.table, c.cfg, state.AskingForInsurance, state.AskingToDeal, state.AskingForSurrender, state.AskingForSwitch)
 }
 
 func (c *TerminalUI) Close() error {
@@ -3,6 +3,8 @@ package ui
 type GameState struct {
 	AskingForInsurance bool
 	AskingToDeal       bool
+	AskingForSurrender bool
+	AskingForSwitch    bool
 }
 
 type IO interface {
@@ -4,14 +4,17 @@ package web
 
 import (
 	"blackjack/cards"
+	"blackjack/deviations"
 	"blackjack/flags"
 	"blackjack/game"
 	"blackjack/player"
 	"blackjack/rules"
 	"blackjack/sidebets"
+	"blackjack/strategy"
 	"blackjack/ui"
 	"errors"
 	"fmt"
+	"strings"
 	"syscall/js"
 )
 
@@ -19,6 +22,7 @@ type WebUI struct {
 	actionCh chan rune
 	handlers []handler
 	cfg      flags.Config
+	table    *game.Table
 }
 
 type handler struct {
@@ -26,11 +30,12 @@ type handler struct {
 	fn js.Func
 }
 
-func New(cfg flags.Config) *WebUI {
+func New(cfg flags.Config, table *game.Table) *WebUI {
 	w := &WebUI{
 		actionCh: make(chan rune),
 		handlers: make([]handler, 0),
 		cfg:      cfg,
+		table:    table,
 	}
 	// bind action buttons to dispatch runes
 	w.bind("hit", 'h')
@@ -40,6 +45,8 @@ func New(cfg flags.Config) *WebUI {
 	w.bind("deal", 'd')
 	w.bind("insure", 'i')
 	w.bind("decline", 'n')
+	w.bind("surrender", 'u')
+	w.bind("switch", 't')
 	return w
 }
 
@@ -77,7 +84,20 @@ func (w *WebUI) Render(state ui.GameState) {
 		el.Get("style").Set("display", insureDisplay)
 	}
 	if el := doc.Call("getElementById", "decline"); el.Truthy() {
-		el.Get("style").Set("display", insureDisplay)
+		if state.AskingForSurrender || state.AskingForSwitch {
+			el.Get("style").Set("display", "inline")
+		} else {
+			el.Get("style").Set("display", insureDisplay)
+		}
+	}
+
+	// toggle switch button
+	switchDisplay := "none"
+	if state.AskingForSwitch {
+		switchDisplay = "inline"
+	}
+	if el := doc.Call("getElementById", "switch"); el.Truthy() {
+		el.Get("style").Set("display", switchDisplay)
 	}
 
 	// toggle deal button
@@ -89,28 +109,40 @@ func (w *WebUI) Render(state ui.GameState) {
 		el.Get("style").Set("display", dealDisplay)
 	}
 
+	// name the plays as the game does, e.g. twist, stick and buy in Pontoon
+	for id, action := range map[string]rune{"hit": 'h', "stand": 's', "double": 'd', "split": 'p', "surrender": 'u'} {
+		if el := doc.Call("getElementById", id); el.Truthy() {
+			name := rules.ActionFor(w.table.Mode, action).Name
+			el.Set("innerText", strings.ToUpper(name[:1])+name[1:])
+		}
+	}
+
 	// toggle player action buttons
 	hitDisplay := "none"
 	standDisplay := "none"
 	doubleDisplay := "none"
 	splitDisplay := "none"
-	if len(game.State.Players) > 0 && len(game.State.Players[0].Hands) > 0 {
-		p := game.State.Players[0]
-		hand := p.Hands[0]
-		if rules.CanPlay(p, w.cfg.MinWager) && !state.AskingForInsurance && !state.AskingToDeal {
-			if rules.CanHit(&hand) {
+	surrenderDisplay := "none"
+	if len(w.table.State.Players) > 0 && len(w.table.State.Players[0].Hands) > 0 {
+		p := w.table.State.Players[0]
+		hand := playingHand(&p)
+		if rules.CanPlay(w.table, p, w.cfg.TableRules) && !state.AskingForInsurance && !state.AskingForSwitch && !state.AskingToDeal {
+			if rules.CanHit(w.table, &hand, w.cfg.TableRules) {
 				hitDisplay = "inline"
 			}
-			if rules.CanStand(hand) {
+			if rules.CanStand(w.table, hand, w.cfg.TableRules) {
 				standDisplay = "inline"
 			}
-			if rules.CanDoubleDown(&hand) {
+			if rules.CanDoubleDown(w.table, &hand, w.cfg.TableRules) {
 				doubleDisplay = "inline"
 			}
-			if rules.CanSplit(hand) {
+			if rules.CanSplit(w.table, hand, w.cfg.TableRules) {
 				splitDisplay = "inline"
 			}
 		}
+		if (state.AskingForSurrender || !state.AskingForInsurance && !state.AskingForSwitch && !state.AskingToDeal) && rules.CanSurrender(w.table, &hand, w.cfg.TableRules) {
+			surrenderDisplay = "inline"
+		}
 	}
 	if el := doc.Call("getElementById", "hit"); el.Truthy() {
 		el.Get("style").Set("display", hitDisplay)
@@ -124,64 +156,74 @@ func (w *WebUI) Render(state ui.GameState) {
 	if el := doc.Call("getElementById", "split"); el.Truthy() {
 		el.Get("style").Set("display", splitDisplay)
 	}
+	if el := doc.Call("getElementById", "surrender"); el.Truthy() {
+		el.Get("style").Set("display", surrenderDisplay)
+	}
 
 	// update dealer cards
 	if el := doc.Call("getElementById", "dealer-cards"); el.Truthy() {
 		html := ""
-		if len(game.State.Dealer.Hands) > 0 {
-			for _, c := range game.State.Dealer.Hands[0].Cards {
-				src := cardToImage(c)
-				html += "<img class=\"card\" src=\"" + src + "\" style=\"display:none\" onload=\"this.style.display='block'\" onerror=\"this.style.display='none'\"/>"
-				html += "<img class=\"card\" src=\"/portfolio" + src + "\" style=\"display:none\" onload=\"this.style.display='block'\" onerror=\"this.style.display='none'\"/>"
-			}
+		if len(w.table.State.Dealer.Hands) > 0 {
+			html = cardsHTML(w.table.State.Dealer.Hands[0].Cards)
 		}
 		el.Set("innerHTML", html)
 	}
 	if el := doc.Call("getElementById", "dealer-total"); el.Truthy() {
 		total := 0
-		if len(game.State.Dealer.Hands) > 0 {
-			hand := game.State.Dealer.Hands[0]
+		if len(w.table.State.Dealer.Hands) > 0 {
+			hand := w.table.State.Dealer.Hands[0]
 			total = player.HandValue(&hand, false)
 		}
-		el.Set("innerText", fmt.Sprintf("Total: %d", total))
-	}
-
-	// update player cards (first player, first hand)
-	if el := doc.Call("getElementById", "player-cards"); el.Truthy() {
-		html := ""
-		if len(game.State.Players) > 0 && len(game.State.Players[0].Hands) > 0 {
-			for _, c := range game.State.Players[0].Hands[0].Cards {
-				src := cardToImage(c)
-				html += "<img class=\"card\" src=\"" + src + "\" style=\"display:none\" onload=\"this.style.display='block'\" onerror=\"this.style.display='none'\"/>"
-				html += "<img class=\"card\" src=\"/portfolio" + src + "\" style=\"display:none\" onload=\"this.style.display='block'\" onerror=\"this.style.display='none'\"/>"
-			}
+		text := fmt.Sprintf("Total: %d", total)
+		if note := rules.DealerCardsNote(w.table, w.cfg.TableRules); note != "" {
+			text += " (" + note + ")"
 		}
-		el.Set("innerHTML", html)
+		el.Set("innerText", text)
 	}
-	if len(game.State.Players) > 0 && len(game.State.Players[0].Hands) > 0 {
-		p := game.State.Players[0]
-		hand := p.Hands[0]
-		if el := doc.Call("getElementById", "player-stack"); el.Truthy() {
+
+	// update the first player's hands, two of them in Blackjack Switch or after a split
+	hands := []player.Hand{}
+	if len(w.table.State.Players) > 0 {
+		p := w.table.State.Players[0]
+		hands = p.Hands
+		if el := doc.Call("getElementById", "player-stack"); el.Truthy() && len(hands) > 0 {
 			el.Set("innerText", fmt.Sprintf("$%d", p.Stack))
 		}
-		if el := doc.Call("getElementById", "player-winnings"); el.Truthy() {
+		if el := doc.Call("getElementById", "player-winnings"); el.Truthy() && len(hands) > 0 {
 			el.Set("innerText", fmt.Sprintf(" +%s", PrintCurrency(p.Winnings*100)))
 		}
-		if el := doc.Call("getElementById", "hand-wager"); el.Truthy() {
-			el.Set("innerText", fmt.Sprintf("$%d", hand.Wager))
+	}
+	if el := doc.Call("getElementById", "hand2"); el.Truthy() {
+		hand2Display := "none"
+		if len(hands) > 1 {
+			hand2Display = "block"
 		}
-		if el := doc.Call("getElementById", "hand-trifecta"); el.Truthy() {
-			if hand.TrifectaWager > 0 {
-				el.Set("innerText", fmt.Sprintf("Trifecta Wager: $%d", hand.TrifectaWager))
-			} else {
-				el.Set("innerText", "")
+		el.Get("style").Set("display", hand2Display)
+	}
+	for i, ids := range handElements {
+		if el := doc.Call("getElementById", ids.cards); el.Truthy() {
+			html := ""
+			if i < len(hands) {
+				html = cardsHTML(hands[i].Cards)
 			}
+			el.Set("innerHTML", html)
+		}
+		if i >= len(hands) {
+			continue
+		}
+
+		hand := hands[i]
+		if el := doc.Call("getElementById", ids.wager); el.Truthy() {
+			el.Set("innerText", fmt.Sprintf("$%d", hand.Wager))
 		}
-		if el := doc.Call("getElementById", "player-total"); el.Truthy() {
+		if el := doc.Call("getElementById", ids.sidebets); el.Truthy() {
+			el.Set("innerText", sidebetWagers(w.table, hand))
+		}
+		if el := doc.Call("getElementById", ids.total); el.Truthy() {
 			soft := player.HandValue(&hand, true)
 			hard := player.HandValue(&hand, false)
 			totalStr := fmt.Sprintf("Total: %d", hard)
-			if soft != hard && rules.CanHit(&hand) {
+			if soft != hard && rules.CanHit(w.table, &hand, w.cfg.TableRules) {
 				totalStr = fmt.Sprintf("Total: %d/%d", soft, hard)
 			}
 			el.Set("innerText", totalStr)
@@ -189,25 +231,29 @@ func (w *WebUI) Render(state ui.GameState) {
 	}
 
 	// progressives and game stats
-	for i := 0; i < 4 && i < len(sidebets.TrifectaProgressives); i++ {
+	for i, meter := range w.table.Progressives.Meters() {
 		id := fmt.Sprintf("prog%d", i)
 		if el := doc.Call("getElementById", id); el.Truthy() {
-			el.Set("innerText", PrintCurrency(sidebets.TrifectaProgressives[i]))
+			el.Set("innerText", PrintCurrency(meter.Amount))
 		}
 	}
 	if el := doc.Call("getElementById", "house"); el.Truthy() {
-		el.Set("innerText", fmt.Sprintf("%d", game.State.House))
+		el.Set("innerText", fmt.Sprintf("%d", w.table.State.House))
 	}
 	if el := doc.Call("getElementById", "count"); el.Truthy() {
-		el.Set("innerText", fmt.Sprintf("%d", game.State.Count))
+		el.Set("innerText", fmt.Sprintf("%s %+g (true %+.1f, aces %+.1f)", w.table.Counter.Name(), w.table.State.Count, game.TrueCount(w.table), game.AceSurplus(w.table)))
 	}
 
 	// update status text
 	if el := doc.Call("getElementById", "status"); el.Truthy() {
 		status := ""
 		switch {
+		case state.AskingForSurrender:
+			status = "Surrender?"
 		case state.AskingForInsurance:
 			status = "Insurance?"
+		case state.AskingForSwitch:
+			status = "Switch?"
 		case state.AskingToDeal:
 			status = "Deal again?"
 		}
@@ -217,11 +263,12 @@ func (w *WebUI) Render(state ui.GameState) {
 	// hint text
 	if el := doc.Call("getElementById", "hint"); el.Truthy() {
 		hint := ""
-		if len(game.State.Players) > 0 && len(game.State.Players[0].Hands) > 0 {
-			p := game.State.Players[0]
-			hand := p.Hands[0]
-			if rules.CanPlay(p, w.cfg.MinWager) {
-				chr, err := rules.GetAutoPlayPlayerAction(&hand, cards.CardToValue(game.State.Dealer.Hands[0].Cards[0], true))
+		if len(w.table.State.Players) > 0 && len(w.table.State.Players[0].Hands) > 0 {
+			p := w.table.State.Players[0]
+			hand := playingHand(&p)
+			if rules.CanPlay(w.table, p, w.cfg.TableRules) && !state.AskingForSwitch {
+				deviationSet, _ := deviations.Load(w.cfg.Deviations)
+				chr, err := deviations.GetAutoPlayPlayerAction(w.table, &hand, cards.CardToValue(w.table.State.Dealer.Hands[0].Cards[0], true), w.cfg.TableRules, strategy.ForRound(w.table, w.cfg), deviationSet)
 				if err == nil {
 					advice := ""
 					switch chr {
@@ -243,6 +290,8 @@ func (w *WebUI) Render(state ui.GameState) {
 						advice = "Odds are in your favor. You should double down."
 					case 'p':
 						advice = "You have a pair in a favorable position. You should split."
+					case 'u':
+						advice = "The odds are against this hand. You should surrender and keep half your wager."
 					}
 					hint = fmt.Sprintf("Hint: Autoplay says you should %s!\n%s", printAutoplayString(chr), advice)
 				}
@@ -252,6 +301,31 @@ func (w *WebUI) Render(state ui.GameState) {
 	}
 }
 
+// handElements are the ids the first player's hands are shown in, in order.
+var handElements = []struct{ wager, sidebets, cards, total string }{
+	{wager: "hand-wager", sidebets: "hand-trifecta", cards: "player-cards", total: "player-total"},
+	{wager: "hand2-wager", sidebets: "hand2-trifecta", cards: "hand2-cards", total: "hand2-total"},
+}
+
+// playingHand is the hand p is playing, or its first once none is.
+func playingHand(p *player.Player) player.Hand {
+	if hand := player.ActiveHand(p); hand != nil {
+		return *hand
+	}
+	return p.Hands[0]
+}
+
+// cardsHTML shows cards as images, from the site root or under /portfolio.
+func cardsHTML(handCards []cards.Card) string {
+	html := ""
+	for _, c := range handCards {
+		src := cardToImage(c)
+		html += "<img class=\"card\" src=\"" + src + "\" style=\"display:none\" onload=\"this.style.display='block'\" onerror=\"this.style.display='none'\"/>"
+		html += "<img class=\"card\" src=\"/portfolio" + src + "\" style=\"display:none\" onload=\"this.style.display='block'\" onerror=\"this.style.display='none'\"/>"
+	}
+	return html
+}
+
 func (w *WebUI) Close() error {
 	for _, h := range w.handlers {
 		h.el.Call("removeEventListener", "click", h.fn)
@@ -280,6 +354,23 @@ func cardToImage(c cards.Card) string {
 	return fmt.Sprintf("/assets/boardgame/PNG/Cards/card%s%s.png", suite, val)
 }
 
+// sidebetWagers lists hand's sidebet wagers, in order, with what any of them
+// won and the hand that won it.
+func sidebetWagers(t *game.Table, hand player.Hand) string {
+	wagers := make([]string, 0, len(hand.Sidebets))
+	for _, name := range sidebets.Wagered(hand) {
+		wager := fmt.Sprintf("%s Wager: $%d", name, hand.Sidebets[name])
+		if winnings := hand.SidebetWinnings[name]; winnings > 0 {
+			if bet, ok := sidebets.ByName(name); ok {
+				wager += fmt.Sprintf(" %s", sidebets.Outcome(t, bet, hand))
+			}
+			wager += fmt.Sprintf(" won $%d", winnings)
+		}
+		wagers = append(wagers, wager)
+	}
+	return strings.Join(wagers, "   ")
+}
+
 func PrintCurrency(value int) string {
 	result := ""
 	isNegative := false
@@ -309,6 +400,8 @@ func printAutoplayString(chr rune) string {
 		return "DOUBLE DOWN"
 	case 'p':
 		return "SPLIT"
+	case 'u':
+		return "SURRENDER"
 	default:
 		return ""
 	}
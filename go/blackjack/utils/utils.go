@@ -18,10 +18,10 @@ func Min(x, y int) int {
 	return y
 }
 
-func RollDice() int {
-	return RollInt(Die)
+func RollDice(rng *rand.Rand) int {
+	return RollInt(rng, Die)
 }
 
-func RollInt(ints []int) int {
-	return ints[rand.Intn(len(ints))]
+func RollInt(rng *rand.Rand, ints []int) int {
+	return ints[rng.Intn(len(ints))]
 }
@@ -4,33 +4,19 @@ package main
 
 import (
 	"log"
-	"math/rand"
-	"sync"
 	"syscall/js"
-	"time"
 
-	"blackjack/cards"
 	"blackjack/dealer"
 	"blackjack/flags"
 	"blackjack/game"
-	"blackjack/player"
-	"blackjack/rules"
-	"blackjack/sidebets"
 	"blackjack/ui"
 	"blackjack/ui/web"
-	"blackjack/utils"
 )
 
-var onlyOnce sync.Once
 var console ui.IO
+var table *game.Table
 var cfg flags.Config
 
-func init() {
-	onlyOnce.Do(func() {
-		rand.New(rand.NewSource(time.Now().UnixNano()))
-	})
-}
-
 // Start initializes the game using a JavaScript configuration object and the
 // Web UI. It is exported to the surrounding JS environment.
 func Start(this js.Value, args []js.Value) any {
@@ -46,101 +32,60 @@ func Start(this js.Value, args []js.Value) any {
 		if jsCfg.MinWager != 0 {
 			cfg.MinWager = jsCfg.MinWager
 		}
+		if jsCfg.MaxWager != 0 {
+			cfg.MaxWager = jsCfg.MaxWager
+		}
 		if jsCfg.HouseStart != 0 {
 			cfg.HouseStart = jsCfg.HouseStart
 		}
 		if jsCfg.PlayerStartStack != 0 {
 			cfg.PlayerStartStack = jsCfg.PlayerStartStack
 		}
+		cfg.Penetration = jsCfg.Penetration
+		cfg.Shuffler = jsCfg.Shuffler
+		cfg.ShuffleRecipe = jsCfg.ShuffleRecipe
+		cfg.StackedShoe = jsCfg.StackedShoe
+		cfg.Game = jsCfg.Game
 		cfg.UseGlyphs = jsCfg.UseGlyphs
 		cfg.DrawCards = jsCfg.DrawCards
 		cfg.TrifectaStax = jsCfg.TrifectaStax
+		cfg.Sidebets = jsCfg.Sidebets
 		cfg.Autoplay = jsCfg.Autoplay
 		cfg.Clean = jsCfg.Clean
 		cfg.ColorTerminal = jsCfg.ColorTerminal
-	}
-
-	console = web.New(cfg)
-
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*15000000))
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*5000000))
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*2500000))
-	sidebets.TrifectaProgressives = append(sidebets.TrifectaProgressives, int(rand.Float64()*100000))
-
-	if err := game.LoadBlackjackStateYaml(cfg.Clean); err != nil {
-		game.State = game.BlackjackState{
-			House:            cfg.HouseStart,
-			Wins:             0,
-			Losses:           0,
-			Pushes:           0,
-			DealerBlackjacks: 0,
-			DealerBusts:      0,
-			PlayerBlackjacks: 0,
-			PlayerBusts:      0,
-			BustCards:        []cards.Card{},
-			BustCounts:       make(map[cards.CardValue]int),
-			SidebetWinnings:  0,
-			SidebetLosings:   0,
-			Players:          make([]player.Player, 0),
-			Dealer:           player.Player{Dealer: true},
-			Rounds:           0,
+		if jsCfg.Seed != 0 {
+			cfg.Seed = jsCfg.Seed
 		}
-
-		for _, value := range cards.CardValues {
-			game.State.BustCounts[value] = 0
+		if jsCfg.Rounds != 0 {
+			cfg.Rounds = jsCfg.Rounds
 		}
-
-		game.CreateShoe(cfg.NumOfDecks)
-		dealer.BurnCard()
-		game.CutShoe()
-	}
-
-	if game.State.Players == nil || len(game.State.Players) == 0 {
-		for i := 0; i < cfg.NumOfPlayers; i++ {
-			game.State.Players = append(game.State.Players, player.CreatePlayer(cfg.PlayerStartStack, cfg.MinWager))
+		cfg.CompositionDependent = jsCfg.CompositionDependent
+		cfg.CountSystem = jsCfg.CountSystem
+		cfg.Deviations = jsCfg.Deviations
+		cfg.Betting = jsCfg.Betting
+		if jsCfg.BetSpread != 0 {
+			cfg.BetSpread = jsCfg.BetSpread
 		}
+		if jsCfg.KellyFraction != 0 {
+			cfg.KellyFraction = jsCfg.KellyFraction
+		}
+		if jsCfg.Bankroll != 0 {
+			cfg.Bankroll = jsCfg.Bankroll
+		}
+		cfg.TableRules = jsCfg.TableRules
 	}
 
-	if !game.State.Dealer.Dealer {
-		game.State.Dealer = player.Player{Dealer: true}
-	}
-
-	if cfg.Autoplay {
-		for i := 0; i < len(game.State.Players); i++ {
-			cardPlayer := &game.State.Players[i]
-
-			cardPlayer.PlaceWager = func() int {
-				if cardPlayer.LastHandPushed {
-					return cardPlayer.LastWager
-				}
-
-				if cardPlayer.LastHandWon {
-					if cardPlayer.WinStreak > 6 {
-						return cardPlayer.Stack
-					} else if cardPlayer.WinStreak > 3 {
-						return utils.Min(cardPlayer.Stack, (2^cardPlayer.WinStreak%3)*cfg.MinWager)
-					}
-					return cfg.MinWager
-				}
-
-				return cfg.MinWager
-			}
+	// there is no file system to keep a hand history or progressives in
+	cfg.HistoryPath = ""
+	cfg.ProgressivesPath = ""
 
-			cardPlayer.DoAction = func() (rune, error) {
-				defer func() {
-					if r := recover(); r != nil {
-						if err, ok := r.(error); ok && err != nil {
-							log.Printf("We panicked!!! Why?!?!?")
-						}
-					}
-				}()
+	table = dealer.OpenTable(cfg, cfg.Game, "state.out")
+	log.Printf("Seed: %d\n", table.State.Seed)
 
-				activeHand := player.ActiveHand(cardPlayer)
-				dealerFaceUpCard := cards.CardToValue(game.State.Dealer.Hands[0].Cards[0], true)
+	console = web.New(cfg, table)
 
-				return rules.GetAutoPlayPlayerAction(activeHand, dealerFaceUpCard)
-			}
-		}
+	if cfg.Autoplay {
+		dealer.AutoplayPlayers(table, cfg)
 	}
 
 	go func() {
@@ -149,7 +94,7 @@ func Start(this js.Value, args []js.Value) any {
 		}
 		var char rune
 		for ok := true; ok; ok = char != 'q' {
-			char, _ = dealer.DealRound(console, cfg)
+			char, _ = dealer.DealRound(table, console, cfg)
 		}
 	}()
 
@@ -32,6 +32,14 @@ export default function BlackjackPage() {
           <div id="player-cards" className="cards"></div>
           <div id="player-total"></div>
         </div>
+        <div id="hand2" style={{ display: "none" }}>
+          <div id="hand2-info">
+            Hand 2: Wager: <span id="hand2-wager"></span>
+            <span id="hand2-trifecta"></span>
+          </div>
+          <div id="hand2-cards" className="cards"></div>
+          <div id="hand2-total"></div>
+        </div>
         <div id="status"></div>
         <div id="hint"></div>
       </div>
@@ -41,9 +49,15 @@ export default function BlackjackPage() {
         <button id="stand">Stand</button>
         <button id="double">Double</button>
         <button id="split">Split</button>
+        <button id="surrender" style={{ display: "none" }}>
+          Surrender
+        </button>
         <button id="insure" style={{ display: "none" }}>
           Insure
         </button>
+        <button id="switch" style={{ display: "none" }}>
+          Switch
+        </button>
         <button id="decline" style={{ display: "none" }}>
           Decline
         </button>